@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/backup"
+	"github.com/moonbase/things-mcp/internal/config"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// backupCommand implements "things-mcp backup".
+func backupCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a YAML or JSON config file")
+	profile := fs.String("profile", "", "config profile to use")
+	dbPath := fs.String("db", "", `path to the Things database ("auto" to locate it)`)
+	logbook := fs.Bool("logbook", false, "include completed and canceled items")
+	output := fs.String("o", "", "archive to write (defaults to a timestamped file in the state directory; .gz compresses)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	settings, err := loadSettings(*configPath, *profile)
+	if err != nil {
+		return err
+	}
+	db, err := openDB(config.ExpandHome(firstNonEmpty(*dbPath, settings.Database, "auto")))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	now := time.Now()
+	path := config.ExpandHome(*output)
+	if path == "" {
+		dir, err := config.StateDir()
+		if err != nil {
+			return err
+		}
+		path = backup.DefaultPath(filepath.Join(dir, "backups"), now)
+	}
+
+	archive, err := backup.Create(ctx, db, *logbook, now)
+	if err != nil {
+		return err
+	}
+	if err := archive.Save(path); err != nil {
+		return err
+	}
+	projects, todos := 0, 0
+	for _, e := range archive.Snapshot.Entries() {
+		if e.Type == things.ItemProject {
+			projects++
+		} else {
+			todos++
+		}
+	}
+	fmt.Printf("Backed up %d areas, %d projects, and %d to-dos to %s\n", len(archive.Snapshot.Areas), projects, todos, path)
+	return nil
+}
+
+// restoreCommand implements "things-mcp restore".
+func restoreCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a YAML or JSON config file")
+	profile := fs.String("profile", "", "config profile to use")
+	dbPath := fs.String("db", "", `path to the Things database, used to skip areas that exist ("auto" to locate it)`)
+	dryRun := fs.Bool("dry-run", false, "build the json commands without sending them")
+	skipAreas := fs.Bool("skip-areas", false, "do not create areas and tags through AppleScript")
+	progress := fs.String("progress", "", "file recording restore progress (defaults to the archive path plus .restore)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: things-mcp restore [flags] archive")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("restore takes exactly one archive")
+	}
+
+	settings, err := loadSettings(*configPath, *profile)
+	if err != nil {
+		return err
+	}
+	if *dbPath != "" {
+		settings.Database = *dbPath
+	}
+	if *dryRun {
+		settings.DryRun = dryRun
+	}
+	db, err := openDB(config.ExpandHome(settings.Database))
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	cfg, err := clientConfig(ctx, settings)
+	if err != nil {
+		return err
+	}
+	// Restored items keep their own lists, tags, and notes, so the defaults
+	// meant for agent-created items do not apply.
+	cfg.Defaults = things.ItemDefaults{}
+	cfg.DB = db
+	client := things.NewClient(cfg)
+
+	path := config.ExpandHome(fs.Arg(0))
+	archive, err := backup.Load(path)
+	if err != nil {
+		return err
+	}
+	log.Printf("restoring backup from %s", archive.CreatedAt.Local().Format("2006-01-02 15:04"))
+	res, err := backup.Restore(ctx, client, db, archive, path, backup.RestoreOptions{
+		ProgressPath: config.ExpandHome(*progress),
+		SkipAreas:    *skipAreas,
+		DryRun:       boolValue(settings.DryRun),
+		Logf:         log.Printf,
+	})
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("Restored %d projects and %d to-dos", res.Projects, res.ToDos)
+	if len(res.AreasCreated) > 0 {
+		summary += fmt.Sprintf(", created areas %s", strings.Join(res.AreasCreated, ", "))
+	}
+	if boolValue(settings.DryRun) {
+		summary = "Dry run: " + summary
+	}
+	fmt.Println(summary)
+	return nil
+}
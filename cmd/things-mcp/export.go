@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/config"
+	"github.com/moonbase/things-mcp/internal/exporter"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type exportInput struct {
+	Format        string `json:"format" jsonschema:"markdown, json (a json command payload that things-json re-imports), csv, ics, or taskpaper"`
+	IncludeClosed bool   `json:"includeClosed,omitempty" jsonschema:"include completed and canceled items from the Logbook"`
+}
+
+type exportResult struct {
+	Format   string `json:"format"`
+	Content  string `json:"content,omitempty"`
+	Projects int    `json:"projects"`
+	ToDos    int    `json:"toDos"`
+}
+
+var errExportNeedsDB = errors.New("export needs the Things database; start the server with -db")
+
+func registerExportTools(reg *toolRegistry, db *things.DB) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-export",
+		Description: "Export areas, projects, headings, to-dos, and checklists from the Things database as Markdown, JSON, CSV, ICS, or TaskPaper",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input exportInput) (*mcp.CallToolResult, exportResult, error) {
+		if db == nil {
+			return nil, exportResult{}, errExportNeedsDB
+		}
+		format, err := exporter.ParseFormat(input.Format)
+		if err != nil {
+			return nil, exportResult{}, err
+		}
+
+		var buf bytes.Buffer
+		out, err := export(ctx, db, &buf, format, input.IncludeClosed)
+		if err != nil {
+			return nil, exportResult{}, err
+		}
+		out.Content = buf.String()
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: out.Content}}}, out, nil
+	})
+}
+
+// export writes a snapshot of the database to w and counts what it held.
+func export(ctx context.Context, db *things.DB, w io.Writer, format string, includeClosed bool) (exportResult, error) {
+	snap, err := db.Snapshot(ctx, things.SnapshotOptions{IncludeClosed: includeClosed})
+	if err != nil {
+		return exportResult{}, err
+	}
+	if err := exporter.Write(w, format, snap); err != nil {
+		return exportResult{}, fmt.Errorf("write %s: %w", format, err)
+	}
+
+	out := exportResult{Format: format}
+	for _, e := range snap.Entries() {
+		if e.Type == things.ItemProject {
+			out.Projects++
+		} else {
+			out.ToDos++
+		}
+	}
+	return out, nil
+}
+
+// exportCommand implements "things-mcp export". Without -db it uses the
+// configured database, or locates it.
+func exportCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a YAML or JSON config file")
+	profile := fs.String("profile", "", "config profile to use")
+	dbPath := fs.String("db", "", `path to the Things database ("auto" to locate it)`)
+	format := fs.String("format", "", "markdown, json, csv, ics, or taskpaper (defaults to the -o extension, else markdown)")
+	output := fs.String("o", "", "file to write instead of standard output")
+	force := fs.Bool("force", false, "overwrite the -o file if it exists")
+	closed := fs.Bool("closed", false, "include completed and canceled items")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	settings, err := loadSettings(*configPath, *profile)
+	if err != nil {
+		return err
+	}
+	db, err := openDB(config.ExpandHome(firstNonEmpty(*dbPath, settings.Database, "auto")))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	path := config.ExpandHome(*output)
+	name := *format
+	if name == "" {
+		name = firstNonEmpty(filepath.Ext(path), exporter.FormatMarkdown)
+	}
+	resolved, err := exporter.ParseFormat(name)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		_, err = export(ctx, db, os.Stdout, resolved, *closed)
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err := export(ctx, db, &buf, resolved, *closed); err != nil {
+		return err
+	}
+	return writeExport(path, buf.Bytes(), *force)
+}
+
+// writeExport writes data to path, refusing to replace an existing file
+// unless force is set.
+func writeExport(path string, data []byte, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists; pass -force to overwrite it", path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
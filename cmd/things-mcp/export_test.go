@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// exportSchema mirrors the tables the snapshot reader touches.
+const exportSchema = `
+CREATE TABLE TMArea (uuid TEXT PRIMARY KEY, title TEXT, visible INTEGER, "index" INTEGER DEFAULT 0);
+CREATE TABLE TMTask (
+	uuid TEXT PRIMARY KEY,
+	type INTEGER DEFAULT 0,
+	title TEXT,
+	notes TEXT DEFAULT '',
+	status INTEGER DEFAULT 0,
+	trashed INTEGER DEFAULT 0,
+	start INTEGER DEFAULT 0,
+	startDate INTEGER,
+	startBucket INTEGER DEFAULT 0,
+	deadline INTEGER,
+	stopDate REAL,
+	creationDate REAL,
+	userModificationDate REAL,
+	area TEXT,
+	project TEXT,
+	heading TEXT,
+	"index" INTEGER DEFAULT 0,
+	todayIndex INTEGER DEFAULT 0,
+	rt1_recurrenceRule BLOB
+);
+CREATE TABLE TMTag (uuid TEXT PRIMARY KEY, title TEXT, shortcut TEXT, parent TEXT, "index" INTEGER DEFAULT 0);
+CREATE TABLE TMTaskTag (tasks TEXT, tags TEXT);
+CREATE TABLE TMAreaTag (areas TEXT, tags TEXT);
+CREATE TABLE TMChecklistItem (
+	uuid TEXT PRIMARY KEY,
+	title TEXT,
+	status INTEGER DEFAULT 0,
+	stopDate REAL,
+	"index" INTEGER DEFAULT 0,
+	task TEXT,
+	creationDate REAL,
+	userModificationDate REAL
+);
+`
+
+func newExportDB(t *testing.T) *things.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "main.sqlite")
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		exportSchema,
+		`INSERT INTO TMTask (uuid, type, title, notes, start) VALUES ('proj-launch', 1, 'Launch', 'Ship v2', 1)`,
+		`INSERT INTO TMTask (uuid, type, title, project, start) VALUES ('todo-plan', 0, 'Write plan', 'proj-launch', 1)`,
+		`INSERT INTO TMTag (uuid, title) VALUES ('tag-urgent', 'Very urgent')`,
+		`INSERT INTO TMTaskTag (tasks, tags) VALUES ('todo-plan', 'tag-urgent')`,
+		`INSERT INTO TMChecklistItem (uuid, title, status, task) VALUES ('c1', 'Outline', 3, 'todo-plan')`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			raw.Close()
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	raw.Close()
+
+	db, err := things.OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestExportToolWritesTaskPaper(t *testing.T) {
+	reg := &toolRegistry{server: mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)}
+	registerExportTools(reg, newExportDB(t))
+
+	res, err := reg.calls["things-export"].run(context.Background(), json.RawMessage(`{"format":"taskpaper"}`))
+	if err != nil {
+		t.Fatalf("things-export returned error: %v", err)
+	}
+	got := res.Content[0].(*mcp.TextContent).Text
+	want := "Launch: @defer(anytime)\n\tShip v2\n\t- Write plan @Very_urgent @defer(anytime)\n\t\t- Outline @done\n"
+	if got != want {
+		t.Errorf("things-export taskpaper =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteExportKeepsExistingFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "things.md")
+	if err := writeExport(path, []byte("first"), false); err != nil {
+		t.Fatalf("writeExport returned error: %v", err)
+	}
+	if err := writeExport(path, []byte("second"), false); err == nil || !strings.Contains(err.Error(), "-force") {
+		t.Fatalf("expected an existing-file error, got %v", err)
+	}
+	if err := writeExport(path, []byte("second"), true); err != nil {
+		t.Fatalf("writeExport with force returned error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second" {
+		t.Errorf("file holds %q", data)
+	}
+}
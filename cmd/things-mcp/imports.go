@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/config"
+	"github.com/moonbase/things-mcp/internal/importer"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type markdownImportInput struct {
+	Markdown string `json:"markdown" jsonschema:"Markdown outline: # project, ## heading, - [ ] to-do, nested - [ ] checklist item, - [x] completed, inline #tag and @due(2025-01-01)"`
+	things.ImportOptions
+}
+
+type taskPaperImportInput struct {
+	TaskPaper string `json:"taskpaper" jsonschema:"TaskPaper document: Project: lines, - task lines, indented notes, and @tag, @due(date), @defer(date), @done(date) tags"`
+	things.ImportOptions
+}
+
+type icsImportInput struct {
+	ICS  string `json:"ics,omitempty" jsonschema:"contents of an iCalendar file with VTODO components"`
+	Path string `json:"path,omitempty" jsonschema:"path to an .ics file to read instead of passing its contents"`
+	things.ImportOptions
+}
+
+type issueImportInput struct {
+	Issues    json.RawMessage   `json:"issues,omitempty" jsonschema:"array of issues as returned by gh issue list --json title,url,state,labels,milestone,closedAt or the GitHub or GitLab issues API"`
+	Path      string            `json:"path,omitempty" jsonschema:"path to a JSON file of issues to read instead of passing them"`
+	LabelTags map[string]string `json:"labelTags,omitempty" jsonschema:"maps issue labels to Things tags, over the issueLabels config setting; map a label to an empty string to drop it"`
+	things.ImportOptions
+}
+
+type codeTODOImportInput struct {
+	Path      string `json:"path" jsonschema:"directory to scan; .gitignore files are respected"`
+	Project   string `json:"project,omitempty" jsonschema:"title for the new project; defaults to TODOs in followed by the directory name"`
+	Context   *int   `json:"context,omitempty" jsonschema:"lines of source to include before and after each comment; defaults to 2"`
+	StateFile string `json:"stateFile,omitempty" jsonschema:"file recording already filed comments; defaults to one per directory in the state directory"`
+	things.ImportOptions
+}
+
+type codeTODOImportResult struct {
+	things.ImportResult
+	Found     int    `json:"found"`
+	New       int    `json:"new"`
+	StateFile string `json:"stateFile"`
+}
+
+func registerImportTools(reg *toolRegistry, client *things.Client, settings config.Settings) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-import-markdown",
+		Description: "Create projects, headings, to-dos, and checklists from a Markdown task outline",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input markdownImportInput) (*mcp.CallToolResult, things.ImportResult, error) {
+		items, err := importer.Markdown(input.Markdown)
+		if err != nil {
+			return nil, things.ImportResult{}, fmt.Errorf("parse markdown: %w", err)
+		}
+		return runImport(ctx, client, items, input.ImportOptions)
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-import-taskpaper",
+		Description: "Create projects, headings, to-dos, and checklists from a TaskPaper document",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input taskPaperImportInput) (*mcp.CallToolResult, things.ImportResult, error) {
+		items, err := importer.TaskPaper(input.TaskPaper)
+		if err != nil {
+			return nil, things.ImportResult{}, fmt.Errorf("parse taskpaper: %w", err)
+		}
+		return runImport(ctx, client, items, input.ImportOptions)
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-import-ics",
+		Description: "Create to-dos from the VTODO entries of an iCalendar (.ics) file, in chunks Things accepts",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input icsImportInput) (*mcp.CallToolResult, things.ImportResult, error) {
+		var src io.Reader
+		switch {
+		case input.ICS != "" && input.Path != "":
+			return nil, things.ImportResult{}, errors.New("provide ics or path, not both")
+		case input.Path != "":
+			f, err := os.Open(config.ExpandHome(input.Path))
+			if err != nil {
+				return nil, things.ImportResult{}, err
+			}
+			defer f.Close()
+			src = f
+		case input.ICS != "":
+			src = strings.NewReader(input.ICS)
+		default:
+			return nil, things.ImportResult{}, errors.New("provide ics or path")
+		}
+
+		items, err := importer.ICS(src)
+		if err != nil {
+			return nil, things.ImportResult{}, fmt.Errorf("parse ics: %w", err)
+		}
+		return runImport(ctx, client, items, input.ImportOptions)
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-import-issues",
+		Description: "Mirror GitHub or GitLab issues from exported JSON as to-dos. Issues imported before are updated instead of created again when the server has -db",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input issueImportInput) (*mcp.CallToolResult, things.ImportResult, error) {
+		data := []byte(input.Issues)
+		switch {
+		case len(data) > 0 && input.Path != "":
+			return nil, things.ImportResult{}, errors.New("provide issues or path, not both")
+		case input.Path != "":
+			var err error
+			if data, err = os.ReadFile(config.ExpandHome(input.Path)); err != nil {
+				return nil, things.ImportResult{}, err
+			}
+		case len(data) == 0:
+			return nil, things.ImportResult{}, errors.New("provide issues or path")
+		}
+
+		// A JSON string holding the export is accepted too.
+		var text string
+		if json.Unmarshal(data, &text) == nil {
+			data = []byte(text)
+		}
+		labels := maps.Clone(settings.IssueLabels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		maps.Copy(labels, input.LabelTags)
+
+		items, err := importer.Issues(data, labels)
+		if err != nil {
+			return nil, things.ImportResult{}, fmt.Errorf("parse issues: %w", err)
+		}
+		return runImport(ctx, client, items, input.ImportOptions)
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-import-code-todos",
+		Description: "File the TODO, FIXME, and HACK comments in a source tree as to-dos in a Things project, with a heading per directory. Comments filed by earlier runs are skipped",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input codeTODOImportInput) (*mcp.CallToolResult, codeTODOImportResult, error) {
+		out, err := importCodeTODOs(ctx, client, input)
+		if err != nil {
+			return nil, codeTODOImportResult{}, err
+		}
+		if out.New == 0 {
+			text := fmt.Sprintf("Found %d comments, all already filed", out.Found)
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, out, nil
+		}
+		return imported(out.ImportResult), out, nil
+	})
+}
+
+func importCodeTODOs(ctx context.Context, client *things.Client, input codeTODOImportInput) (codeTODOImportResult, error) {
+	if input.Path == "" {
+		return codeTODOImportResult{}, errors.New("path is required")
+	}
+	root, err := filepath.Abs(config.ExpandHome(input.Path))
+	if err != nil {
+		return codeTODOImportResult{}, err
+	}
+	lines := 2
+	if input.Context != nil {
+		lines = max(0, *input.Context)
+	}
+	statePath := config.ExpandHome(input.StateFile)
+	if statePath == "" {
+		dir, err := config.StateDir()
+		if err != nil {
+			return codeTODOImportResult{}, err
+		}
+		sum := sha256.Sum256([]byte(root))
+		statePath = filepath.Join(dir, "code-todos", hex.EncodeToString(sum[:8])+".json")
+	}
+
+	todos, err := importer.ScanCodeTODOs(root, lines)
+	if err != nil {
+		return codeTODOImportResult{}, fmt.Errorf("scan %s: %w", root, err)
+	}
+	state, err := importer.LoadTODOState(statePath, root)
+	if err != nil {
+		return codeTODOImportResult{}, err
+	}
+	fresh := state.New(todos)
+	out := codeTODOImportResult{Found: len(todos), New: len(fresh), StateFile: statePath}
+	if len(fresh) == 0 {
+		return out, nil
+	}
+
+	title := input.Project
+	if title == "" {
+		title = "TODOs in " + filepath.Base(root)
+	}
+	items := importer.CodeTODOItems(fresh, title, state.ProjectID)
+	// Record each chunk once it is sent, so a failure in a later chunk does
+	// not file the earlier comments again on the next run.
+	byNotes := map[string]importer.CodeTODO{}
+	for _, todo := range fresh {
+		byNotes[todo.Notes()] = todo
+	}
+	record := func(done, total int, chunk []things.JSONItem) error {
+		if client.DryRun() {
+			return nil
+		}
+		state.Record(chunkTODOs(chunk, byNotes), time.Now())
+		if err := state.Save(); err != nil {
+			return fmt.Errorf("to-dos were created but the state file was not saved: %w", err)
+		}
+		return nil
+	}
+	out.ImportResult, err = client.Import(ctx, things.ImportInput{ImportOptions: input.ImportOptions, Items: items, Progress: record})
+	if err != nil {
+		return codeTODOImportResult{}, err
+	}
+	if out.Preview || client.DryRun() || state.ProjectID != "" || len(out.ProjectIDs) == 0 {
+		return out, nil
+	}
+
+	state.ProjectID = out.ProjectIDs[0]
+	if err := state.Save(); err != nil {
+		return codeTODOImportResult{}, fmt.Errorf("to-dos were created but the state file was not saved: %w", err)
+	}
+	return out, nil
+}
+
+// chunkTODOs returns the comments whose to-dos are in chunk, at any depth.
+func chunkTODOs(chunk []things.JSONItem, byNotes map[string]importer.CodeTODO) []importer.CodeTODO {
+	var todos []importer.CodeTODO
+	for _, item := range chunk {
+		if todo, ok := byNotes[item.Attributes.Notes]; ok && item.Type == things.JSONToDo {
+			todos = append(todos, todo)
+		}
+		todos = append(todos, chunkTODOs(item.Attributes.Items, byNotes)...)
+	}
+	return todos
+}
+
+func runImport(ctx context.Context, client *things.Client, items []things.JSONItem, opts things.ImportOptions) (*mcp.CallToolResult, things.ImportResult, error) {
+	out, err := client.Import(ctx, things.ImportInput{ImportOptions: opts, Items: items})
+	if err != nil {
+		return nil, things.ImportResult{}, err
+	}
+	return imported(out), out, nil
+}
+
+func imported(out things.ImportResult) *mcp.CallToolResult {
+	summary := fmt.Sprintf("%d projects, %d headings, %d to-dos, %d checklist items",
+		out.Projects, out.Headings, out.ToDos, out.ChecklistItems)
+	if out.Updated > 0 {
+		summary += fmt.Sprintf(", %d updated", out.Updated)
+	}
+	for _, w := range out.Warnings {
+		summary += "\nWarning: " + w
+	}
+	if out.Preview {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Preview, not dispatched: %s\n%s", summary, out.Data)},
+			},
+		}
+	}
+
+	res := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Imported " + summary}}}
+	for _, d := range out.Dispatches {
+		res.Content = append(res.Content, dispatched(d).Content...)
+	}
+	return res
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/schedule"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// jobTools manage scheduled jobs and cannot be scheduled themselves.
+var jobTools = map[string]bool{
+	"things-schedule-dispatch": true,
+	"things-list-scheduled":    true,
+	"things-cancel-scheduled":  true,
+}
+
+type scheduleDispatchInput struct {
+	Tool      string          `json:"tool" jsonschema:"name of the tool to call later, such as things-add or things-show"`
+	Arguments json.RawMessage `json:"arguments,omitempty" jsonschema:"the tool's arguments, exactly as they would be passed when calling it directly"`
+	At        string          `json:"at,omitempty" jsonschema:"when to call it: an RFC 3339 time, or YYYY-MM-DD HH:MM in local time"`
+	In        string          `json:"in,omitempty" jsonschema:"delay instead of at, such as 45m or 2h30m"`
+}
+
+type listScheduledInput struct {
+	IncludeFinished bool `json:"includeFinished,omitempty" jsonschema:"also list jobs that ran, failed, or were canceled in the last week"`
+}
+
+type listScheduledResult struct {
+	Jobs []schedule.Job `json:"jobs"`
+}
+
+type cancelScheduledInput struct {
+	ID string `json:"id" jsonschema:"job ID returned by things-schedule-dispatch"`
+}
+
+func registerJobTools(reg *toolRegistry, store *schedule.JobStore) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-schedule-dispatch",
+		Description: "Call another Things tool at a later time instead of now, for example to add a to-do on Monday morning or show a list at a set time. Jobs are kept on disk and run by the server while it is running",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input scheduleDispatchInput) (*mcp.CallToolResult, schedule.Job, error) {
+		call, ok := reg.calls[input.Tool]
+		switch {
+		case input.Tool == "":
+			return nil, schedule.Job{}, errors.New("tool is required")
+		case jobTools[input.Tool]:
+			return nil, schedule.Job{}, fmt.Errorf("%s cannot be scheduled", input.Tool)
+		case !ok:
+			return nil, schedule.Job{}, fmt.Errorf("unknown tool %q", input.Tool)
+		}
+		args := input.Arguments
+		// Arguments passed as a JSON string are accepted too.
+		var text string
+		if json.Unmarshal(args, &text) == nil {
+			args = json.RawMessage(text)
+		}
+		if err := call.check(args); err != nil {
+			return nil, schedule.Job{}, fmt.Errorf("%s: %w", input.Tool, err)
+		}
+		fireAt, err := parseFireTime(input.At, input.In, time.Now())
+		if err != nil {
+			return nil, schedule.Job{}, err
+		}
+
+		job := schedule.Job{Tool: input.Tool, Arguments: args, FireAt: fireAt}
+		if params := sessionParams(req); params != nil && params.ClientInfo != nil {
+			job.ClientName = params.ClientInfo.Name
+		}
+		job, err = store.Add(job)
+		if err != nil {
+			return nil, schedule.Job{}, err
+		}
+		text = fmt.Sprintf("Scheduled %s for %s as job %s", job.Tool, job.FireAt.Local().Format("Mon 2006-01-02 15:04"), job.ID)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, job, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-list-scheduled",
+		Description: "List tool calls scheduled with things-schedule-dispatch that have not run yet, or with includeFinished those from the last week too",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input listScheduledInput) (*mcp.CallToolResult, listScheduledResult, error) {
+		jobs, err := store.List(!input.IncludeFinished)
+		if err != nil {
+			return nil, listScheduledResult{}, err
+		}
+		out := listScheduledResult{Jobs: []schedule.Job{}}
+		var lines []string
+		for _, j := range jobs {
+			out.Jobs = append(out.Jobs, j)
+			line := fmt.Sprintf("%s %s %s %s", j.ID, j.FireAt.Local().Format("2006-01-02 15:04"), j.Tool, j.Status)
+			if j.Error != "" {
+				line += ": " + j.Error
+			}
+			lines = append(lines, line)
+		}
+		text := "No scheduled jobs"
+		if len(lines) > 0 {
+			text = strings.Join(lines, "\n")
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-cancel-scheduled",
+		Description: "Cancel a pending job scheduled with things-schedule-dispatch",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input cancelScheduledInput) (*mcp.CallToolResult, schedule.Job, error) {
+		job, err := store.Cancel(input.ID)
+		if err != nil {
+			return nil, schedule.Job{}, err
+		}
+		text := fmt.Sprintf("Canceled job %s (%s at %s)", job.ID, job.Tool, job.FireAt.Local().Format("2006-01-02 15:04"))
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, job, nil
+	})
+}
+
+// runJob calls the job's tool as the client that scheduled it and returns
+// the text of the tool's result.
+func (r *toolRegistry) runJob(ctx context.Context, job schedule.Job) (string, error) {
+	call, ok := r.calls[job.Tool]
+	if !ok {
+		return "", fmt.Errorf("tool %s is not available in this server", job.Tool)
+	}
+	if job.ClientName != "" {
+		ctx = things.WithClientName(ctx, job.ClientName)
+	}
+	res, err := call.run(ctx, job.Arguments)
+	if err != nil {
+		return "", err
+	}
+	var texts []string
+	for _, c := range res.Content {
+		if t, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, t.Text)
+		}
+	}
+	text := strings.Join(texts, "\n")
+	if res.IsError {
+		return "", errors.New(text)
+	}
+	return text, nil
+}
+
+// parseFireTime reads an absolute time or a delay from now; exactly one must
+// be given and the result must lie in the future.
+func parseFireTime(at, in string, now time.Time) (time.Time, error) {
+	var fireAt time.Time
+	switch {
+	case (at == "") == (in == ""):
+		return time.Time{}, errors.New("provide at or in")
+	case in != "":
+		d, err := time.ParseDuration(in)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid delay %q; use a duration such as 45m or 2h30m", in)
+		}
+		fireAt = now.Add(d)
+	default:
+		var err error
+		if fireAt, err = time.Parse(time.RFC3339, at); err != nil {
+			for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+				if fireAt, err = time.ParseInLocation(layout, at, time.Local); err == nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q; use RFC 3339 or YYYY-MM-DD HH:MM", at)
+		}
+	}
+	if !fireAt.After(now) {
+		return time.Time{}, fmt.Errorf("%s is not in the future; call the tool directly instead", fireAt.Local().Format("2006-01-02 15:04"))
+	}
+	return fireAt, nil
+}
@@ -2,151 +2,304 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/moonbase/things-mcp/internal/config"
+	"github.com/moonbase/things-mcp/internal/schedule"
 	"github.com/moonbase/things-mcp/internal/things"
 )
 
-type invocationOutput struct {
-	URL string `json:"url"`
+// subcommands run instead of the server when named as the first argument.
+var subcommands = map[string]func(ctx context.Context, args []string) error{
+	"export":   exportCommand,
+	"backup":   backupCommand,
+	"restore":  restoreCommand,
+	"schedule": scheduleCommand,
 }
 
 func main() {
-	var activate bool
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err := run(ctx, os.Args[2:])
+			stop()
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			if err != nil {
+				log.Fatalf("%s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
+	var activate, createTags, dryRun, strict, noSchedule, noWatch bool
+	var dbPath, catalogPath, configPath, profile string
+	flag.StringVar(&configPath, "config", "", "path to a YAML or JSON config file (defaults to $XDG_CONFIG_HOME/things-mcp/config.yaml)")
+	flag.StringVar(&profile, "profile", "", "config profile to use, such as work or personal")
 	flag.BoolVar(&activate, "activate", false, "bring Things to the foreground when launching URLs")
+	flag.StringVar(&dbPath, "db", "", `path to the Things database used to resolve names ("auto" to locate it)`)
+	flag.StringVar(&catalogPath, "catalog", "", "YAML catalog of areas, projects, and headings used to resolve names")
+	flag.BoolVar(&createTags, "create-tags", false, "create tags missing from the catalog via AppleScript instead of rejecting the call")
+	flag.BoolVar(&dryRun, "dry-run", false, "build Things URLs without launching them")
+	flag.BoolVar(&strict, "strict", false, "reject calls with parameters Things would ignore instead of warning")
+	flag.BoolVar(&noWatch, "no-watch", false, "do not watch the database for changes made in Things")
+	flag.BoolVar(&noSchedule, "no-schedule", false, "do not run configured schedules or scheduled jobs in this server")
 	flag.Parse()
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	settings, err := loadSettings(configPath, profile)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	// Flags given on the command line win over the config file.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "activate":
+			settings.Activate = &activate
+		case "create-tags":
+			settings.CreateMissingTags = &createTags
+		case "dry-run":
+			settings.DryRun = &dryRun
+		case "strict":
+			settings.Strict = &strict
+		case "db":
+			settings.Database = dbPath
+		case "catalog":
+			settings.Catalog = catalogPath
+		}
+	})
+
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "things-mcp",
 		Version: "0.1.0",
-	}, nil)
-
-	client := things.NewClient(things.Config{
-		Activate: activate,
+	}, &mcp.ServerOptions{
+		SubscribeHandler:   subscribeChanges,
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
 	})
 
-	registerTools(server, client)
+	db, err := openDB(config.ExpandHome(settings.Database))
+	if err != nil {
+		log.Fatalf("open database: %v", err)
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	catalog, err := loadCatalog(db, config.ExpandHome(settings.Catalog))
+	if err != nil {
+		log.Fatalf("load catalog: %v", err)
+	}
+
+	cfg, err := clientConfig(ctx, settings)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	cfg.Catalog = catalog
+	cfg.DB = db
+
+	// Webhooks get their own context so events queued when the server stops
+	// are written to the dead-letter file before the process exits.
+	sender, err := newWebhookSender(ctx, settings)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	var publish func([]things.Change)
+	if sender != nil {
+		cfg.OnDispatch = publishDispatch(sender)
+		publish = publishChanges(sender)
+		senderCtx, stopSender := context.WithCancel(context.Background())
+		senderDone := make(chan struct{})
+		go func() {
+			sender.Run(senderCtx)
+			close(senderDone)
+		}()
+		defer func() {
+			stopSender()
+			<-senderDone
+		}()
+	}
+	client := things.NewClient(cfg)
+
+	reg := &toolRegistry{server: server, allowed: settings.Tools}
+	registerTools(reg, client)
+	registerImportTools(reg, client, settings)
+	registerExportTools(reg, db)
+	registerStatsTools(reg, db)
+	registerReviewTools(reg, db)
+	registerPlanTools(reg, client, db)
+	registerTemplateTools(reg, client, firstNonEmpty(config.ExpandHome(settings.Templates), config.DefaultTemplateDir()))
+	jobs, err := openJobStore(jobOwner(settings.Profile, client.DryRun()))
+	if err != nil {
+		log.Fatalf("open job store: %v", err)
+	}
+	jobs.CanRun = func(j schedule.Job) bool {
+		_, ok := reg.calls[j.Tool]
+		return ok
+	}
+	registerJobTools(reg, jobs)
+	if unknown := reg.unknown(); len(unknown) > 0 {
+		log.Fatalf("load config: unknown tools %v", unknown)
+	}
+
+	if len(settings.Schedules) > 0 && !noSchedule {
+		sched, err := newScheduler(settings, cfg)
+		if err != nil {
+			log.Fatalf("load config: %v", err)
+		}
+		go sched.Run(ctx)
+	}
+	if !noSchedule {
+		go jobs.Run(ctx, reg.runJob)
+	}
+	if db != nil && !noWatch {
+		watchChanges(ctx, server, db, publish)
+	}
 
 	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
 		log.Fatalf("run server: %v", err)
 	}
 }
 
-func registerTools(server *mcp.Server, client *things.Client) {
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-add",
-		Description: "Create new to-dos in Things using the URL scheme",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.AddInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.Add(ctx, input)
+func openDB(path string) (*things.DB, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if path == "auto" {
+		located, err := things.DefaultDBPath()
 		if err != nil {
-			return nil, invocationOutput{}, err
+			return nil, err
 		}
-		res, out := success(url)
-		return res, out, nil
-	})
+		path = located
+	}
+	return things.OpenDB(path)
+}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-add-project",
-		Description: "Create new projects in Things",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.AddProjectInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.AddProject(ctx, input)
-		if err != nil {
-			return nil, invocationOutput{}, err
-		}
-		res, out := success(url)
-		return res, out, nil
-	})
+// loadCatalog prefers a hand-maintained catalog file and falls back to the
+// database when one is open.
+func loadCatalog(db *things.DB, path string) (things.CatalogSource, error) {
+	if path != "" {
+		return things.LoadCatalog(path)
+	}
+	if db != nil {
+		return db, nil
+	}
+	return nil, nil
+}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-update",
-		Description: "Update existing to-dos in Things",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.UpdateInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.Update(ctx, input)
-		if err != nil {
-			return nil, invocationOutput{}, err
+// loadSettings reads the config file at path, or the default location when
+// path is empty, and resolves profile. Having no config file at all is fine.
+func loadSettings(path, profile string) (config.Settings, error) {
+	if path == "" {
+		path = config.DefaultPath()
+	}
+	if path == "" {
+		if profile != "" {
+			return config.Settings{}, fmt.Errorf("profile %q requested but no config file found", profile)
 		}
-		res, out := success(url)
-		return res, out, nil
-	})
+		return config.Settings{}, nil
+	}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-update-project",
-		Description: "Update existing projects in Things",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.UpdateProjectInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.UpdateProject(ctx, input)
-		if err != nil {
-			return nil, invocationOutput{}, err
-		}
-		res, out := success(url)
-		return res, out, nil
-	})
+	file, err := config.Load(path)
+	if err != nil {
+		return config.Settings{}, err
+	}
+	return file.Resolve(profile)
+}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-show",
-		Description: "Open Things lists, projects, or tags",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.ShowInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.Show(ctx, input)
+func clientConfig(ctx context.Context, s config.Settings) (things.Config, error) {
+	cfg := things.Config{
+		Activate:          boolValue(s.Activate),
+		CreateMissingTags: boolValue(s.CreateMissingTags),
+		DryRun:            boolValue(s.DryRun),
+		Strict:            boolValue(s.Strict),
+		Reveal:            s.Reveal,
+	}
+	if s.AuthToken != nil {
+		token, err := s.AuthToken.Token(ctx)
 		if err != nil {
-			return nil, invocationOutput{}, err
+			return things.Config{}, fmt.Errorf("authToken: %w", err)
 		}
-		res, out := success(url)
-		return res, out, nil
-	})
-
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-search",
-		Description: "Open the Things search UI",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.SearchInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.Search(ctx, input)
-		if err != nil {
-			return nil, invocationOutput{}, err
+		cfg.AuthToken = token
+	}
+	if d := s.Defaults; d != nil {
+		cfg.Defaults = things.ItemDefaults{
+			List:       d.List,
+			ListID:     d.ListID,
+			Heading:    d.Heading,
+			Tags:       d.Tags,
+			Provenance: d.Provenance,
 		}
-		res, out := success(url)
-		return res, out, nil
-	})
+	}
+	if r := s.RateLimit; r != nil {
+		cfg.RateLimit = things.RateLimit{Requests: r.Requests, Per: r.Per.Duration}
+	}
+	store, err := openIdempotencyStore(s.Idempotency)
+	if err != nil {
+		return things.Config{}, err
+	}
+	cfg.Idempotency = store
+	return cfg, nil
+}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-version",
-		Description: "Reveal the Things app and URL scheme version dialog",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.VersionInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.Version(ctx, input)
-		if err != nil {
-			return nil, invocationOutput{}, err
+// openIdempotencyStore keeps keys for a day in the state directory unless
+// the config says otherwise.
+func openIdempotencyStore(settings *config.Idempotency) (*things.IdempotencyStore, error) {
+	path, ttl := "", 24*time.Hour
+	if settings != nil {
+		path = config.ExpandHome(settings.Path)
+		if settings.TTL.Duration > 0 {
+			ttl = settings.TTL.Duration
 		}
-		res, out := success(url)
-		return res, out, nil
-	})
-
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "things-json",
-		Description: "Invoke the Things JSON command for complex imports",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.JSONInput) (*mcp.CallToolResult, invocationOutput, error) {
-		url, err := client.JSON(ctx, input)
+	}
+	if path == "" {
+		dir, err := config.StateDir()
 		if err != nil {
-			return nil, invocationOutput{}, err
+			return nil, fmt.Errorf("idempotency: %w", err)
 		}
-		res, out := success(url)
-		return res, out, nil
-	})
+		path = filepath.Join(dir, "idempotency.json")
+	}
+	return things.OpenIdempotencyStore(path, ttl)
+}
+
+// openJobStore keeps jobs from things-schedule-dispatch in the state
+// directory. Servers share the store but only run the jobs of their owner.
+func openJobStore(owner string) (*schedule.JobStore, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	store, err := schedule.OpenJobStore(filepath.Join(dir, "jobs.json"))
+	if err != nil {
+		return nil, err
+	}
+	store.Owner = owner
+	store.Logf = log.Printf
+	return store, nil
 }
 
-func success(url string) (*mcp.CallToolResult, invocationOutput) {
-	result := &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: fmt.Sprintf("Dispatched %s", url),
-			},
-		},
+// jobOwner names the server configuration scheduled jobs belong to: the
+// config profile, marked when the server only does dry runs.
+func jobOwner(profile string, dryRun bool) string {
+	if dryRun {
+		return strings.TrimSpace(profile + " (dry run)")
 	}
-	return result, invocationOutput{URL: url}
+	return profile
+}
+
+func boolValue(value *bool) bool {
+	return value != nil && *value
 }
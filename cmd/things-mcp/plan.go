@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/dayplan"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type planDayInput struct {
+	Budget          string            `json:"budget,omitempty" jsonschema:"time available today, such as 5h or 4h30m; defaults to 6h"`
+	Evening         string            `json:"evening,omitempty" jsonschema:"time available this evening once the day's budget is used up, such as 1h; defaults to none"`
+	DeadlineDays    int               `json:"deadlineDays,omitempty" jsonschema:"include to-dos due within this many days; defaults to 3. Overdue to-dos are always included"`
+	Tags            []string          `json:"tags,omitempty" jsonschema:"include to-dos with any of these tags, ranked in this order after deadlines"`
+	Durations       map[string]string `json:"durations,omitempty" jsonschema:"estimates by tag, such as {\"Errand\": \"45m\"}, for to-dos without a duration tag like 30m or an estimate in their notes like ~30m"`
+	DefaultDuration string            `json:"defaultDuration,omitempty" jsonschema:"estimate for to-dos nothing else estimates; defaults to 30m"`
+	Preview         bool              `json:"preview,omitempty" jsonschema:"return the plan and the json command payload without dispatching it"`
+	Reveal          *bool             `json:"reveal,omitempty"`
+}
+
+type planDayResult struct {
+	dayplan.Plan
+	Import *things.ImportResult `json:"import,omitempty" jsonschema:"the json command updating the to-dos, and how it was dispatched"`
+}
+
+var errPlanNeedsDB = errors.New("planning the day needs the Things database; start the server with -db")
+
+func registerPlanTools(reg *toolRegistry, client *things.Client, db *things.DB) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-plan-day",
+		Description: "Plan the day: rank the to-dos in Today, those due soon, and those with the given tags, fit them into a time budget using duration tags such as 30m or estimates in their notes, and move them to Today, This Evening, or Tomorrow through one json command. Use preview to check the plan first",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input planDayInput) (*mcp.CallToolResult, planDayResult, error) {
+		if db == nil {
+			return nil, planDayResult{}, errPlanNeedsDB
+		}
+		opts, err := planOptions(input)
+		if err != nil {
+			return nil, planDayResult{}, err
+		}
+		snap, err := db.Snapshot(ctx, things.SnapshotOptions{})
+		if err != nil {
+			return nil, planDayResult{}, err
+		}
+		out := planDayResult{Plan: dayplan.Build(snap, opts)}
+		if len(out.Entries) == 0 {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Nothing to plan for %s", out.Date)}}}, out, nil
+		}
+
+		items, err := out.Items()
+		if err != nil {
+			return nil, planDayResult{}, err
+		}
+		res, err := client.Import(ctx, things.ImportInput{ImportOptions: things.ImportOptions{Preview: input.Preview, Reveal: input.Reveal}, Items: items})
+		if err != nil {
+			return nil, planDayResult{}, err
+		}
+		out.Import = &res
+
+		text := planText(out.Plan)
+		for _, w := range res.Warnings {
+			text += "\nWarning: " + w
+		}
+		if res.Preview {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Preview, not dispatched: " + text}}}, out, nil
+		}
+		result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+		for _, d := range res.Dispatches {
+			result.Content = append(result.Content, dispatched(d).Content...)
+		}
+		return result, out, nil
+	})
+}
+
+func planOptions(input planDayInput) (dayplan.Options, error) {
+	opts := dayplan.Options{Now: time.Now(), DeadlineDays: input.DeadlineDays, Tags: input.Tags}
+	if input.DeadlineDays < 0 {
+		return opts, errors.New("deadlineDays must not be negative")
+	}
+	for _, f := range []struct {
+		name, value string
+		dst         *time.Duration
+	}{
+		{"budget", input.Budget, &opts.Budget},
+		{"evening", input.Evening, &opts.Evening},
+		{"defaultDuration", input.DefaultDuration, &opts.DefaultDuration},
+	} {
+		if f.value == "" {
+			continue
+		}
+		d, err := dayplan.ParseDuration(f.value)
+		if err != nil {
+			return opts, fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.dst = d
+	}
+	if len(input.Durations) > 0 {
+		opts.Durations = map[string]time.Duration{}
+		for tag, value := range input.Durations {
+			d, err := dayplan.ParseDuration(value)
+			if err != nil {
+				return opts, fmt.Errorf("durations[%s]: %w", tag, err)
+			}
+			opts.Durations[tag] = d
+		}
+	}
+	return opts, nil
+}
+
+func planText(p dayplan.Plan) string {
+	lines := []string{fmt.Sprintf("Plan for %s: %s of %s today, %s this evening, %s moved to tomorrow",
+		p.Date, minutes(p.TodayMinutes), minutes(p.BudgetMinutes), minutes(p.EveningMinutes), minutes(p.TomorrowMinutes))}
+	for _, e := range p.Entries {
+		line := fmt.Sprintf("- %s, %s: %q", e.Slot, minutes(e.Minutes), e.Title)
+		if len(e.Reasons) > 0 {
+			line += " (" + strings.Join(e.Reasons, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	for _, w := range p.Warnings {
+		lines = append(lines, "Warning: "+w)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// minutes formats n minutes as 45m, 2h, or 1h30m.
+func minutes(n int) string {
+	switch {
+	case n < 60:
+		return fmt.Sprintf("%dm", n)
+	case n%60 == 0:
+		return fmt.Sprintf("%dh", n/60)
+	}
+	return fmt.Sprintf("%dh%dm", n/60, n%60)
+}
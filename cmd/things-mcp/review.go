@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/review"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type reviewInput struct {
+	StaleDays    int `json:"staleDays,omitempty" jsonschema:"days without an edit to a project or its to-dos before it counts as stale; defaults to 14"`
+	SomedayWeeks int `json:"somedayWeeks,omitempty" jsonschema:"weeks in Someday before an item is listed; defaults to 8"`
+}
+
+var errReviewNeedsDB = errors.New("the weekly review needs the Things database; start the server with -db")
+
+func registerReviewTools(reg *toolRegistry, db *things.DB) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-weekly-review",
+		Description: "Assemble a GTD weekly review checklist: Inbox items, overdue deadlines, projects without a next action, stale projects, and old Someday items, each with ready-made things-update, things-update-project, or things-show arguments for the suggested actions. Nothing is changed until those tools are called",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input reviewInput) (*mcp.CallToolResult, review.Review, error) {
+		if db == nil {
+			return nil, review.Review{}, errReviewNeedsDB
+		}
+		if input.StaleDays < 0 || input.SomedayWeeks < 0 {
+			return nil, review.Review{}, errors.New("staleDays and somedayWeeks must not be negative")
+		}
+		snap, err := db.Snapshot(ctx, things.SnapshotOptions{IncludeClosed: true})
+		if err != nil {
+			return nil, review.Review{}, err
+		}
+		r := review.Build(snap, review.Options{Now: time.Now(), StaleDays: input.StaleDays, SomedayWeeks: input.SomedayWeeks})
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: reviewText(r)}}}, r, nil
+	})
+}
+
+func reviewText(r review.Review) string {
+	if r.Count() == 0 {
+		return fmt.Sprintf("Weekly review for %s: nothing needs attention", r.Date)
+	}
+	lines := []string{fmt.Sprintf("Weekly review for %s: %d items", r.Date, r.Count())}
+	for _, s := range r.Sections {
+		if len(s.Items) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s (%d)", s.Title, len(s.Items)))
+		for _, item := range s.Items {
+			lines = append(lines, fmt.Sprintf("- %q: %s", item.Title, item.Reason))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/config"
+	"github.com/moonbase/things-mcp/internal/schedule"
+	"github.com/moonbase/things-mcp/internal/templates"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// newScheduler builds the scheduler for the configured rules, keeping its
+// state in the state directory, one file per profile and dry-run mode.
+// Scheduled items are configured by the user, so the defaults meant for
+// agent-created items do not apply to them.
+func newScheduler(settings config.Settings, cfg things.Config) (*schedule.Scheduler, error) {
+	cfg.Defaults = things.ItemDefaults{}
+	client := things.NewClient(cfg)
+	templateDir := firstNonEmpty(config.ExpandHome(settings.Templates), config.DefaultTemplateDir())
+
+	var rules []schedule.Rule
+	for _, s := range settings.Schedules {
+		spec, err := schedule.Parse(s.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", s.Name, err)
+		}
+		rules = append(rules, schedule.Rule{Name: s.Name, Spec: spec, Action: scheduledAction(client, s, templateDir)})
+	}
+	dir, err := config.StateDir()
+	if err != nil {
+		return nil, fmt.Errorf("schedule: %w", err)
+	}
+	sched, err := schedule.New(filepath.Join(dir, scheduleStateFile(settings.Profile, cfg.DryRun)), rules)
+	if err != nil {
+		return nil, err
+	}
+	sched.Logf = log.Printf
+	return sched, nil
+}
+
+// scheduleStateFile names the state file of profile, so rules of the same
+// name in different profiles run independently and a dry-run server does not
+// mark occurrences as run for the real ones.
+func scheduleStateFile(profile string, dryRun bool) string {
+	name := "schedule"
+	if profile != "" {
+		name += "-" + url.PathEscape(profile)
+	}
+	if dryRun {
+		name += "-dry-run"
+	}
+	return name + ".json"
+}
+
+func scheduledAction(client *things.Client, s config.Schedule, templateDir string) func(context.Context, time.Time) error {
+	if s.Add == nil {
+		return func(ctx context.Context, _ time.Time) error {
+			// The template is read when the rule runs, so edits take effect
+			// without a restart.
+			t, err := templates.Find(templateDir, s.Template)
+			if err != nil {
+				return err
+			}
+			item, err := t.Expand(s.Variables, time.Now())
+			if err != nil {
+				return err
+			}
+			_, err = client.Import(ctx, things.ImportInput{Items: []things.JSONItem{item}})
+			return err
+		}
+	}
+	todo := *s.Add
+	return func(ctx context.Context, _ time.Time) error {
+		today := time.Now()
+		when, err := templates.ResolveDate(todo.When, nil, today)
+		if err != nil {
+			return fmt.Errorf("when: %w", err)
+		}
+		deadline, err := templates.ResolveDate(todo.Deadline, nil, today)
+		if err != nil {
+			return fmt.Errorf("deadline: %w", err)
+		}
+		_, err = client.Add(ctx, things.AddInput{
+			Title:          todo.Title,
+			Notes:          todo.Notes,
+			When:           when,
+			Deadline:       deadline,
+			Tags:           todo.Tags,
+			ChecklistItems: todo.Checklist,
+			List:           todo.List,
+			Heading:        todo.Heading,
+		})
+		return err
+	}
+}
+
+// scheduleCommand implements "things-mcp schedule", which runs the configured
+// schedules without an MCP client, for example from a launchd agent.
+func scheduleCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a YAML or JSON config file")
+	profile := fs.String("profile", "", "config profile to use")
+	dbPath := fs.String("db", "", `path to the Things database used to resolve names ("auto" to locate it)`)
+	once := fs.Bool("once", false, "run the rules that are due and exit")
+	list := fs.Bool("list", false, "show when each rule last ran and runs next, then exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	settings, err := loadSettings(*configPath, *profile)
+	if err != nil {
+		return err
+	}
+	if len(settings.Schedules) == 0 {
+		return errors.New("no schedules configured")
+	}
+	if *dbPath != "" {
+		settings.Database = *dbPath
+	}
+	db, err := openDB(config.ExpandHome(settings.Database))
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+	catalog, err := loadCatalog(db, config.ExpandHome(settings.Catalog))
+	if err != nil {
+		return err
+	}
+	cfg, err := clientConfig(ctx, settings)
+	if err != nil {
+		return err
+	}
+	cfg.Catalog = catalog
+	cfg.DB = db
+	sched, err := newScheduler(settings, cfg)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *list:
+		upcoming, err := sched.Upcoming()
+		if err != nil {
+			return err
+		}
+		for _, u := range upcoming {
+			last, next := "never", "never"
+			if !u.LastRun.IsZero() {
+				last = u.LastRun.Local().Format("2006-01-02 15:04")
+			}
+			if !u.Next.IsZero() {
+				next = u.Next.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%s\tlast %s\tnext %s\n", u.Rule, last, next)
+		}
+		return nil
+	case *once:
+		runs, err := sched.Tick(ctx)
+		if err != nil {
+			return err
+		}
+		var failed []error
+		for _, r := range runs {
+			if r.Err != nil {
+				failed = append(failed, fmt.Errorf("%s: %w", r.Rule, r.Err))
+			}
+		}
+		return errors.Join(failed...)
+	}
+	if err := sched.Run(ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/stats"
+	"github.com/moonbase/things-mcp/internal/templates"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type statsInput struct {
+	From string `json:"from,omitempty" jsonschema:"first day to count completions for, as YYYY-MM-DD or relative to today such as -1m or -2w; defaults to 29 days before to"`
+	To   string `json:"to,omitempty" jsonschema:"last day to count completions for, as YYYY-MM-DD or relative to today such as -1d; defaults to today"`
+}
+
+var errStatsNeedsDB = errors.New("stats need the Things database; start the server with -db")
+
+func registerStatsTools(reg *toolRegistry, db *things.DB) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-stats",
+		Description: "Count completed to-dos per day, area, project, and tag over a date range from the Logbook, with the average time from creation to completion, overdue deadlines, and the age of the Someday backlog",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input statsInput) (*mcp.CallToolResult, stats.Report, error) {
+		if db == nil {
+			return nil, stats.Report{}, errStatsNeedsDB
+		}
+		now := time.Now()
+		to, err := statsDay("to", input.To, now)
+		if err != nil {
+			return nil, stats.Report{}, err
+		}
+		from := to.AddDate(0, 0, -29)
+		if input.From != "" {
+			if from, err = statsDay("from", input.From, now); err != nil {
+				return nil, stats.Report{}, err
+			}
+		}
+		if from.After(to) {
+			return nil, stats.Report{}, fmt.Errorf("from %s is after to %s", from.Format(time.DateOnly), to.Format(time.DateOnly))
+		}
+
+		snap, err := db.Snapshot(ctx, things.SnapshotOptions{IncludeClosed: true})
+		if err != nil {
+			return nil, stats.Report{}, err
+		}
+		report := stats.Compute(snap, stats.Options{From: from, To: to, Now: now})
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: statsText(report)}}}, report, nil
+	})
+}
+
+// statsDay resolves a date expression to a day in the local time zone.
+func statsDay(field, expr string, now time.Time) (time.Time, error) {
+	if expr == "" {
+		return now, nil
+	}
+	resolved, err := templates.ResolveDate(expr, nil, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", field, err)
+	}
+	t, err := time.ParseInLocation(time.DateOnly, resolved, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %q is not a date", field, expr)
+	}
+	return t, nil
+}
+
+func statsText(r stats.Report) string {
+	lines := []string{fmt.Sprintf("%s to %s: %d to-dos completed, %d canceled, %d projects completed", r.From, r.To, r.Completed, r.Canceled, r.CompletedProjects)}
+	if r.Completed > 0 {
+		lines = append(lines, fmt.Sprintf("%.1f days on average from creation to completion (median %.1f)", r.AverageDaysToComplete, r.MedianDaysToComplete))
+	}
+	for _, group := range []struct {
+		label  string
+		counts []stats.Count
+	}{{"By project", r.PerProject}, {"By area", r.PerArea}, {"By tag", r.PerTag}} {
+		if len(group.counts) == 0 {
+			continue
+		}
+		var parts []string
+		for _, c := range group.counts {
+			parts = append(parts, fmt.Sprintf("%s %d", c.Name, c.Count))
+		}
+		lines = append(lines, group.label+": "+strings.Join(parts, ", "))
+	}
+	if len(r.Overdue) > 0 {
+		first := r.Overdue[0]
+		lines = append(lines, fmt.Sprintf("%d overdue, the oldest %q by %d days", len(r.Overdue), first.Title, first.DaysOverdue))
+	}
+	if r.Someday.Count > 0 {
+		lines = append(lines, fmt.Sprintf("%d items in Someday, %.0f days old on average", r.Someday.Count, r.Someday.AverageAgeDays))
+	}
+	return strings.Join(lines, "\n")
+}
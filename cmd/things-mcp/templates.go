@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/templates"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type listTemplatesInput struct{}
+
+type templateSummary struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Title       string               `json:"title"`
+	Variables   []templates.Variable `json:"variables,omitempty"`
+	Path        string               `json:"path"`
+}
+
+type listTemplatesResult struct {
+	Directory string            `json:"directory"`
+	Templates []templateSummary `json:"templates"`
+}
+
+type applyTemplateInput struct {
+	Template  string            `json:"template" jsonschema:"template name, as listed by things-list-templates"`
+	Variables map[string]string `json:"variables,omitempty" jsonschema:"values for the template's variables; dates may be YYYY-MM-DD or relative such as +2w"`
+	things.ImportOptions
+}
+
+// registerTemplateTools reads templates from dir on every call, so edits
+// take effect without a restart.
+func registerTemplateTools(reg *toolRegistry, client *things.Client, dir string) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-list-templates",
+		Description: "List the project templates things-apply-template can create, with their variables",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input listTemplatesInput) (*mcp.CallToolResult, listTemplatesResult, error) {
+		list, err := templates.LoadDir(dir)
+		if err != nil {
+			return nil, listTemplatesResult{}, err
+		}
+		out := listTemplatesResult{Directory: dir, Templates: []templateSummary{}}
+		var lines []string
+		for _, t := range list {
+			out.Templates = append(out.Templates, templateSummary{
+				Name:        t.Name,
+				Description: t.Description,
+				Title:       t.Title,
+				Variables:   t.Variables,
+				Path:        t.Path,
+			})
+			line := t.Name
+			if t.Description != "" {
+				line += " – " + t.Description
+			}
+			lines = append(lines, line)
+		}
+		text := fmt.Sprintf("No templates in %s", dir)
+		if len(lines) > 0 {
+			text = strings.Join(lines, "\n")
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-apply-template",
+		Description: "Create a project from a template, filling in its variables and relative dates",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input applyTemplateInput) (*mcp.CallToolResult, things.ImportResult, error) {
+		t, err := templates.Find(dir, input.Template)
+		if err != nil {
+			return nil, things.ImportResult{}, err
+		}
+		item, err := t.Expand(input.Variables, time.Now())
+		if err != nil {
+			return nil, things.ImportResult{}, err
+		}
+		return runImport(ctx, client, []things.JSONItem{item}, input.ImportOptions)
+	})
+}
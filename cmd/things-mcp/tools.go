@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/config"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type scriptOutput struct {
+	ID     string `json:"id,omitempty"`
+	DryRun bool   `json:"dryRun,omitempty"`
+	Script string `json:"script,omitempty" jsonschema:"the AppleScript a dry run would have run"`
+}
+
+// toolRegistry registers the tools permitted by the config and remembers
+// every name it was offered so typos in the allow and deny lists surface.
+// It also keeps each registered handler so scheduled jobs can call tools
+// outside an MCP request.
+type toolRegistry struct {
+	server  *mcp.Server
+	allowed *config.Tools
+	offered []string
+	calls   map[string]toolCall
+}
+
+// toolCall runs a registered tool with raw JSON arguments. check decodes the
+// arguments without running the tool.
+type toolCall struct {
+	check func(args json.RawMessage) error
+	run   func(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error)
+}
+
+// schemaOptions describes raw JSON fields as accepting any value; by default
+// they would be inferred as arrays of bytes.
+var schemaOptions = &jsonschema.ForOptions{
+	TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+		reflect.TypeFor[json.RawMessage](): {},
+	},
+}
+
+// addTool registers tool unless the config excludes it. Handlers see the
+// calling client's name in their context for provenance footers.
+func addTool[In, Out any](reg *toolRegistry, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	reg.offered = append(reg.offered, tool.Name)
+	if !reg.allowed.Allowed(tool.Name) {
+		return
+	}
+	if tool.InputSchema == nil {
+		tool.InputSchema = mustSchema[In]()
+	}
+	if tool.OutputSchema == nil && reflect.TypeFor[Out]() != reflect.TypeFor[any]() {
+		tool.OutputSchema = mustSchema[Out]()
+	}
+	mcp.AddTool(reg.server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		if params := sessionParams(req); params != nil && params.ClientInfo != nil {
+			ctx = things.WithClientName(ctx, params.ClientInfo.Name)
+		}
+		return handler(ctx, req, input)
+	})
+
+	if reg.calls == nil {
+		reg.calls = map[string]toolCall{}
+	}
+	reg.calls[tool.Name] = toolCall{
+		check: func(args json.RawMessage) error {
+			_, err := decodeArguments[In](args)
+			return err
+		},
+		run: func(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error) {
+			input, err := decodeArguments[In](args)
+			if err != nil {
+				return nil, err
+			}
+			res, out, err := handler(ctx, nil, input)
+			if err != nil || res != nil {
+				return res, err
+			}
+			// Tools without a text result report their structured output.
+			data, err := json.Marshal(out)
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}, nil
+		},
+	}
+}
+
+// decodeArguments strictly decodes tool arguments, treating none as {}.
+func decodeArguments[In any](args json.RawMessage) (In, error) {
+	var input In
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		return input, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return input, nil
+}
+
+func mustSchema[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](schemaOptions)
+	if err != nil {
+		panic(fmt.Sprintf("schema for %s: %v", reflect.TypeFor[T](), err))
+	}
+	return schema
+}
+
+func sessionParams(req *mcp.CallToolRequest) *mcp.InitializeParams {
+	if req == nil || req.Session == nil {
+		return nil
+	}
+	return req.Session.InitializeParams()
+}
+
+// unknown returns the configured tool names that match no tool.
+func (r *toolRegistry) unknown() []string {
+	var names []string
+	for _, name := range r.allowed.Names() {
+		if !slices.Contains(r.offered, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func registerTools(reg *toolRegistry, client *things.Client) {
+	addTool(reg, &mcp.Tool{
+		Name:        "things-add",
+		Description: "Create new to-dos in Things using the URL scheme",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.AddInput) (*mcp.CallToolResult, things.AddResult, error) {
+		out, err := client.Add(ctx, input)
+		if err != nil {
+			return nil, things.AddResult{}, err
+		}
+		return dispatchedAdd(out.Dispatch, "to-do", out.Duplicates), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-add-project",
+		Description: "Create new projects in Things",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.AddProjectInput) (*mcp.CallToolResult, things.AddProjectResult, error) {
+		out, err := client.AddProject(ctx, input)
+		if err != nil {
+			return nil, things.AddProjectResult{}, err
+		}
+		return dispatchedAdd(out.Dispatch, "project", out.Duplicates), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-update",
+		Description: "Update existing to-dos in Things. Omitted fields are left unchanged; list fields in clear to empty them",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.UpdateInput) (*mcp.CallToolResult, things.UpdateResult, error) {
+		out, err := client.Update(ctx, input)
+		if err != nil {
+			return nil, things.UpdateResult{}, err
+		}
+		return dispatched(out.Dispatch), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-update-project",
+		Description: "Update existing projects in Things. Omitted fields are left unchanged; list fields in clear to empty them",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.UpdateProjectInput) (*mcp.CallToolResult, things.UpdateProjectResult, error) {
+		out, err := client.UpdateProject(ctx, input)
+		if err != nil {
+			return nil, things.UpdateProjectResult{}, err
+		}
+		return dispatched(out.Dispatch), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-show",
+		Description: "Open Things lists, projects, or tags",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.ShowInput) (*mcp.CallToolResult, things.ShowResult, error) {
+		out, err := client.Show(ctx, input)
+		if err != nil {
+			return nil, things.ShowResult{}, err
+		}
+		return dispatched(out.Dispatch), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-search",
+		Description: "Open the Things search UI",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.SearchInput) (*mcp.CallToolResult, things.SearchResult, error) {
+		out, err := client.Search(ctx, input)
+		if err != nil {
+			return nil, things.SearchResult{}, err
+		}
+		return dispatched(out.Dispatch), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-version",
+		Description: "Reveal the Things app and URL scheme version dialog",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.VersionInput) (*mcp.CallToolResult, things.VersionResult, error) {
+		out, err := client.Version(ctx, input)
+		if err != nil {
+			return nil, things.VersionResult{}, err
+		}
+		return dispatched(out.Dispatch), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-resolve",
+		Description: "Resolve list, heading, and area names to Things IDs and check tags against the configured catalog",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.ResolveInput) (*mcp.CallToolResult, things.Resolution, error) {
+		res, err := client.Resolve(ctx, input)
+		if err != nil {
+			return nil, things.Resolution{}, err
+		}
+		return nil, res, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-json",
+		Description: "Invoke the Things JSON command for complex imports",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.JSONInput) (*mcp.CallToolResult, things.JSONResult, error) {
+		out, err := client.JSON(ctx, input)
+		if err != nil {
+			return nil, things.JSONResult{}, err
+		}
+		return dispatched(out.Dispatch), out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-add-area",
+		Description: "Create a new area in Things via AppleScript",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.AddAreaInput) (*mcp.CallToolResult, scriptOutput, error) {
+		id, err := client.AddArea(ctx, input)
+		if err != nil {
+			return nil, scriptOutput{}, err
+		}
+		res, out := scripted(client, "Created area", id)
+		return res, out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-add-tag",
+		Description: "Create a new tag in Things via AppleScript, optionally under a parent tag",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.AddTagInput) (*mcp.CallToolResult, scriptOutput, error) {
+		id, err := client.AddTag(ctx, input)
+		if err != nil {
+			return nil, scriptOutput{}, err
+		}
+		res, out := scripted(client, "Created tag", id)
+		return res, out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-delete",
+		Description: "Delete a to-do, project, area, or tag by ID via AppleScript. To-dos and projects go to the Trash; areas and tags are removed permanently",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.DeleteInput) (*mcp.CallToolResult, scriptOutput, error) {
+		id, err := client.Delete(ctx, input)
+		if err != nil {
+			return nil, scriptOutput{}, err
+		}
+		res, out := scripted(client, "Deleted", id)
+		return res, out, nil
+	})
+
+	addTool(reg, &mcp.Tool{
+		Name:        "things-move-to-trash",
+		Description: "Move a to-do or project to the Things Trash via AppleScript",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input things.MoveToTrashInput) (*mcp.CallToolResult, scriptOutput, error) {
+		id, err := client.MoveToTrash(ctx, input)
+		if err != nil {
+			return nil, scriptOutput{}, err
+		}
+		res, out := scripted(client, "Moved to Trash", id)
+		return res, out, nil
+	})
+}
+
+// scripted reports an AppleScript operation. On a dry-run client, result is
+// the script that was not run; otherwise it is the ID of the item acted on.
+func scripted(client *things.Client, action, result string) (*mcp.CallToolResult, scriptOutput) {
+	if client.DryRun() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Dry run, not run:\n" + result}},
+		}, scriptOutput{DryRun: true, Script: result}
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("%s %s", action, result)},
+		},
+	}, scriptOutput{ID: result}
+}
+
+func dispatched(d things.Dispatch) *mcp.CallToolResult {
+	return dispatchedAdd(d, "", nil)
+}
+
+// dispatchedAdd reports an add call that checked for duplicates of kind: a
+// line per title that was skipped or appended to an existing item, and the
+// dispatch only when something was created.
+func dispatchedAdd(d things.Dispatch, kind string, dups []things.Duplicate) *mcp.CallToolResult {
+	var lines []string
+	switch {
+	case d.URL == "":
+	case d.DryRun:
+		lines = append(lines, fmt.Sprintf("Dry run, not dispatched: %s", d.URL))
+	case d.Replayed:
+		lines = append(lines, fmt.Sprintf("Already dispatched for this idempotency key, not dispatched again: %s", d.URL))
+	default:
+		lines = append(lines, fmt.Sprintf("Dispatched %s", d.URL))
+	}
+	for _, dup := range dups {
+		switch dup.Action {
+		case "skipped":
+			lines = append(lines, fmt.Sprintf("Skipped %q: open %s %q (%s) is similar", dup.Title, kind, dup.ExistingTitle, dup.ID))
+		case "updated":
+			lines = append(lines, fmt.Sprintf("Appended the notes of %q to open %s %q (%s) instead of creating it", dup.Title, kind, dup.ExistingTitle, dup.ID))
+		}
+	}
+	for _, w := range d.Warnings {
+		lines = append(lines, fmt.Sprintf("Warning: %s %s", w.Field, w.Message))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}
+}
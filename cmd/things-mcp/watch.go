@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// changesURI is the resource listing recent changes made in Things.
+const changesURI = "things://changes"
+
+// maxChangeEvents bounds how many changes the resource keeps.
+const maxChangeEvents = 200
+
+type changeEvent struct {
+	Time time.Time `json:"time"`
+	things.Change
+}
+
+// changeFeed keeps the most recent changes for the changes resource.
+type changeFeed struct {
+	mu     sync.Mutex
+	events []changeEvent
+}
+
+func (f *changeFeed) add(at time.Time, changes []things.Change) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range changes {
+		f.events = append(f.events, changeEvent{Time: at, Change: c})
+	}
+	if extra := len(f.events) - maxChangeEvents; extra > 0 {
+		f.events = append([]changeEvent(nil), f.events[extra:]...)
+	}
+}
+
+func (f *changeFeed) read(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	f.mu.Lock()
+	data, err := json.MarshalIndent(struct {
+		Changes []changeEvent `json:"changes"`
+	}{append([]changeEvent{}, f.events...)}, "", "  ")
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: changesURI, MIMEType: "application/json", Text: string(data)}}}, nil
+}
+
+// subscribeChanges accepts subscriptions to the changes resource only.
+func subscribeChanges(ctx context.Context, req *mcp.SubscribeRequest) error {
+	if req.Params.URI != changesURI {
+		return fmt.Errorf("resource %q does not support subscriptions", req.Params.URI)
+	}
+	return nil
+}
+
+// watchChanges publishes changes made in Things, by hand or through sync, as
+// they show up in the database: it keeps them in the changes resource,
+// notifies sessions subscribed to it, and sends them as info log messages to
+// sessions that enabled logging. publish, when set, also receives them.
+func watchChanges(ctx context.Context, server *mcp.Server, db *things.DB, publish func([]things.Change)) {
+	feed := &changeFeed{}
+	server.AddResource(&mcp.Resource{
+		URI:         changesURI,
+		Name:        "changes",
+		Title:       "Recent changes in Things",
+		Description: "Projects and to-dos added, edited, completed, canceled, or deleted in Things since the server started, oldest first",
+		MIMEType:    "application/json",
+	}, feed.read)
+
+	w := things.NewWatcher(db)
+	w.Logf = log.Printf
+	w.OnChange = func(changes []things.Change) {
+		feed.add(time.Now(), changes)
+		server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: changesURI})
+		for session := range server.Sessions() {
+			session.Log(ctx, &mcp.LoggingMessageParams{Level: "info", Logger: "things", Data: map[string]any{"changes": changes}})
+		}
+		if publish != nil {
+			publish(changes)
+		}
+	}
+	go func() {
+		if err := w.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("watch: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/moonbase/things-mcp/internal/config"
+	"github.com/moonbase/things-mcp/internal/things"
+	"github.com/moonbase/things-mcp/internal/webhook"
+)
+
+// newWebhookSender returns a sender for the configured webhooks, or nil when
+// there are none. Undeliverable events go to a file in the state directory.
+func newWebhookSender(ctx context.Context, settings config.Settings) (*webhook.Sender, error) {
+	if len(settings.Webhooks) == 0 {
+		return nil, nil
+	}
+	var endpoints []webhook.Endpoint
+	for _, w := range settings.Webhooks {
+		endpoint := webhook.Endpoint{URL: w.URL, Events: w.Events}
+		if w.Secret != nil {
+			secret, err := w.Secret.Token(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %s: secret: %w", w.URL, err)
+			}
+			endpoint.Secret = secret
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	dir, err := config.StateDir()
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: %w", err)
+	}
+	sender := webhook.NewSender(endpoints, filepath.Join(dir, "webhooks-failed.jsonl"))
+	sender.Logf = log.Printf
+	return sender, nil
+}
+
+func publishDispatch(sender *webhook.Sender) func(context.Context, things.DispatchEvent) {
+	return func(_ context.Context, e things.DispatchEvent) {
+		text := fmt.Sprintf("Sent %s to Things", e.Command)
+		if title := e.Params["title"]; title != "" {
+			text += fmt.Sprintf(": %q", title)
+		}
+		sender.Publish(webhook.EventDispatch, text, e)
+	}
+}
+
+func publishChanges(sender *webhook.Sender) func([]things.Change) {
+	return func(changes []things.Change) {
+		var parts []string
+		for i, c := range changes {
+			if i == 5 {
+				parts = append(parts, fmt.Sprintf("and %d more", len(changes)-i))
+				break
+			}
+			parts = append(parts, fmt.Sprintf("%s %q", c.Kind, c.Title))
+		}
+		noun := "changes"
+		if len(changes) == 1 {
+			noun = "change"
+		}
+		text := fmt.Sprintf("%d %s in Things: %s", len(changes), noun, strings.Join(parts, ", "))
+		sender.Publish(webhook.EventChange, text, map[string]any{"changes": changes})
+	}
+}
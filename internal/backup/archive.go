@@ -0,0 +1,114 @@
+// Package backup saves snapshots of the Things library to versioned archives
+// and restores them through the json command.
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// Version is the archive format written by this build. Restore reads this
+// version and older ones.
+const Version = 1
+
+// Archive is a backup of the Things library.
+type Archive struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Logbook   bool             `json:"logbook"`
+	Snapshot  *things.Snapshot `json:"snapshot"`
+
+	// digest identifies the archive file so restore progress is only reused
+	// for the same archive.
+	digest string
+}
+
+// Create reads the open items, and the Logbook when logbook is set, from db.
+func Create(ctx context.Context, db *things.DB, logbook bool, now time.Time) (*Archive, error) {
+	snap, err := db.Snapshot(ctx, things.SnapshotOptions{IncludeClosed: logbook})
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{Version: Version, CreatedAt: now.UTC(), Logbook: logbook, Snapshot: snap}, nil
+}
+
+// DefaultPath names a timestamped archive in dir.
+func DefaultPath(dir string, now time.Time) string {
+	return filepath.Join(dir, "things-"+now.Format("20060102-150405")+".json.gz")
+}
+
+// Save writes the archive to path, gzip-compressed when path ends in ".gz".
+// The file is replaced atomically.
+func (a *Archive) Save(path string) error {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		zw = gzip.NewWriter(&buf)
+		w = zw
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(a); err != nil {
+		return fmt.Errorf("encode archive: %w", err)
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compress archive: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads an archive written by Save.
+func Load(path string) (*Archive, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	var r io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("read archive %s: %w", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	var a Archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("parse archive %s: %w", path, err)
+	}
+	switch {
+	case a.Version < 1 || a.Snapshot == nil:
+		return nil, fmt.Errorf("%s is not a things-mcp backup", path)
+	case a.Version > Version:
+		return nil, fmt.Errorf("%s is a version %d backup; this build reads up to version %d", path, a.Version, Version)
+	}
+	a.digest = hex.EncodeToString(sum[:])
+	return &a, nil
+}
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+func sampleArchive() *Archive {
+	created := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+	done := time.Date(2025, 1, 5, 14, 30, 0, 0, time.UTC)
+	return &Archive{
+		Version:   Version,
+		CreatedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		Logbook:   true,
+		Snapshot: &things.Snapshot{
+			Areas: []things.Area{{ID: "area-work", Title: "Work", Tags: []string{"Office"}, Projects: []things.Project{{
+				Task: things.Task{ID: "proj-launch", Title: "Launch", Status: things.StatusOpen, CreationDate: created},
+				ToDos: []things.ToDo{
+					{Task: things.Task{ID: "todo-plan", Title: "Plan", Status: things.StatusCompleted, Tags: []string{"Urgent"}, CreationDate: created, CompletionDate: &done}},
+				},
+			}}}},
+			ToDos: []things.ToDo{{Task: things.Task{ID: "todo-inbox", Title: "Call mom", Status: things.StatusOpen, CreationDate: created}}},
+		},
+	}
+}
+
+func TestArchiveRoundTrips(t *testing.T) {
+	for _, name := range []string{"backup.json", "backup.json.gz"} {
+		path := filepath.Join(t.TempDir(), "nested", name)
+		if err := sampleArchive().Save(path); err != nil {
+			t.Fatalf("Save(%s) returned error: %v", name, err)
+		}
+		a, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load(%s) returned error: %v", name, err)
+		}
+		if a.Version != Version || !a.Logbook || a.digest == "" {
+			t.Errorf("%s: archive = %+v", name, a)
+		}
+		plan := a.Snapshot.Areas[0].Projects[0].ToDos[0]
+		if plan.CompletionDate == nil || !plan.CompletionDate.Equal(time.Date(2025, 1, 5, 14, 30, 0, 0, time.UTC)) {
+			t.Errorf("%s: completion date = %v", name, plan.CompletionDate)
+		}
+	}
+}
+
+func TestLoadRejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"other.json":  `{"items": []}`,
+		"future.json": `{"version": 99, "snapshot": {}}`,
+		"broken.json": `{`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%s) returned no error", name)
+		}
+	}
+	if _, err := Load(filepath.Join(dir, "future.json")); err == nil || !strings.Contains(err.Error(), "version 99") {
+		t.Errorf("future archive error = %v", err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	got := DefaultPath("/backups", time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC))
+	if got != "/backups/things-20250304-050607.json.gz" {
+		t.Errorf("DefaultPath = %s", got)
+	}
+}
@@ -0,0 +1,214 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/moonbase/things-mcp/internal/exporter"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// RestoreOptions control a restore.
+type RestoreOptions struct {
+	// ProgressPath records how far the restore got so an interrupted one
+	// resumes where it stopped. It defaults to the archive path plus
+	// ".restore".
+	ProgressPath string
+	// SkipAreas leaves creating areas and tags, which the json command
+	// cannot do, to the user.
+	SkipAreas bool
+	// DryRun reports what would be restored without creating anything or
+	// recording progress.
+	DryRun bool
+	// Logf, when set, receives a line per step.
+	Logf func(format string, args ...any)
+}
+
+// RestoreResult reports what a restore created.
+type RestoreResult struct {
+	AreasCreated []string
+	Tags         int
+	Projects     int
+	ToDos        int
+	Chunks       int
+	// Resumed is the number of chunks an earlier run had already sent.
+	Resumed int
+}
+
+// progress is the state file of a restore.
+type progress struct {
+	path string
+
+	Archive string `json:"archive"`
+	Areas   bool   `json:"areas"`
+	Chunks  int    `json:"chunks"`
+	Total   int    `json:"total"`
+}
+
+// Restore recreates the archive's projects, headings, to-dos, and checklists
+// through the json command, with their original creation and completion
+// dates. Missing areas and the archive's tags are created through AppleScript
+// first; db, when set, is used to skip areas that already exist. Chunks are
+// sent at the pace Things accepts and recorded as they go, so running Restore
+// again after an interruption continues with the next chunk.
+func Restore(ctx context.Context, client *things.Client, db *things.DB, a *Archive, path string, opts RestoreOptions) (RestoreResult, error) {
+	logf := opts.Logf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	if opts.ProgressPath == "" {
+		opts.ProgressPath = path + ".restore"
+	}
+	state, err := loadProgress(opts.ProgressPath, a.digest)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	if state.Total > 0 && state.Chunks >= state.Total {
+		return RestoreResult{}, fmt.Errorf("this archive was already restored; remove %s to restore it again", opts.ProgressPath)
+	}
+	save := func() error {
+		if opts.DryRun {
+			return nil
+		}
+		return state.save()
+	}
+
+	var res RestoreResult
+	if !state.Areas && !opts.SkipAreas {
+		if res.AreasCreated, res.Tags, err = restoreAreas(ctx, client, db, a.Snapshot, opts.DryRun, logf); err != nil {
+			return res, err
+		}
+		state.Areas = true
+		if err := save(); err != nil {
+			return res, err
+		}
+	}
+
+	items := exporter.Items(a.Snapshot)
+	if len(items) == 0 {
+		logf("the archive holds no projects or to-dos")
+		return res, nil
+	}
+	res.Resumed = state.Chunks
+	if state.Chunks > 0 {
+		logf("resuming after chunk %d of %d", state.Chunks, state.Total)
+	}
+	out, err := client.Import(ctx, things.ImportInput{
+		Items:  items,
+		Resume: state.Chunks,
+		// Restored items keep their markers; rewriting them into updates
+		// would move chunk boundaries between runs.
+		Raw: true,
+		Progress: func(done, total int, _ []things.JSONItem) error {
+			res.Chunks++
+			state.Chunks, state.Total = done, total
+			if opts.DryRun {
+				logf("built chunk %d of %d", done, total)
+			} else {
+				logf("sent chunk %d of %d", done, total)
+			}
+			return save()
+		},
+	})
+	res.Projects, res.ToDos = out.Projects, out.ToDos
+	if err != nil {
+		return res, fmt.Errorf("%w; run restore again to continue", err)
+	}
+	return res, nil
+}
+
+// restoreAreas creates the snapshot's tags and the areas that do not exist
+// yet. Creating a tag that exists leaves it alone.
+func restoreAreas(ctx context.Context, client *things.Client, db *things.DB, snap *things.Snapshot, dryRun bool, logf func(string, ...any)) ([]string, int, error) {
+	existing := map[string]bool{}
+	if db != nil {
+		catalog, err := db.Catalog(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, area := range catalog.Areas {
+			existing[strings.ToLower(area.Title)] = true
+		}
+	}
+
+	var tags []string
+	for _, area := range snap.Areas {
+		tags = append(tags, area.Tags...)
+	}
+	for _, e := range snap.Entries() {
+		tags = append(tags, e.Tags...)
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	for _, tag := range tags {
+		if dryRun {
+			continue
+		}
+		if _, err := client.AddTag(ctx, things.AddTagInput{Title: tag}); err != nil {
+			return nil, 0, fmt.Errorf("create tag %q: %w", tag, err)
+		}
+	}
+	if len(tags) > 0 {
+		logf("ensured %d tags exist", len(tags))
+	}
+
+	var created []string
+	for _, area := range snap.Areas {
+		if existing[strings.ToLower(area.Title)] {
+			continue
+		}
+		if !dryRun {
+			if _, err := client.AddArea(ctx, things.AddAreaInput{Title: area.Title, Tags: area.Tags}); err != nil {
+				return created, len(tags), fmt.Errorf("create area %q: %w", area.Title, err)
+			}
+		}
+		created = append(created, area.Title)
+		if dryRun {
+			logf("would create area %s", area.Title)
+		} else {
+			logf("created area %s", area.Title)
+		}
+	}
+	return created, len(tags), nil
+}
+
+func loadProgress(path, digest string) (*progress, error) {
+	state := &progress{path: path, Archive: digest}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read restore progress: %w", err)
+	}
+	var saved progress
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parse restore progress %s: %w", path, err)
+	}
+	if saved.Archive != digest {
+		return nil, fmt.Errorf("%s belongs to a different archive; remove it to start over", path)
+	}
+	saved.path = path
+	return &saved, nil
+}
+
+func (p *progress) save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return fmt.Errorf("write restore progress: %w", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write restore progress: %w", err)
+	}
+	return os.Rename(tmp, p.path)
+}
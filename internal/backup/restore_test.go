@@ -0,0 +1,145 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+type recordingLauncher struct {
+	calls []string
+	err   error
+}
+
+func (l *recordingLauncher) Launch(_ context.Context, target string) error {
+	l.calls = append(l.calls, target)
+	return l.err
+}
+
+type recordingScripts struct {
+	scripts []string
+}
+
+func (r *recordingScripts) Run(_ context.Context, script string) (string, error) {
+	r.scripts = append(r.scripts, script)
+	return "new-id", nil
+}
+
+func savedArchive(t *testing.T, a *Archive) (*Archive, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err := a.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loaded, path
+}
+
+func TestRestoreReplaysArchive(t *testing.T) {
+	a, path := savedArchive(t, sampleArchive())
+	launcher, scripts := &recordingLauncher{}, &recordingScripts{}
+	client := things.NewClient(things.Config{Launcher: launcher, Scripts: scripts})
+
+	res, err := Restore(context.Background(), client, nil, a, path, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if len(res.AreasCreated) != 1 || res.Tags != 2 || res.Projects != 1 || res.ToDos != 2 || res.Chunks != 1 {
+		t.Errorf("result = %+v", res)
+	}
+	if len(scripts.scripts) != 3 || !strings.Contains(scripts.scripts[2], `make new area with properties {name:"Work"`) {
+		t.Errorf("scripts = %q", scripts.scripts)
+	}
+	if len(launcher.calls) != 1 {
+		t.Fatalf("launched %d URLs, want 1", len(launcher.calls))
+	}
+	data, _ := url.QueryUnescape(launcher.calls[0])
+	for _, want := range []string{`"area":"Work"`, `"creation-date":"2025-01-02T09:00:00Z"`, `"completion-date":"2025-01-05T14:30:00Z"`} {
+		if !strings.Contains(data, want) {
+			t.Errorf("payload %s missing %s", data, want)
+		}
+	}
+
+	if _, err := Restore(context.Background(), client, nil, a, path, RestoreOptions{}); err == nil || !strings.Contains(err.Error(), "already restored") {
+		t.Errorf("second restore error = %v", err)
+	}
+}
+
+func TestRestoreResumes(t *testing.T) {
+	archive := sampleArchive()
+	for i := 0; i < 300; i++ {
+		archive.Snapshot.ToDos = append(archive.Snapshot.ToDos, things.ToDo{Task: things.Task{Title: fmt.Sprintf("To-do %d", i)}})
+	}
+	a, path := savedArchive(t, archive)
+
+	// An earlier run sent the first of the two chunks.
+	launcher := &recordingLauncher{}
+	client := things.NewClient(things.Config{Launcher: launcher, Scripts: &recordingScripts{}})
+	state := &progress{path: path + ".restore", Archive: a.digest, Areas: true, Chunks: 1, Total: 2}
+	if err := state.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Restore(context.Background(), client, nil, a, path, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if res.Resumed != 1 || res.Chunks != 1 || len(launcher.calls) != 1 || len(res.AreasCreated) != 0 {
+		t.Errorf("result = %+v after %d launches", res, len(launcher.calls))
+	}
+}
+
+func TestRestoreKeepsProgressOnFailure(t *testing.T) {
+	a, path := savedArchive(t, sampleArchive())
+	client := things.NewClient(things.Config{Launcher: &recordingLauncher{err: errors.New("things is not running")}, Scripts: &recordingScripts{}})
+
+	if _, err := Restore(context.Background(), client, nil, a, path, RestoreOptions{}); err == nil || !strings.Contains(err.Error(), "run restore again") {
+		t.Fatalf("error = %v", err)
+	}
+	state, err := loadProgress(path+".restore", a.digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Areas || state.Chunks != 0 {
+		t.Errorf("progress = %+v, want areas done and no chunks", state)
+	}
+}
+
+func TestRestoreDryRunChangesNothing(t *testing.T) {
+	a, path := savedArchive(t, sampleArchive())
+	scripts := &recordingScripts{}
+	client := things.NewClient(things.Config{Launcher: &recordingLauncher{}, Scripts: scripts, DryRun: true})
+
+	res, err := Restore(context.Background(), client, nil, a, path, RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if len(scripts.scripts) != 0 || len(res.AreasCreated) != 1 {
+		t.Errorf("dry run ran %d scripts, result %+v", len(scripts.scripts), res)
+	}
+	if _, err := os.Stat(path + ".restore"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("dry run wrote progress: %v", err)
+	}
+}
+
+func TestRestoreRejectsProgressOfOtherArchive(t *testing.T) {
+	a, path := savedArchive(t, sampleArchive())
+	state := &progress{path: path + ".restore", Archive: "other", Chunks: 1, Total: 2}
+	if err := state.save(); err != nil {
+		t.Fatal(err)
+	}
+	client := things.NewClient(things.Config{Launcher: &recordingLauncher{}})
+	if _, err := Restore(context.Background(), client, nil, a, path, RestoreOptions{}); err == nil || !strings.Contains(err.Error(), "different archive") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+// Package calendar does the calendar-day arithmetic of the packages that
+// analyse a Things snapshot, where deadlines are dates and ages count days.
+package calendar
+
+import "time"
+
+// Day returns midnight at the start of t's day, in t's location.
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Parse reads a YYYY-MM-DD date such as a deadline as midnight in loc. It
+// returns the zero time for anything else.
+func Parse(value string, loc *time.Location) time.Time {
+	t, _ := time.ParseInLocation(time.DateOnly, value, loc)
+	return t
+}
+
+// DaysBetween counts calendar days from a to b, ignoring DST shifts.
+func DaysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
@@ -0,0 +1,25 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarDays(t *testing.T) {
+	// Europe/Berlin moves its clocks forward on 2025-03-30.
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	start := Day(time.Date(2025, 3, 29, 22, 30, 0, 0, loc))
+	if want := time.Date(2025, 3, 29, 0, 0, 0, 0, loc); !start.Equal(want) {
+		t.Errorf("Day = %v, want %v", start, want)
+	}
+	deadline := Parse("2025-04-02", loc)
+	if got := DaysBetween(start, deadline); got != 4 {
+		t.Errorf("DaysBetween = %d, want 4", got)
+	}
+	if !Parse("someday", loc).IsZero() {
+		t.Error("Parse accepted a keyword")
+	}
+}
@@ -0,0 +1,429 @@
+// Package config loads the things-mcp configuration file and its profiles.
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the on-disk configuration. The top-level settings apply to every
+// profile; a selected profile overrides them field by field.
+type File struct {
+	Profile  string `yaml:"profile"`
+	Settings `yaml:",inline"`
+	Profiles map[string]Settings `yaml:"profiles"`
+}
+
+// Settings configures one server instance.
+type Settings struct {
+	// Profile is the name of the resolved profile, set by Resolve; it is
+	// empty when no profile applies.
+	Profile           string            `yaml:"-"`
+	AuthToken         *TokenSource      `yaml:"authToken"`
+	Activate          *bool             `yaml:"activate"`
+	Reveal            *bool             `yaml:"reveal"`
+	Database          string            `yaml:"database"`
+	Catalog           string            `yaml:"catalog"`
+	CreateMissingTags *bool             `yaml:"createMissingTags"`
+	DryRun            *bool             `yaml:"dryRun"`
+	Strict            *bool             `yaml:"strict"`
+	Defaults          *Defaults         `yaml:"defaults"`
+	Tools             *Tools            `yaml:"tools"`
+	RateLimit         *RateLimit        `yaml:"rateLimit"`
+	Idempotency       *Idempotency      `yaml:"idempotency"`
+	IssueLabels       map[string]string `yaml:"issueLabels"`
+	Templates         string            `yaml:"templates"`
+	Schedules         []Schedule        `yaml:"schedules"`
+	Webhooks          []Webhook         `yaml:"webhooks"`
+}
+
+// TokenSource says where to read the Things auth token from. Exactly one
+// field must be set.
+type TokenSource struct {
+	Value   string   `yaml:"value"`
+	Env     string   `yaml:"env"`
+	File    string   `yaml:"file"`
+	Command []string `yaml:"command"`
+}
+
+// Defaults are applied to items created by agents.
+type Defaults struct {
+	List       string   `yaml:"list"`
+	ListID     string   `yaml:"listId"`
+	Heading    string   `yaml:"heading"`
+	Tags       []string `yaml:"tags"`
+	Provenance bool     `yaml:"provenance"`
+}
+
+// Tools restricts which MCP tools the server registers.
+type Tools struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// Allowed reports whether the named tool should be registered.
+func (t *Tools) Allowed(name string) bool {
+	if t == nil {
+		return true
+	}
+	if len(t.Allow) > 0 {
+		return slices.Contains(t.Allow, name)
+	}
+	return !slices.Contains(t.Deny, name)
+}
+
+// Names returns every tool named in the allow and deny lists.
+func (t *Tools) Names() []string {
+	if t == nil {
+		return nil
+	}
+	return append(append([]string{}, t.Allow...), t.Deny...)
+}
+
+// RateLimit caps how many commands are dispatched to Things per window.
+type RateLimit struct {
+	Requests int      `yaml:"requests"`
+	Per      Duration `yaml:"per"`
+}
+
+// Idempotency configures where idempotency keys are kept and for how long.
+type Idempotency struct {
+	Path string   `yaml:"path"`
+	TTL  Duration `yaml:"ttl"`
+}
+
+// Schedule is a recurring rule: at each time matching Cron it either adds a
+// to-do or applies a project template.
+type Schedule struct {
+	Name      string            `yaml:"name"`
+	Cron      string            `yaml:"cron"`
+	Add       *ScheduledToDo    `yaml:"add"`
+	Template  string            `yaml:"template"`
+	Variables map[string]string `yaml:"variables"`
+}
+
+// ScheduledToDo is the to-do a schedule adds. When and Deadline take the
+// same date expressions as templates, counted from the day the rule runs.
+type ScheduledToDo struct {
+	Title     string   `yaml:"title"`
+	Notes     string   `yaml:"notes"`
+	When      string   `yaml:"when"`
+	Deadline  string   `yaml:"deadline"`
+	Tags      []string `yaml:"tags"`
+	Checklist []string `yaml:"checklist"`
+	List      string   `yaml:"list"`
+	Heading   string   `yaml:"heading"`
+}
+
+// Webhook is an HTTP endpoint that receives events as JSON POSTs.
+type Webhook struct {
+	URL string `yaml:"url"`
+	// Secret signs each body with HMAC-SHA256.
+	Secret *TokenSource `yaml:"secret"`
+	// Events limits the events sent to dispatch or change; empty means all.
+	Events []string `yaml:"events"`
+}
+
+// Duration decodes Go duration strings such as "10s" or "1m".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", node.Line, node.Value)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// DefaultPath returns the first config file found in the XDG config
+// directory, or "" when there is none.
+func DefaultPath() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	for _, name := range []string{"config.yaml", "config.yml", "config.json"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// DefaultTemplateDir is where project templates live unless the config says
+// otherwise, or "" when there is no home directory.
+func DefaultTemplateDir() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "templates")
+}
+
+// configDir returns the things-mcp directory under $XDG_CONFIG_HOME.
+func configDir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "things-mcp")
+}
+
+// StateDir returns the directory for state the server keeps between runs,
+// following $XDG_STATE_HOME.
+func StateDir() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "things-mcp"), nil
+}
+
+// Load reads and strictly decodes the YAML or JSON file at path.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	var file File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// Resolve merges the named profile, or the file's default profile when name
+// is empty, over the top-level settings and validates the result.
+func (f *File) Resolve(name string) (Settings, error) {
+	if name == "" {
+		name = f.Profile
+	}
+
+	settings := f.Settings
+	if name != "" {
+		profile, ok := f.Profiles[name]
+		if !ok {
+			return Settings{}, fmt.Errorf("profile %q not found; available profiles: %s", name, strings.Join(f.profileNames(), ", "))
+		}
+		settings = settings.merge(profile)
+		settings.Profile = name
+	}
+
+	if err := settings.validate(); err != nil {
+		if name != "" {
+			return Settings{}, fmt.Errorf("profile %q: %w", name, err)
+		}
+		return Settings{}, err
+	}
+	return settings, nil
+}
+
+func (f *File) profileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s Settings) merge(o Settings) Settings {
+	if o.AuthToken != nil {
+		s.AuthToken = o.AuthToken
+	}
+	if o.Activate != nil {
+		s.Activate = o.Activate
+	}
+	if o.Reveal != nil {
+		s.Reveal = o.Reveal
+	}
+	if o.Database != "" {
+		s.Database = o.Database
+	}
+	if o.Catalog != "" {
+		s.Catalog = o.Catalog
+	}
+	if o.CreateMissingTags != nil {
+		s.CreateMissingTags = o.CreateMissingTags
+	}
+	if o.DryRun != nil {
+		s.DryRun = o.DryRun
+	}
+	if o.Strict != nil {
+		s.Strict = o.Strict
+	}
+	if o.Defaults != nil {
+		s.Defaults = o.Defaults
+	}
+	if o.Tools != nil {
+		s.Tools = o.Tools
+	}
+	if o.RateLimit != nil {
+		s.RateLimit = o.RateLimit
+	}
+	if o.Idempotency != nil {
+		s.Idempotency = o.Idempotency
+	}
+	if o.IssueLabels != nil {
+		s.IssueLabels = o.IssueLabels
+	}
+	if o.Templates != "" {
+		s.Templates = o.Templates
+	}
+	if o.Schedules != nil {
+		s.Schedules = o.Schedules
+	}
+	if o.Webhooks != nil {
+		s.Webhooks = o.Webhooks
+	}
+	return s
+}
+
+func (s Settings) validate() error {
+	if s.AuthToken != nil {
+		if err := s.AuthToken.validate(); err != nil {
+			return fmt.Errorf("authToken: %w", err)
+		}
+	}
+	if s.Defaults != nil && s.Defaults.List != "" && s.Defaults.ListID != "" {
+		return errors.New("defaults: set list or listId, not both")
+	}
+	if s.Tools != nil && len(s.Tools.Allow) > 0 && len(s.Tools.Deny) > 0 {
+		return errors.New("tools: set allow or deny, not both")
+	}
+	if s.RateLimit != nil && (s.RateLimit.Requests <= 0 || s.RateLimit.Per.Duration <= 0) {
+		return errors.New("rateLimit: requests and per must both be positive")
+	}
+	if s.Idempotency != nil && s.Idempotency.TTL.Duration < 0 {
+		return errors.New("idempotency: ttl must not be negative")
+	}
+	names := map[string]bool{}
+	for i, sch := range s.Schedules {
+		if err := sch.validate(); err != nil {
+			return fmt.Errorf("schedules[%d]: %w", i, err)
+		}
+		if names[sch.Name] {
+			return fmt.Errorf("schedules[%d]: duplicate name %q", i, sch.Name)
+		}
+		names[sch.Name] = true
+	}
+	for i, w := range s.Webhooks {
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("webhooks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (w Webhook) validate() error {
+	if !strings.HasPrefix(w.URL, "https://") && !strings.HasPrefix(w.URL, "http://") {
+		return fmt.Errorf("url %q must start with http:// or https://", w.URL)
+	}
+	if w.Secret != nil {
+		if err := w.Secret.validate(); err != nil {
+			return fmt.Errorf("secret: %w", err)
+		}
+	}
+	for _, e := range w.Events {
+		if e != "dispatch" && e != "change" {
+			return fmt.Errorf("unknown event %q; use dispatch or change", e)
+		}
+	}
+	return nil
+}
+
+func (s Schedule) validate() error {
+	switch {
+	case s.Name == "":
+		return errors.New("name is required")
+	case s.Cron == "":
+		return errors.New("cron is required")
+	case (s.Add == nil) == (s.Template == ""):
+		return errors.New("set either add or template")
+	case s.Add != nil && s.Add.Title == "":
+		return errors.New("add: title is required")
+	case s.Add != nil && len(s.Variables) > 0:
+		return errors.New("variables only apply to templates")
+	}
+	return nil
+}
+
+func (t *TokenSource) validate() error {
+	set := 0
+	for _, ok := range []bool{t.Value != "", t.Env != "", t.File != "", len(t.Command) > 0} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("set exactly one of value, env, file, or command")
+	}
+	return nil
+}
+
+// Token reads the auth token from its source.
+func (t *TokenSource) Token(ctx context.Context) (string, error) {
+	switch {
+	case t.Value != "":
+		return t.Value, nil
+	case t.Env != "":
+		token := os.Getenv(t.Env)
+		if token == "" {
+			return "", fmt.Errorf("environment variable %s is empty", t.Env)
+		}
+		return token, nil
+	case t.File != "":
+		data, err := os.ReadFile(ExpandHome(t.File))
+		if err != nil {
+			return "", fmt.Errorf("read token file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case len(t.Command) > 0:
+		out, err := exec.CommandContext(ctx, t.Command[0], t.Command[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("run token command: %w", err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", errors.New("no token source configured")
+}
+
+// ExpandHome replaces a leading "~/" with the user's home directory.
+func ExpandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const sampleConfig = `
+profile: personal
+dryRun: false
+defaults:
+  tags: [agent]
+rateLimit:
+  requests: 250
+  per: 10s
+issueLabels:
+  bug: Bug
+profiles:
+  personal:
+    database: auto
+  work:
+    authToken:
+      env: THINGS_WORK_TOKEN
+    catalog: ~/work-catalog.yaml
+    dryRun: true
+    tools:
+      deny: [things-delete, things-move-to-trash]
+`
+
+func TestResolveMergesProfileOverTopLevel(t *testing.T) {
+	file, err := Load(writeConfig(t, sampleConfig))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	work, err := file.Resolve("work")
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if work.Profile != "work" {
+		t.Fatalf("Profile = %q, want work", work.Profile)
+	}
+	if work.DryRun == nil || !*work.DryRun {
+		t.Fatalf("expected work profile to enable dry run, got %v", work.DryRun)
+	}
+	if work.AuthToken == nil || work.AuthToken.Env != "THINGS_WORK_TOKEN" {
+		t.Fatalf("unexpected auth token source: %+v", work.AuthToken)
+	}
+	if work.Defaults == nil || work.Defaults.Tags[0] != "agent" {
+		t.Fatalf("expected top-level defaults to carry over, got %+v", work.Defaults)
+	}
+	if work.RateLimit == nil || work.RateLimit.Per.Duration != 10*time.Second {
+		t.Fatalf("unexpected rate limit: %+v", work.RateLimit)
+	}
+	if work.IssueLabels["bug"] != "Bug" {
+		t.Fatalf("unexpected issue labels: %v", work.IssueLabels)
+	}
+	if work.Tools.Allowed("things-delete") || !work.Tools.Allowed("things-add") {
+		t.Fatal("deny list not applied")
+	}
+
+	personal, err := file.Resolve("")
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if personal.Profile != "personal" || personal.Database != "auto" || personal.Catalog != "" {
+		t.Fatalf("expected default profile to be personal, got %+v", personal)
+	}
+}
+
+func TestResolveUnknownProfile(t *testing.T) {
+	file, err := Load(writeConfig(t, sampleConfig))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	_, err = file.Resolve("home")
+	if err == nil || !strings.Contains(err.Error(), "available profiles: personal, work") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	_, err := Load(writeConfig(t, "dryrun: true\n"))
+	if err == nil || !strings.Contains(err.Error(), "dryrun") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestLoadAcceptsJSON(t *testing.T) {
+	file, err := Load(writeConfig(t, `{"strict": true, "profiles": {"ci": {"dryRun": true}}}`))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	settings, err := file.Resolve("ci")
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if !*settings.Strict || !*settings.DryRun {
+		t.Fatalf("unexpected settings: %+v", settings)
+	}
+}
+
+func TestResolveValidates(t *testing.T) {
+	tests := map[string]string{
+		"authToken":        "authToken:\n  value: a\n  env: B\n",
+		"defaults":         "defaults:\n  list: Inbox\n  listId: abc\n",
+		"tools":            "tools:\n  allow: [things-add]\n  deny: [things-delete]\n",
+		"rateLimit":        "rateLimit:\n  requests: 0\n  per: 10s\n",
+		"invalid duration": "rateLimit:\n  requests: 5\n  per: soon\n",
+		"webhook url":      "webhooks:\n  - url: hooks.example.com\n",
+		"webhook event":    "webhooks:\n  - url: https://hooks.example.com\n    events: [deleted]\n",
+		"schedule action":  "schedules:\n  - name: plants\n    cron: '@daily'\n",
+		"schedule name":    "schedules:\n  - {name: a, cron: '@daily', template: t}\n  - {name: a, cron: '@daily', template: t}\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, err := Load(writeConfig(t, data))
+			if err == nil {
+				_, err = file.Resolve("")
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestTokenSources(t *testing.T) {
+	t.Setenv("THINGS_TEST_TOKEN", "from-env")
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		source TokenSource
+		want   string
+	}{
+		{TokenSource{Value: "inline"}, "inline"},
+		{TokenSource{Env: "THINGS_TEST_TOKEN"}, "from-env"},
+		{TokenSource{File: path}, "from-file"},
+		{TokenSource{Command: []string{"echo", "from-command"}}, "from-command"},
+	}
+	for _, tt := range tests {
+		got, err := tt.source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token(%+v) returned error: %v", tt.source, err)
+		}
+		if got != tt.want {
+			t.Fatalf("Token(%+v) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+
+	if _, err := (&TokenSource{Env: "THINGS_TEST_UNSET"}).Token(context.Background()); err == nil {
+		t.Fatal("expected error for empty environment variable")
+	}
+}
+
+func TestToolsAllowList(t *testing.T) {
+	tools := &Tools{Allow: []string{"things-add", "things-show"}}
+	if !tools.Allowed("things-add") || tools.Allowed("things-delete") {
+		t.Fatal("allow list not applied")
+	}
+	var none *Tools
+	if !none.Allowed("things-delete") {
+		t.Fatal("nil tools should allow everything")
+	}
+}
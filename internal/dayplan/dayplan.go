@@ -0,0 +1,244 @@
+// Package dayplan builds a plan for the day from a Things snapshot: it picks
+// candidate to-dos, estimates how long each takes, ranks them, and fits them
+// into a time budget for today and this evening, moving the rest to
+// tomorrow.
+package dayplan
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// Defaults for Options.
+const (
+	DefaultBudget       = 6 * time.Hour
+	DefaultDuration     = 30 * time.Minute
+	DefaultDeadlineDays = 3
+)
+
+// Slots a to-do can be planned for; they are the when values set in Things.
+const (
+	SlotToday    = "today"
+	SlotEvening  = "evening"
+	SlotTomorrow = "tomorrow"
+)
+
+// Options describe what to plan and how much time there is.
+type Options struct {
+	// Now is the day being planned, in its location.
+	Now time.Time
+	// Budget is the time available today; it defaults to six hours.
+	Budget time.Duration
+	// Evening is the time available this evening, after Budget is used up.
+	Evening time.Duration
+	// DeadlineDays makes to-dos due within that many days candidates; it
+	// defaults to three. Overdue to-dos are always candidates.
+	DeadlineDays int
+	// Tags makes to-dos with any of these tags candidates. They rank in the
+	// order given, after deadlines.
+	Tags []string
+	// Durations estimates to-dos by tag when neither a duration tag nor the
+	// notes give one.
+	Durations map[string]time.Duration
+	// DefaultDuration estimates to-dos nothing else does; it defaults to 30
+	// minutes.
+	DefaultDuration time.Duration
+}
+
+// Plan is the ranked list of candidates with the slot each was given.
+type Plan struct {
+	Date            string   `json:"date"`
+	BudgetMinutes   int      `json:"budgetMinutes"`
+	TodayMinutes    int      `json:"todayMinutes"`
+	EveningMinutes  int      `json:"eveningMinutes"`
+	TomorrowMinutes int      `json:"tomorrowMinutes"`
+	Entries         []Entry  `json:"entries"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+// Entry is a candidate to-do.
+type Entry struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Area     string   `json:"area,omitempty"`
+	Project  string   `json:"project,omitempty"`
+	When     string   `json:"when,omitempty"`
+	Deadline string   `json:"deadline,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Minutes  int      `json:"minutes"`
+	// Estimate says where Minutes came from: "tag", "notes", "tag estimate",
+	// or "default".
+	Estimate string   `json:"estimate"`
+	Reasons  []string `json:"reasons"`
+	Slot     string   `json:"slot"`
+}
+
+// notesEstimate finds a duration in notes written as "~45m", "~1h30m", or
+// "estimate: 2h".
+var notesEstimate = regexp.MustCompile(`(?i)(?:~|\bestimate:?\s*)(\d+h\d+m|\d+(?:\.\d+)?h|\d+m)(?:in)?\b`)
+
+// Build plans the open to-dos in snap.
+func Build(snap *things.Snapshot, opts Options) Plan {
+	budget := cmp.Or(opts.Budget, DefaultBudget)
+	deadlineDays := cmp.Or(opts.DeadlineDays, DefaultDeadlineDays)
+	fallback := cmp.Or(opts.DefaultDuration, DefaultDuration)
+	today := time.Date(opts.Now.Year(), opts.Now.Month(), opts.Now.Day(), 0, 0, 0, 0, opts.Now.Location())
+	todayStr := today.Format(time.DateOnly)
+	horizon := today.AddDate(0, 0, deadlineDays).Format(time.DateOnly)
+
+	type candidate struct {
+		Entry
+		tagRank int
+		inToday bool
+		created time.Time
+	}
+	var candidates []candidate
+	for _, e := range snap.Entries() {
+		if e.Type != things.ItemToDo || e.Status != things.StatusOpen {
+			continue
+		}
+		c := candidate{tagRank: len(opts.Tags), created: e.CreationDate}
+		c.inToday = isDate(e.When) && e.When <= todayStr
+		due := e.Deadline != "" && e.Deadline <= horizon
+		flagged := ""
+		if deferred := e.When == "someday" || isDate(e.When) && e.When > todayStr; !deferred {
+			for i, tag := range opts.Tags {
+				if i < c.tagRank && slices.Contains(e.Tags, tag) {
+					c.tagRank, flagged = i, tag
+				}
+			}
+		}
+		if !c.inToday && !due && flagged == "" {
+			continue
+		}
+
+		c.Entry = Entry{ID: e.ID, Title: e.Title, Area: e.Area, Project: e.Project, When: e.When, Deadline: e.Deadline, Tags: e.Tags, Reasons: []string{}}
+		switch {
+		case due && e.Deadline < todayStr:
+			c.Reasons = append(c.Reasons, "overdue since "+e.Deadline)
+		case due:
+			c.Reasons = append(c.Reasons, "due "+e.Deadline)
+		}
+		if flagged != "" {
+			c.Reasons = append(c.Reasons, "tagged "+flagged)
+		}
+		if c.inToday {
+			c.Reasons = append(c.Reasons, "in Today")
+		}
+		d, source := estimate(e.Task, opts.Durations, fallback)
+		c.Minutes, c.Estimate = int(math.Ceil(d.Minutes())), source
+		candidates = append(candidates, c)
+	}
+
+	// Deadlines come first, then flagged tags in the order given, then what
+	// is already in Today, then the oldest.
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(rankDeadline(a.Deadline, horizon), rankDeadline(b.Deadline, horizon)),
+			cmp.Compare(a.tagRank, b.tagRank),
+			compareBool(a.inToday, b.inToday),
+			a.created.Compare(b.created),
+		)
+	})
+
+	p := Plan{Date: todayStr, BudgetMinutes: int(budget.Minutes()), Entries: []Entry{}}
+	todayLeft, eveningLeft := int(budget.Minutes()), int(opts.Evening.Minutes())
+	for _, c := range candidates {
+		switch {
+		case c.Minutes <= todayLeft:
+			c.Slot = SlotToday
+			todayLeft -= c.Minutes
+			p.TodayMinutes += c.Minutes
+		case c.Minutes <= eveningLeft:
+			c.Slot = SlotEvening
+			eveningLeft -= c.Minutes
+			p.EveningMinutes += c.Minutes
+		default:
+			c.Slot = SlotTomorrow
+			p.TomorrowMinutes += c.Minutes
+			if c.Deadline != "" && c.Deadline <= todayStr {
+				p.Warnings = append(p.Warnings, fmt.Sprintf("%q is due by today but does not fit the budget", c.Title))
+			}
+		}
+		p.Entries = append(p.Entries, c.Entry)
+	}
+	return p
+}
+
+// Items returns the json command updates that move each entry to its slot.
+func (p Plan) Items() ([]things.JSONItem, error) {
+	var items []things.JSONItem
+	for _, e := range p.Entries {
+		when := e.Slot
+		item, err := things.UpdateItem(things.UpdateInput{ID: e.ID, When: &when})
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", e.Title, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ParseDuration reads an estimate such as "30m", "1h30m", "1.5h", or
+// "45min".
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	s = strings.TrimSuffix(s, "in")
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%q is not a duration such as 30m or 1h30m", value)
+	}
+	return d, nil
+}
+
+// estimate returns how long t takes and where that came from.
+func estimate(t things.Task, byTag map[string]time.Duration, fallback time.Duration) (time.Duration, string) {
+	for _, tag := range t.Tags {
+		if d, err := ParseDuration(tag); err == nil {
+			return d, "tag"
+		}
+	}
+	if m := notesEstimate.FindStringSubmatch(t.Notes); m != nil {
+		if d, err := ParseDuration(m[1]); err == nil {
+			return d, "notes"
+		}
+	}
+	for _, tag := range t.Tags {
+		if d, ok := byTag[tag]; ok && d > 0 {
+			return d, "tag estimate"
+		}
+	}
+	return fallback, "default"
+}
+
+// rankDeadline orders deadlines within the horizon by date, ahead of those
+// beyond it and to-dos without one.
+func rankDeadline(deadline, horizon string) string {
+	if deadline == "" || deadline > horizon {
+		return "~"
+	}
+	return deadline
+}
+
+// compareBool orders true before false.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	}
+	return 1
+}
+
+func isDate(when string) bool {
+	_, err := time.Parse(time.DateOnly, when)
+	return err == nil
+}
@@ -0,0 +1,132 @@
+package dayplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+var now = time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC)
+
+func todo(id, when, deadline string, created int, tags ...string) things.ToDo {
+	return things.ToDo{Task: things.Task{ID: id, Title: id, Status: things.StatusOpen, When: when, Deadline: deadline, Tags: tags, CreationDate: now.AddDate(0, 0, -created)}}
+}
+
+func planFixture() *things.Snapshot {
+	noted := todo("noted", "2025-04-01", "", 5)
+	noted.Notes = "Call the bank.\nEstimate: 1h30m"
+	closed := todo("closed", "2025-04-01", "2025-04-01", 1)
+	closed.Status = things.StatusCompleted
+	return &things.Snapshot{
+		Areas: []things.Area{{
+			Title: "Work",
+			ToDos: []things.ToDo{
+				todo("report", "anytime", "2025-04-02", 10, "2h"),
+				todo("late", "anytime", "2025-03-30", 3),
+				todo("review", "anytime", "", 20, "Urgent", "Errand"),
+				closed,
+			},
+		}},
+		ToDos: []things.ToDo{
+			noted,
+			todo("standup", "2025-03-31", "", 30, "15m"),
+			todo("later", "anytime", "2025-04-20", 1),
+			todo("parked", "someday", "", 1, "Urgent"),
+			todo("tomorrow", "2025-04-02", "", 1, "Urgent"),
+			todo("quick", "anytime", "", 2, "Quick"),
+		},
+	}
+}
+
+func slots(p Plan) string {
+	var parts []string
+	for _, e := range p.Entries {
+		parts = append(parts, fmt.Sprintf("%s:%s:%d", e.ID, e.Slot, e.Minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestBuild(t *testing.T) {
+	p := Build(planFixture(), Options{
+		Now:       now,
+		Budget:    3 * time.Hour,
+		Evening:   time.Hour,
+		Tags:      []string{"Urgent", "Quick"},
+		Durations: map[string]time.Duration{"Errand": 45 * time.Minute},
+	})
+
+	want := "late:today:30 report:today:120 review:evening:45 quick:today:30 standup:evening:15 noted:tomorrow:90"
+	if got := slots(p); got != want {
+		t.Errorf("plan = %s\nwant   %s", got, want)
+	}
+	if p.Date != "2025-04-01" || p.BudgetMinutes != 180 || p.TodayMinutes != 180 || p.EveningMinutes != 60 || p.TomorrowMinutes != 90 {
+		t.Errorf("minutes = %+v", p)
+	}
+	if len(p.Warnings) != 0 {
+		t.Errorf("warnings = %v", p.Warnings)
+	}
+
+	for id, want := range map[string]string{
+		"late":   "default overdue since 2025-03-30",
+		"report": "tag due 2025-04-02",
+		"review": "tag estimate tagged Urgent",
+		"noted":  "notes in Today",
+	} {
+		for _, e := range p.Entries {
+			if e.ID == id {
+				if got := e.Estimate + " " + strings.Join(e.Reasons, ", "); got != want {
+					t.Errorf("%s: %q, want %q", id, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestBuildWarnsAboutDeadlinesThatDoNotFit(t *testing.T) {
+	p := Build(planFixture(), Options{Now: now, Budget: 15 * time.Minute})
+
+	if got := slots(p); !strings.HasPrefix(got, "late:tomorrow:30 report:tomorrow:120 standup:today:15") {
+		t.Errorf("plan = %s", got)
+	}
+	if len(p.Warnings) != 1 || !strings.Contains(p.Warnings[0], `"late"`) {
+		t.Errorf("warnings = %v", p.Warnings)
+	}
+}
+
+func TestPlanItems(t *testing.T) {
+	p := Plan{Entries: []Entry{{ID: "a", Slot: SlotToday}, {ID: "b", Slot: SlotEvening}, {ID: "c", Slot: SlotTomorrow}}}
+	items, err := p.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(items)
+	want := `[{"type":"to-do","operation":"update","id":"a","attributes":{"when":"today"}},` +
+		`{"type":"to-do","operation":"update","id":"b","attributes":{"when":"evening"}},` +
+		`{"type":"to-do","operation":"update","id":"c","attributes":{"when":"tomorrow"}}]`
+	if string(data) != want {
+		t.Errorf("items = %s", data)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"30m":   30 * time.Minute,
+		"1h30m": 90 * time.Minute,
+		"1.5h":  90 * time.Minute,
+		"45min": 45 * time.Minute,
+		" 2H ":  2 * time.Hour,
+	} {
+		if got, err := ParseDuration(value); err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "Errand", "0m", "-5m", "Admin"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("ParseDuration(%q) succeeded", value)
+		}
+	}
+}
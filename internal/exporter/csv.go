@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+var csvHeader = []string{
+	"type", "id", "title", "status", "area", "project", "heading", "when", "deadline",
+	"tags", "notes", "checklist", "created", "completed",
+}
+
+// CSV writes one row per project and to-do. Tags are comma-separated,
+// checklist items are one per line with completed ones prefixed by "[x] ",
+// and times are RFC 3339.
+func CSV(w io.Writer, snap *things.Snapshot) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range snap.Entries() {
+		kind := "to-do"
+		if e.Type == things.ItemProject {
+			kind = "project"
+		}
+		var checklist []string
+		for _, c := range e.Checklist {
+			if c.Completed {
+				checklist = append(checklist, "[x] "+c.Title)
+			} else {
+				checklist = append(checklist, c.Title)
+			}
+		}
+		completed := ""
+		if e.CompletionDate != nil {
+			completed = csvTime(*e.CompletionDate)
+		}
+		err := cw.Write([]string{
+			kind, e.ID, e.Title, e.Status, e.Area, e.Project, e.Heading, e.When, e.Deadline,
+			strings.Join(e.Tags, ", "), e.Notes, strings.Join(checklist, "\n"),
+			csvTime(e.CreationDate), completed,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
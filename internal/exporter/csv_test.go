@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestCSVRows(t *testing.T) {
+	var buf bytes.Buffer
+	if err := CSV(&buf, sampleSnapshot()); err != nil {
+		t.Fatalf("CSV returned error: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not CSV: %v", err)
+	}
+	if len(rows) != 6 || len(rows[0]) != len(csvHeader) {
+		t.Fatalf("got %d rows of %d columns", len(rows), len(rows[0]))
+	}
+
+	col := map[string]int{}
+	for i, name := range rows[0] {
+		col[name] = i
+	}
+	login := rows[4]
+	checks := map[string]string{
+		"type":      "to-do",
+		"title":     "Test login, twice",
+		"area":      "Work",
+		"project":   "Launch v2",
+		"heading":   "QA",
+		"when":      "2025-02-20",
+		"deadline":  "2025-02-25",
+		"tags":      "high priority",
+		"checklist": "iOS\n[x] Android",
+		"created":   "2025-01-02T09:00:00Z",
+	}
+	for name, want := range checks {
+		if got := login[col[name]]; got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if plan := rows[3]; plan[col["status"]] != "completed" || plan[col["completed"]] != "2025-01-05T14:30:00Z" {
+		t.Errorf("completed row = %v", plan)
+	}
+}
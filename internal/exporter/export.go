@@ -0,0 +1,62 @@
+// Package exporter renders a snapshot of the Things library as Markdown,
+// json command items, CSV, iCalendar, or TaskPaper.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// Export formats.
+const (
+	FormatMarkdown  = "markdown"
+	FormatJSON      = "json"
+	FormatCSV       = "csv"
+	FormatICS       = "ics"
+	FormatTaskPaper = "taskpaper"
+)
+
+// Formats lists the export formats in the order they are documented.
+var Formats = []string{FormatMarkdown, FormatJSON, FormatCSV, FormatICS, FormatTaskPaper}
+
+// ParseFormat accepts a format name or its usual file extension.
+func ParseFormat(name string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case FormatMarkdown, "md":
+		return FormatMarkdown, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatICS, "ical", "icalendar":
+		return FormatICS, nil
+	case FormatTaskPaper, "tp":
+		return FormatTaskPaper, nil
+	}
+	return "", fmt.Errorf("unknown format %q; use %s", name, strings.Join(Formats, ", "))
+}
+
+// Write renders snap in format to w.
+func Write(w io.Writer, format string, snap *things.Snapshot) error {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(w, snap)
+	case FormatJSON:
+		return JSON(w, snap)
+	case FormatCSV:
+		return CSV(w, snap)
+	case FormatICS:
+		return ICS(w, snap)
+	case FormatTaskPaper:
+		return TaskPaper(w, snap)
+	}
+	return fmt.Errorf("unknown format %q; use %s", format, strings.Join(Formats, ", "))
+}
+
+// tagWord makes a tag usable as an inline #tag or @tag.
+func tagWord(tag string) string {
+	return strings.ReplaceAll(tag, " ", "_")
+}
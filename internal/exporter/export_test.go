@@ -0,0 +1,55 @@
+package exporter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+func sampleSnapshot() *things.Snapshot {
+	created := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+	done := time.Date(2025, 1, 5, 14, 30, 0, 0, time.UTC)
+	return &things.Snapshot{
+		Areas: []things.Area{{
+			ID:    "area-work",
+			Title: "Work",
+			ToDos: []things.ToDo{{Task: things.Task{ID: "todo-expenses", Title: "File expenses", Status: things.StatusOpen, When: "anytime", CreationDate: created}}},
+			Projects: []things.Project{{
+				Task: things.Task{ID: "proj-launch", Title: "Launch v2", Notes: "Everything needed to ship.", Status: things.StatusOpen, Deadline: "2025-03-01", Tags: []string{"release"}, CreationDate: created},
+				ToDos: []things.ToDo{{
+					Task: things.Task{ID: "todo-plan", Title: "Write test plan", Status: things.StatusCompleted, CreationDate: created, CompletionDate: &done},
+				}},
+				Headings: []things.Heading{{ID: "head-qa", Title: "QA", ToDos: []things.ToDo{{
+					Task:      things.Task{ID: "todo-login", Title: "Test login, twice", Notes: "Use staging", Status: things.StatusOpen, When: "2025-02-20", Deadline: "2025-02-25", Tags: []string{"high priority"}, CreationDate: created},
+					Checklist: []things.ChecklistItem{{Title: "iOS"}, {Title: "Android", Completed: true}},
+				}}}},
+			}},
+		}},
+		ToDos: []things.ToDo{{Task: things.Task{ID: "todo-inbox", Title: "Call mom", Status: things.StatusOpen, CreationDate: created}}},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]string{"md": FormatMarkdown, "JSON": FormatJSON, ".csv": FormatCSV, "ical": FormatICS, ".taskpaper": FormatTaskPaper} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat accepted xml")
+	}
+}
+
+func TestWriteEveryFormat(t *testing.T) {
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, sampleSnapshot()); err != nil || buf.Len() == 0 {
+			t.Errorf("Write(%s) wrote %d bytes, err %v", format, buf.Len(), err)
+		}
+	}
+	if err := Write(&bytes.Buffer{}, "xml", sampleSnapshot()); err == nil {
+		t.Error("Write accepted xml")
+	}
+}
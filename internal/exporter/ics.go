@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// ICS writes every project and to-do as an RFC 5545 VTODO. Deadlines become
+// DUE dates and scheduled dates DTSTART, so calendar apps show them on the
+// right day; the ICS importer reads the result back.
+func ICS(w io.Writer, snap *things.Snapshot) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) { writeICSLine(bw, s) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//things-mcp//export//EN")
+	for _, e := range snap.Entries() {
+		line("BEGIN:VTODO")
+		line("UID:" + e.ID + "@things-mcp")
+		stamp := e.ModificationDate
+		if stamp.IsZero() {
+			stamp = e.CreationDate
+		}
+		if stamp.IsZero() {
+			stamp = time.Now()
+		}
+		line("DTSTAMP:" + icsTime(stamp))
+		if !e.CreationDate.IsZero() {
+			line("CREATED:" + icsTime(e.CreationDate))
+		}
+		line("SUMMARY:" + escapeICSText(e.Title))
+		if e.Notes != "" {
+			line("DESCRIPTION:" + escapeICSText(e.Notes))
+		}
+		if d, ok := icsDate(e.When); ok {
+			line("DTSTART;VALUE=DATE:" + d)
+		}
+		if d, ok := icsDate(e.Deadline); ok {
+			line("DUE;VALUE=DATE:" + d)
+		}
+		if len(e.Tags) > 0 {
+			tags := make([]string, len(e.Tags))
+			for i, tag := range e.Tags {
+				tags[i] = escapeICSText(tag)
+			}
+			line("CATEGORIES:" + strings.Join(tags, ","))
+		}
+		if container := firstNonEmpty(e.Project, e.Area); container != "" {
+			line("X-THINGS-LIST:" + escapeICSText(container))
+		}
+		switch e.Status {
+		case things.StatusCompleted:
+			line("STATUS:COMPLETED")
+		case things.StatusCanceled:
+			line("STATUS:CANCELLED")
+		default:
+			line("STATUS:NEEDS-ACTION")
+		}
+		if e.CompletionDate != nil && e.Status == things.StatusCompleted {
+			line("COMPLETED:" + icsTime(*e.CompletionDate))
+		}
+		line("END:VTODO")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// writeICSLine folds content lines longer than 75 octets without splitting
+// a UTF-8 sequence.
+func writeICSLine(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	w.WriteString(s + "\r\n")
+}
+
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func icsDate(date string) (string, bool) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", false
+	}
+	return t.Format("20060102"), true
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
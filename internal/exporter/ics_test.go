@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/moonbase/things-mcp/internal/importer"
+)
+
+func TestICSRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ICS(&buf, sampleSnapshot()); err != nil {
+		t.Fatalf("ICS returned error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:todo-login@things-mcp\r\n",
+		"SUMMARY:Test login\\, twice\r\n",
+		"DUE;VALUE=DATE:20250225\r\n",
+		"DTSTART;VALUE=DATE:20250220\r\n",
+		"STATUS:COMPLETED\r\nCOMPLETED:20250105T143000Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	items, err := importer.ICS(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("importer.ICS returned error: %v", err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("re-imported %d items, want 5", len(items))
+	}
+	login := items[3].Attributes
+	if login.Title != "Test login, twice" || login.Deadline != "2025-02-25" || login.When != "2025-02-20" || strings.Join(login.Tags, ",") != "high priority" {
+		t.Errorf("re-imported to-do = %+v", login)
+	}
+}
+
+func TestICSFoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	snap := sampleSnapshot()
+	snap.ToDos[0].Notes = strings.Repeat("ü", 100)
+	if err := ICS(&buf, snap); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+	items, err := importer.ICS(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := items[len(items)-1].Attributes.Notes; got != strings.Repeat("ü", 100) {
+		t.Errorf("unfolded notes = %q", got)
+	}
+}
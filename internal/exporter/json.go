@@ -0,0 +1,102 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// Items converts snap into json command items that recreate it: projects
+// with their headings, to-dos, and checklists, and loose to-dos. Areas cannot
+// be created through the json command, so items name their area by title and
+// it must exist where they are imported.
+func Items(snap *things.Snapshot) []things.JSONItem {
+	var items []things.JSONItem
+	for _, area := range snap.Areas {
+		for _, todo := range area.ToDos {
+			item := toDoItem(todo)
+			item.Attributes.List = area.Title
+			items = append(items, item)
+		}
+		for _, p := range area.Projects {
+			item := projectItem(p)
+			item.Attributes.Area = area.Title
+			items = append(items, item)
+		}
+	}
+	for _, p := range snap.Projects {
+		items = append(items, projectItem(p))
+	}
+	for _, todo := range snap.ToDos {
+		items = append(items, toDoItem(todo))
+	}
+	return items
+}
+
+// JSON writes the items of snap as an indented json command payload, which
+// things-json and Client.JSON accept as is.
+func JSON(w io.Writer, snap *things.Snapshot) error {
+	items := Items(snap)
+	if items == nil {
+		items = []things.JSONItem{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+func projectItem(p things.Project) things.JSONItem {
+	item := things.JSONItem{Type: things.JSONProject, Attributes: taskAttributes(p.Task)}
+	for _, todo := range p.ToDos {
+		item.Attributes.Items = append(item.Attributes.Items, toDoItem(todo))
+	}
+	for _, h := range p.Headings {
+		item.Attributes.Items = append(item.Attributes.Items, things.JSONItem{
+			Type:       things.JSONHeading,
+			Attributes: things.JSONAttributes{Title: h.Title},
+		})
+		for _, todo := range h.ToDos {
+			item.Attributes.Items = append(item.Attributes.Items, toDoItem(todo))
+		}
+	}
+	return item
+}
+
+func toDoItem(todo things.ToDo) things.JSONItem {
+	item := things.JSONItem{Type: things.JSONToDo, Attributes: taskAttributes(todo.Task)}
+	for _, c := range todo.Checklist {
+		attrs := things.JSONAttributes{Title: c.Title}
+		if c.Completed {
+			attrs.Completed = &c.Completed
+		}
+		item.Attributes.ChecklistItems = append(item.Attributes.ChecklistItems, things.JSONItem{Type: things.JSONChecklistItem, Attributes: attrs})
+	}
+	return item
+}
+
+func taskAttributes(t things.Task) things.JSONAttributes {
+	attrs := things.JSONAttributes{
+		Title:    t.Title,
+		Notes:    t.Notes,
+		When:     t.When,
+		Deadline: t.Deadline,
+		Tags:     t.Tags,
+	}
+	yes := true
+	switch t.Status {
+	case things.StatusCompleted:
+		attrs.Completed = &yes
+	case things.StatusCanceled:
+		attrs.Canceled = &yes
+	}
+	if !t.CreationDate.IsZero() {
+		attrs.CreationDate = t.CreationDate.Format(time.RFC3339)
+	}
+	if t.CompletionDate != nil {
+		attrs.CompletionDate = t.CompletionDate.Format(time.RFC3339)
+	}
+	return attrs
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+func TestItemsRecreateSnapshot(t *testing.T) {
+	items := Items(sampleSnapshot())
+	if len(items) != 3 {
+		t.Fatalf("got %d top-level items, want 3", len(items))
+	}
+
+	expenses := items[0].Attributes
+	if items[0].Type != things.JSONToDo || expenses.List != "Work" || expenses.When != "anytime" {
+		t.Errorf("area to-do = %+v", items[0])
+	}
+	launch := items[1]
+	if launch.Type != things.JSONProject || launch.Attributes.Area != "Work" || launch.Attributes.CreationDate != "2025-01-02T09:00:00Z" {
+		t.Errorf("project = %+v", launch.Attributes)
+	}
+	var kinds []string
+	for _, child := range launch.Attributes.Items {
+		kinds = append(kinds, child.Type+":"+child.Attributes.Title)
+	}
+	if got := strings.Join(kinds, "|"); got != "to-do:Write test plan|heading:QA|to-do:Test login, twice" {
+		t.Errorf("project items = %s", got)
+	}
+	plan := launch.Attributes.Items[0].Attributes
+	if plan.Completed == nil || !*plan.Completed || plan.CompletionDate != "2025-01-05T14:30:00Z" {
+		t.Errorf("completed to-do = %+v", plan)
+	}
+	login := launch.Attributes.Items[2].Attributes
+	if len(login.ChecklistItems) != 2 || login.ChecklistItems[1].Attributes.Completed == nil {
+		t.Errorf("checklist = %+v", login.ChecklistItems)
+	}
+}
+
+func TestJSONIsACommandPayload(t *testing.T) {
+	var buf bytes.Buffer
+	if err := JSON(&buf, sampleSnapshot()); err != nil {
+		t.Fatalf("JSON returned error: %v", err)
+	}
+	var items []things.JSONItem
+	if err := json.Unmarshal(buf.Bytes(), &items); err != nil {
+		t.Fatalf("output is not a json command payload: %v", err)
+	}
+	if len(items) != 3 || items[2].Attributes.Title != "Call mom" {
+		t.Errorf("items = %+v", items)
+	}
+
+	buf.Reset()
+	if err := JSON(&buf, &things.Snapshot{}); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty snapshot = %q, %v", buf.String(), err)
+	}
+}
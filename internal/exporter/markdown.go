@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// noArea titles the section for projects and to-dos outside any area.
+const noArea = "No Area"
+
+// Markdown writes snap as an outline: a level one heading per area, level
+// two per project, and level three per project heading, with to-dos as task
+// list items and checklists nested under them. Tags and deadlines use the
+// #tag and @due(date) annotations the Markdown importer reads.
+func Markdown(w io.Writer, snap *things.Snapshot) error {
+	bw := bufio.NewWriter(w)
+	first := true
+	section := func(title string) {
+		if !first {
+			bw.WriteString("\n")
+		}
+		first = false
+		bw.WriteString("# " + title + "\n")
+	}
+
+	for _, area := range snap.Areas {
+		if len(area.ToDos) == 0 && len(area.Projects) == 0 {
+			continue
+		}
+		section(area.Title + mdAnnotations(area.Tags, ""))
+		writeMarkdownToDos(bw, area.ToDos)
+		writeMarkdownProjects(bw, area.Projects)
+	}
+	if len(snap.Projects) > 0 || len(snap.ToDos) > 0 {
+		section(noArea)
+		writeMarkdownToDos(bw, snap.ToDos)
+		writeMarkdownProjects(bw, snap.Projects)
+	}
+	return bw.Flush()
+}
+
+func writeMarkdownProjects(w *bufio.Writer, projects []things.Project) {
+	for _, p := range projects {
+		title := p.Title
+		if p.Status != things.StatusOpen {
+			title = "~~" + title + "~~"
+		}
+		w.WriteString("\n## " + title + mdAnnotations(p.Tags, p.Deadline) + "\n")
+		if p.Notes != "" {
+			w.WriteString(p.Notes + "\n")
+		}
+		if len(p.ToDos) > 0 {
+			w.WriteString("\n")
+			writeMarkdownToDos(w, p.ToDos)
+		}
+		for _, h := range p.Headings {
+			w.WriteString("\n### " + h.Title + "\n")
+			if len(h.ToDos) > 0 {
+				w.WriteString("\n")
+				writeMarkdownToDos(w, h.ToDos)
+			}
+		}
+	}
+}
+
+func writeMarkdownToDos(w *bufio.Writer, todos []things.ToDo) {
+	for _, todo := range todos {
+		w.WriteString("- " + mdCheckbox(todo.Status != things.StatusOpen) + " " + todo.Title + mdAnnotations(todo.Tags, todo.Deadline) + "\n")
+		for _, line := range strings.Split(todo.Notes, "\n") {
+			if line != "" {
+				w.WriteString("  " + line + "\n")
+			}
+		}
+		for _, item := range todo.Checklist {
+			w.WriteString("  - " + mdCheckbox(item.Completed) + " " + item.Title + "\n")
+		}
+	}
+}
+
+func mdCheckbox(done bool) string {
+	if done {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func mdAnnotations(tags []string, deadline string) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		b.WriteString(" #" + tagWord(tag))
+	}
+	if deadline != "" {
+		b.WriteString(" @due(" + deadline + ")")
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/moonbase/things-mcp/internal/importer"
+)
+
+func TestMarkdownOutline(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, sampleSnapshot()); err != nil {
+		t.Fatalf("Markdown returned error: %v", err)
+	}
+
+	want := `# Work
+- [ ] File expenses
+
+## Launch v2 #release @due(2025-03-01)
+Everything needed to ship.
+
+- [x] Write test plan
+
+### QA
+
+- [ ] Test login, twice #high_priority @due(2025-02-25)
+  Use staging
+  - [ ] iOS
+  - [x] Android
+
+# No Area
+- [ ] Call mom
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Markdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarkdownReimports(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, sampleSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	items, err := importer.Markdown(buf.String())
+	if err != nil {
+		t.Fatalf("importer.Markdown returned error: %v", err)
+	}
+	// Areas come back as projects and projects as headings, but the to-dos
+	// keep their deadlines and checklists.
+	var found bool
+	for _, item := range items {
+		for _, child := range item.Attributes.Items {
+			if child.Attributes.Title == "Test login, twice" {
+				found = true
+				if child.Attributes.Deadline != "2025-02-25" || len(child.Attributes.ChecklistItems) != 2 {
+					t.Errorf("re-imported to-do = %+v", child.Attributes)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Errorf("re-import lost the to-do: %+v", items)
+	}
+}
@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// TaskPaper writes snap as a TaskPaper document that the TaskPaper importer
+// reads back: projects with their headings as nested projects, to-dos as
+// tasks with checklists nested under them, and notes indented below. @due,
+// @defer, and @done(date) carry deadlines, when, and completion. TaskPaper
+// has no areas, so they are left out, and tags are written as @words with
+// spaces replaced by underscores.
+func TaskPaper(w io.Writer, snap *things.Snapshot) error {
+	return taskPaperItems(w, Items(snap))
+}
+
+func taskPaperItems(w io.Writer, items []things.JSONItem) error {
+	bw := bufio.NewWriter(w)
+	for _, item := range items {
+		switch item.Type {
+		case things.JSONProject:
+			writeTaskPaperLine(bw, 0, item.Attributes.Title+":", item.Attributes)
+			writeTaskPaperNotes(bw, 1, item.Attributes.Notes)
+			depth := 1
+			for _, child := range item.Attributes.Items {
+				switch child.Type {
+				case things.JSONHeading:
+					bw.WriteString("\t" + child.Attributes.Title + ":\n")
+					depth = 2
+				case things.JSONToDo:
+					writeTaskPaperToDo(bw, depth, child.Attributes)
+				}
+			}
+		case things.JSONToDo:
+			writeTaskPaperToDo(bw, 0, item.Attributes)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeTaskPaperToDo(w *bufio.Writer, depth int, attrs things.JSONAttributes) {
+	writeTaskPaperLine(w, depth, "- "+attrs.Title, attrs)
+	writeTaskPaperNotes(w, depth+1, attrs.Notes)
+	for _, item := range attrs.ChecklistItems {
+		line := strings.Repeat("\t", depth+1) + "- " + item.Attributes.Title
+		if done := item.Attributes.Completed; done != nil && *done {
+			line += " @done"
+		}
+		w.WriteString(line + "\n")
+	}
+}
+
+func writeTaskPaperLine(w *bufio.Writer, depth int, text string, attrs things.JSONAttributes) {
+	w.WriteString(strings.Repeat("\t", depth) + text)
+	for _, tag := range attrs.Tags {
+		w.WriteString(" @" + tagWord(tag))
+	}
+	if attrs.When != "" {
+		w.WriteString(" @defer(" + strings.Replace(attrs.When, "@", " ", 1) + ")")
+	}
+	if attrs.Deadline != "" {
+		w.WriteString(" @due(" + attrs.Deadline + ")")
+	}
+	if done := attrs.Completed; done != nil && *done {
+		w.WriteString(" @done")
+		if t, err := time.Parse(time.RFC3339, attrs.CompletionDate); err == nil {
+			w.WriteString("(" + t.In(time.Local).Format("2006-01-02 15:04") + ")")
+		}
+	}
+	w.WriteString("\n")
+}
+
+func writeTaskPaperNotes(w *bufio.Writer, depth int, notes string) {
+	if notes == "" {
+		return
+	}
+	for _, line := range strings.Split(notes, "\n") {
+		w.WriteString(strings.Repeat("\t", depth) + line + "\n")
+	}
+}
@@ -0,0 +1,49 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/moonbase/things-mcp/internal/importer"
+)
+
+func TestTaskPaper(t *testing.T) {
+	var buf bytes.Buffer
+	if err := TaskPaper(&buf, sampleSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"- File expenses @defer(anytime)\n",
+		"Launch v2: @release @due(2025-03-01)\n\tEverything needed to ship.\n",
+		"\tQA:\n\t\t- Test login, twice @high_priority @defer(2025-02-20) @due(2025-02-25)\n\t\t\tUse staging\n\t\t\t- iOS\n\t\t\t- Android @done\n",
+		"- Call mom\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("TaskPaper output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestTaskPaperReimports(t *testing.T) {
+	var buf bytes.Buffer
+	if err := TaskPaper(&buf, sampleSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	items, err := importer.TaskPaper(buf.String())
+	if err != nil {
+		t.Fatalf("importer.TaskPaper returned error: %v\n%s", err, buf.String())
+	}
+	var again bytes.Buffer
+	if err := taskPaperItems(&again, items); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != buf.String() {
+		t.Errorf("round trip changed the document:\n%s\nvs\n%s", buf.String(), again.String())
+	}
+	// Tags with spaces come back with underscores.
+	if !strings.Contains(buf.String(), "@high_priority") {
+		t.Errorf("expected the space in the tag to become an underscore:\n%s", buf.String())
+	}
+}
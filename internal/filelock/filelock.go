@@ -0,0 +1,3 @@
+// Package filelock serialises read-modify-write cycles on state files shared
+// by several things-mcp processes.
+package filelock
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package filelock
+
+// Lock does nothing where flock is unavailable; only run one process per
+// state file there.
+func Lock(string) (unlock func(), err error) {
+	return func() {}, nil
+}
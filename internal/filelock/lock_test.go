@@ -0,0 +1,34 @@
+package filelock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockWaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("Lock returned error: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := Lock(path)
+		if err != nil {
+			t.Errorf("second Lock returned error: %v", err)
+		} else {
+			unlock()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Lock did not wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-acquired
+}
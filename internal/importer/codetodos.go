@@ -0,0 +1,337 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// maxScanSize skips generated bundles and other files too large to be
+// hand-written source.
+const maxScanSize = 1 << 20
+
+var codeTODO = regexp.MustCompile(`\b(TODO|FIXME|HACK)\b(?:\([^)]*\))?:?\s*(.*)$`)
+
+// commentMarkers introduce line and block comments in common languages.
+var commentMarkers = []string{"//", "#", "/*", "<!--", "--", ";", "%"}
+
+// languageMarkers are the comment markers of a file extension, or of a file
+// name for files without one. In these files a keyword counts when one of
+// them appears anywhere before it on the line; in other files a marker must
+// come right before the keyword.
+var languageMarkers = map[string][]string{
+	".c": cMarkers, ".h": cMarkers, ".cc": cMarkers, ".cpp": cMarkers, ".hpp": cMarkers, ".m": cMarkers,
+	".go": cMarkers, ".rs": cMarkers, ".swift": cMarkers, ".java": cMarkers, ".kt": cMarkers, ".scala": cMarkers,
+	".cs": cMarkers, ".dart": cMarkers, ".proto": cMarkers, ".scss": cMarkers, ".less": cMarkers,
+	".js": cMarkers, ".jsx": cMarkers, ".mjs": cMarkers, ".ts": cMarkers, ".tsx": cMarkers,
+	".css": {"/*"}, ".php": {"//", "/*", "#"},
+
+	".py": hashMarkers, ".rb": hashMarkers, ".pl": hashMarkers, ".r": hashMarkers,
+	".sh": hashMarkers, ".bash": hashMarkers, ".zsh": hashMarkers, ".fish": hashMarkers,
+	".yaml": hashMarkers, ".yml": hashMarkers, ".toml": hashMarkers, ".tf": hashMarkers, ".nix": hashMarkers,
+	"Makefile": hashMarkers, "Dockerfile": hashMarkers, "Gemfile": hashMarkers, "Rakefile": hashMarkers,
+
+	".sql": {"--"}, ".lua": {"--"}, ".hs": {"--"}, ".elm": {"--"},
+	".el": {";"}, ".lisp": {";"}, ".clj": {";"}, ".scm": {";"}, ".asm": {";"}, ".ini": {";", "#"},
+	".tex": {"%"}, ".sty": {"%"}, ".erl": {"%"},
+	".html": xmlMarkers, ".htm": xmlMarkers, ".xml": xmlMarkers, ".svg": xmlMarkers, ".md": xmlMarkers,
+	".vue": {"<!--", "//", "/*"}, ".svelte": {"<!--", "//", "/*"},
+}
+
+var (
+	cMarkers    = []string{"//", "/*"}
+	hashMarkers = []string{"#"}
+	xmlMarkers  = []string{"<!--"}
+)
+
+// CodeTODO is a TODO, FIXME, or HACK comment found in a source file.
+type CodeTODO struct {
+	Kind    string
+	Text    string
+	File    string // slash-separated, relative to the scanned root
+	Line    int
+	Context []string
+	// Ref identifies the comment across runs. It hashes the file, keyword,
+	// and text rather than the line, so edits elsewhere in the file do not
+	// change it.
+	Ref string
+}
+
+// ScanCodeTODOs walks root, skipping paths excluded by .gitignore files, and
+// returns the TODO, FIXME, and HACK comments it finds with up to context
+// lines of source on either side.
+func ScanCodeTODOs(root string, context int) ([]CodeTODO, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	ignore := newIgnoreMatcher()
+	var todos []CodeTODO
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && (d.Name() == ".git" || ignore.ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			return ignore.load(p, rel)
+		}
+		if !d.Type().IsRegular() || ignore.ignored(rel, false) {
+			return nil
+		}
+		found, err := scanFile(p, rel, context)
+		if err != nil {
+			return err
+		}
+		todos = append(todos, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func scanFile(p, rel string, context int) ([]CodeTODO, error) {
+	info, err := os.Stat(p)
+	if err != nil || info.Size() > maxScanSize {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScanSize)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", rel, err)
+	}
+
+	markers, ok := languageMarkers[strings.ToLower(path.Ext(rel))]
+	if !ok {
+		markers = languageMarkers[path.Base(rel)]
+	}
+
+	var todos []CodeTODO
+	seen := map[string]int{}
+	for i, line := range lines {
+		loc := codeTODO.FindStringSubmatchIndex(line)
+		if loc == nil || !inComment(line[:loc[0]], markers) {
+			continue
+		}
+		kind := line[loc[2]:loc[3]]
+		text := strings.TrimSpace(line[loc[4]:loc[5]])
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(text, "*/"), "-->"))
+
+		key := kind + "\x00" + strings.Join(strings.Fields(text), " ")
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d", rel, key, seen[key])))
+
+		todos = append(todos, CodeTODO{
+			Kind:    kind,
+			Text:    text,
+			File:    rel,
+			Line:    i + 1,
+			Context: lines[max(0, i-context):min(len(lines), i+context+1)],
+			Ref:     "code-todo:" + hex.EncodeToString(sum[:6]),
+		})
+	}
+	return todos, nil
+}
+
+// inComment reports whether a keyword after prefix is in a comment, given
+// the markers of the file's language or nil when it is not known.
+func inComment(prefix string, markers []string) bool {
+	trimmed := strings.TrimSpace(prefix)
+	if markers == nil {
+		// Inside a block comment, or right after any marker.
+		if trimmed == "*" {
+			return true
+		}
+		for _, marker := range commentMarkers {
+			if strings.HasSuffix(trimmed, marker) {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.HasPrefix(trimmed, "*") && slices.Contains(markers, "/*") {
+		return true
+	}
+	for _, marker := range markers {
+		if strings.Contains(prefix, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Title is the to-do title for the comment.
+func (t CodeTODO) Title() string {
+	if t.Text == "" {
+		return fmt.Sprintf("%s in %s:%d", t.Kind, t.File, t.Line)
+	}
+	return t.Kind + ": " + t.Text
+}
+
+// Notes reference the comment's location, its stable ref, and the
+// surrounding source.
+func (t CodeTODO) Notes() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%d\nRef: %s\n", t.File, t.Line, t.Ref)
+	if len(t.Context) > 0 {
+		b.WriteString("\n```\n")
+		for _, line := range t.Context {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("```")
+	}
+	return b.String()
+}
+
+// TODOState records which comments were already filed for a repository so
+// re-runs only add new ones.
+type TODOState struct {
+	path string
+
+	Version   int                      `json:"version"`
+	Root      string                   `json:"root"`
+	ProjectID string                   `json:"projectId,omitempty"`
+	Refs      map[string]TODOStateItem `json:"refs"`
+}
+
+// TODOStateItem is a comment that was filed.
+type TODOStateItem struct {
+	File       string    `json:"file"`
+	Line       int       `json:"line"`
+	Title      string    `json:"title"`
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+// LoadTODOState reads the state file at path, or starts an empty state for
+// root when the file does not exist yet.
+func LoadTODOState(path, root string) (*TODOState, error) {
+	state := &TODOState{path: path, Version: 1, Root: root, Refs: map[string]TODOStateItem{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parse state %s: %w", path, err)
+	}
+	if state.Refs == nil {
+		state.Refs = map[string]TODOStateItem{}
+	}
+	return state, nil
+}
+
+// New returns the comments that have not been filed yet.
+func (s *TODOState) New(todos []CodeTODO) []CodeTODO {
+	var fresh []CodeTODO
+	for _, todo := range todos {
+		if _, ok := s.Refs[todo.Ref]; !ok {
+			fresh = append(fresh, todo)
+		}
+	}
+	return fresh
+}
+
+// Record marks todos as filed.
+func (s *TODOState) Record(todos []CodeTODO, now time.Time) {
+	for _, todo := range todos {
+		s.Refs[todo.Ref] = TODOStateItem{File: todo.File, Line: todo.Line, Title: todo.Title(), ImportedAt: now}
+	}
+}
+
+// Save writes the state file, creating its directory if needed.
+func (s *TODOState) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// CodeTODOItems groups todos by directory. Without a projectID they become a
+// new project titled title with one heading per directory. With one, they
+// become to-dos in that existing project, filed under the heading of the same
+// name when it exists.
+func CodeTODOItems(todos []CodeTODO, title, projectID string) []things.JSONItem {
+	byDir := map[string][]CodeTODO{}
+	for _, todo := range todos {
+		dir := path.Dir(todo.File)
+		byDir[dir] = append(byDir[dir], todo)
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var children []things.JSONItem
+	for _, dir := range dirs {
+		heading := dir
+		if dir == "." {
+			heading = "(root)"
+		}
+		if projectID == "" {
+			children = append(children, things.JSONItem{Type: things.JSONHeading, Attributes: things.JSONAttributes{Title: heading}})
+		}
+		for _, todo := range byDir[dir] {
+			attrs := things.JSONAttributes{Title: todo.Title(), Notes: todo.Notes()}
+			if projectID != "" {
+				attrs.ListID, attrs.Heading = projectID, heading
+			}
+			children = append(children, things.JSONItem{Type: things.JSONToDo, Attributes: attrs})
+		}
+	}
+
+	if projectID != "" {
+		return children
+	}
+	return []things.JSONItem{{Type: things.JSONProject, Attributes: things.JSONAttributes{Title: title, Items: children}}}
+}
@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanCodeTODOs(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":         "dist/\n*.gen.go\n",
+		"main.go":            "package main\n\n// TODO: handle signals\nfunc main() {\n\tx := \"TODO not a comment\"\n}\n",
+		"pkg/store/store.go": "package store\n\n/* FIXME(ana): leaks on error */\n",
+		"scripts/run.sh":     "#!/bin/sh\n# HACK\necho hi\n",
+		"dist/bundle.js":     "// TODO: ignored\n",
+		"pkg/a.gen.go":       "// TODO: ignored\n",
+		"image.bin":          "\x00\x01// TODO: binary\n",
+		".git/config":        "# TODO: not scanned\n",
+	})
+
+	todos, err := ScanCodeTODOs(root, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]CodeTODO{}
+	for _, todo := range todos {
+		got[todo.Title()] = todo
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d todos, want 3: %+v", len(todos), todos)
+	}
+
+	todo, ok := got["TODO: handle signals"]
+	if !ok {
+		t.Fatalf("missing main.go TODO: %+v", todos)
+	}
+	if todo.File != "main.go" || todo.Line != 3 {
+		t.Errorf("location = %s:%d, want main.go:3", todo.File, todo.Line)
+	}
+	if want := []string{"", "// TODO: handle signals", "func main() {"}; strings.Join(todo.Context, "\n") != strings.Join(want, "\n") {
+		t.Errorf("context = %q, want %q", todo.Context, want)
+	}
+	if !strings.HasPrefix(todo.Notes(), "main.go:3\nRef: code-todo:") {
+		t.Errorf("notes = %q", todo.Notes())
+	}
+	if _, ok := got["FIXME: leaks on error"]; !ok {
+		t.Errorf("missing block comment FIXME: %+v", todos)
+	}
+	if _, ok := got["HACK in scripts/run.sh:2"]; !ok {
+		t.Errorf("missing empty HACK: %+v", todos)
+	}
+}
+
+func TestInCommentUsesTheFileLanguage(t *testing.T) {
+	for _, tc := range []struct {
+		file, line string
+		want       bool
+	}{
+		{"main.go", `x := "#TODO"`, false},
+		{"main.c", `a--; TODO`, false},
+		{"main.c", `printf("%d TODO")`, false},
+		{"main.c", `a--; // TODO: bounds`, true},
+		{"main.c", ` * TODO: in a block`, true},
+		{"run.py", `x = 1  # TODO: tidy`, true},
+		{"query.sql", `SELECT 1; -- TODO: index`, true},
+		{"notes.txt", `see #TODO`, true},
+		{"notes.txt", `printf("%d TODO")`, false},
+	} {
+		prefix := tc.line[:strings.Index(tc.line, "TODO")]
+		if got := inComment(prefix, languageMarkers[path.Ext(tc.file)]); got != tc.want {
+			t.Errorf("%s: inComment(%q) = %v, want %v", tc.file, tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestCodeTODORefIgnoresLineMoves(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.go": "// TODO: one\n"})
+	before, err := ScanCodeTODOs(root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, root, map[string]string{"a.go": "package a\n\n//   TODO:  one\n// TODO: two\n"})
+	after, err := ScanCodeTODOs(root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 1 || len(after) != 2 {
+		t.Fatalf("got %d and %d todos", len(before), len(after))
+	}
+	if before[0].Ref != after[0].Ref {
+		t.Errorf("ref changed from %s to %s after the comment moved", before[0].Ref, after[0].Ref)
+	}
+	if after[0].Ref == after[1].Ref {
+		t.Errorf("distinct comments share ref %s", after[0].Ref)
+	}
+}
+
+func TestTODOStateSkipsFiledComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "repo.json")
+	state, err := LoadTODOState(path, "/src/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := []CodeTODO{{Kind: "TODO", Text: "one", File: "a.go", Line: 1, Ref: "code-todo:1"}}
+	state.Record(state.New(first), time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	state.ProjectID = "P1"
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = LoadTODOState(path, "/src/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ProjectID != "P1" {
+		t.Errorf("project ID = %q, want P1", state.ProjectID)
+	}
+	second := append(first, CodeTODO{Kind: "FIXME", Text: "two", File: "b/b.go", Line: 4, Ref: "code-todo:2"})
+	fresh := state.New(second)
+	if len(fresh) != 1 || fresh[0].Ref != "code-todo:2" {
+		t.Fatalf("new = %+v, want only code-todo:2", fresh)
+	}
+}
+
+func TestCodeTODOItems(t *testing.T) {
+	todos := []CodeTODO{
+		{Kind: "TODO", Text: "b", File: "pkg/b.go", Line: 1, Ref: "code-todo:b"},
+		{Kind: "TODO", Text: "a", File: "main.go", Line: 2, Ref: "code-todo:a"},
+	}
+
+	items := CodeTODOItems(todos, "TODOs in repo", "")
+	if len(items) != 1 || items[0].Type != things.JSONProject || items[0].Attributes.Title != "TODOs in repo" {
+		t.Fatalf("items = %+v, want one project", items)
+	}
+	var titles []string
+	for _, child := range items[0].Attributes.Items {
+		titles = append(titles, child.Attributes.Title)
+	}
+	if got, want := strings.Join(titles, "|"), "(root)|TODO: a|pkg|TODO: b"; got != want {
+		t.Errorf("children = %s, want %s", got, want)
+	}
+
+	items = CodeTODOItems(todos, "TODOs in repo", "P1")
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2 to-dos", len(items))
+	}
+	for _, item := range items {
+		if item.Type != things.JSONToDo || item.Attributes.ListID != "P1" {
+			t.Errorf("item = %+v, want to-do in P1", item)
+		}
+	}
+	if items[1].Attributes.Heading != "pkg" {
+		t.Errorf("heading = %q, want pkg", items[1].Attributes.Heading)
+	}
+}
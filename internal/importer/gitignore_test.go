@@ -0,0 +1,41 @@
+package importer
+
+import "testing"
+
+func TestIgnoreMatcher(t *testing.T) {
+	m := newIgnoreMatcher()
+	for _, line := range []string{"# comment", "*.log", "!keep.log", "build/", "/vendor", "docs/**/*.tmp"} {
+		if rule, ok := parseIgnoreRule(line); ok {
+			m.rules["."] = append(m.rules["."], rule)
+		}
+	}
+	if rule, ok := parseIgnoreRule("generated.go"); ok {
+		m.rules["pkg"] = append(m.rules["pkg"], rule)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"sub/dir/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"vendor", true, true},
+		{"src/vendor", true, false},
+		{"docs/a/b/x.tmp", false, true},
+		{"docs/x.tmp", false, true},
+		{"pkg/generated.go", false, true},
+		{"pkg/sub/generated.go", false, true},
+		{"generated.go", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
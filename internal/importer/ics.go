@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// icsProperty is one unfolded content line of an iCalendar file.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ICS reads the VTODO components of an RFC 5545 calendar and converts them to
+// to-dos. SUMMARY, DESCRIPTION, DUE, DTSTART, CATEGORIES, STATUS, and
+// COMPLETED map to the title, notes, deadline, when, tags, completed or
+// canceled state, and completion date. Other components are ignored.
+func ICS(r io.Reader) ([]things.JSONItem, error) {
+	props, err := readICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []things.JSONItem
+	var todo *things.JSONAttributes
+	var nested []string
+	for _, prop := range props {
+		switch {
+		case prop.name == "BEGIN" && todo == nil:
+			if strings.EqualFold(prop.value, "VTODO") {
+				todo = &things.JSONAttributes{}
+			}
+		case prop.name == "BEGIN":
+			nested = append(nested, strings.ToUpper(prop.value))
+		case prop.name == "END" && len(nested) > 0:
+			nested = nested[:len(nested)-1]
+		case prop.name == "END" && todo != nil && strings.EqualFold(prop.value, "VTODO"):
+			items = append(items, things.JSONItem{Type: things.JSONToDo, Attributes: *todo})
+			todo = nil
+		case todo != nil && len(nested) == 0:
+			if err := applyICSProperty(todo, prop); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if todo != nil {
+		return nil, fmt.Errorf("VTODO %q is missing END:VTODO", todo.Title)
+	}
+	return items, nil
+}
+
+func applyICSProperty(attrs *things.JSONAttributes, prop icsProperty) error {
+	switch prop.name {
+	case "SUMMARY":
+		attrs.Title = unescapeICSText(prop.value)
+	case "DESCRIPTION":
+		attrs.Notes = unescapeICSText(prop.value)
+	case "DUE":
+		t, _, err := parseICSTime(prop)
+		if err != nil {
+			return err
+		}
+		attrs.Deadline = t.Format("2006-01-02")
+	case "DTSTART":
+		t, hasTime, err := parseICSTime(prop)
+		if err != nil {
+			return err
+		}
+		attrs.When = t.Format("2006-01-02")
+		if hasTime && (t.Hour() != 0 || t.Minute() != 0) {
+			attrs.When += "@" + t.Format("15:04")
+		}
+	case "CATEGORIES":
+		for _, tag := range splitICSList(prop.value) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				attrs.Tags = append(attrs.Tags, tag)
+			}
+		}
+	case "STATUS":
+		yes := true
+		switch strings.ToUpper(prop.value) {
+		case "COMPLETED":
+			attrs.Completed = &yes
+		case "CANCELLED":
+			attrs.Canceled = &yes
+		}
+	case "COMPLETED":
+		t, _, err := parseICSTime(prop)
+		if err != nil {
+			return err
+		}
+		attrs.CompletionDate = t.Format(time.RFC3339)
+		if attrs.Completed == nil && attrs.Canceled == nil {
+			yes := true
+			attrs.Completed = &yes
+		}
+	}
+	return nil
+}
+
+// readICS unfolds continuation lines and splits each line into its name,
+// parameters, and value.
+func readICS(r io.Reader) ([]icsProperty, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read calendar: %w", err)
+	}
+
+	props := make([]icsProperty, 0, len(lines))
+	for i, line := range lines {
+		prop, err := parseICSLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("content line %d: %w", i+1, err)
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+func parseICSLine(line string) (icsProperty, error) {
+	// The value starts at the first colon outside a quoted parameter value.
+	inQuote, colon := false, -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icsProperty{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	parts := splitOutsideQuotes(line[:colon], ';')
+	prop := icsProperty{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	inQuote, start := false, 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitICSList splits a comma-separated TEXT list, honoring escaped commas.
+func splitICSList(value string) []string {
+	var parts []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			parts = append(parts, unescapeICSText(b.String()))
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(parts, unescapeICSText(b.String()))
+}
+
+func unescapeICSText(value string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		if !escaped {
+			if r == '\\' {
+				escaped = true
+			} else {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		escaped = false
+		switch r {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// parseICSTime parses a DATE or DATE-TIME value. UTC times and times with a
+// TZID are converted to local time; floating times are taken as local.
+func parseICSTime(prop icsProperty) (time.Time, bool, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s: invalid date %q", prop.name, value)
+		}
+		return t, false, nil
+	}
+
+	loc := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if strings.HasSuffix(value, "Z") {
+		loc = time.UTC
+		value = strings.TrimSuffix(value, "Z")
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%s: invalid date time %q", prop.name, prop.value)
+	}
+	return t.In(time.Local), true, nil
+}
@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Tasks//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:1@example.com\r\n" +
+	"SUMMARY:Book flights\\, hotel\r\n" +
+	"DESCRIPTION:Window seat\\nNo red-eye\r\n" +
+	"DUE;VALUE=DATE:20250301\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250220T090000\r\n" +
+	"CATEGORIES:Travel,Work\\,Q1\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VTODO\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"SUMMARY:A very long summary that an exporter folded across two content li\r\n" +
+	" nes\r\n" +
+	"STATUS:COMPLETED\r\n" +
+	"COMPLETED:20250105T143000Z\r\n" +
+	"END:VTODO\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"SUMMARY:Dropped\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestICSMapsVTODOs(t *testing.T) {
+	items, err := ICS(strings.NewReader(calendar))
+	if err != nil {
+		t.Fatalf("ICS returned error: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 to-dos, got %d", len(items))
+	}
+
+	first := items[0].Attributes
+	if first.Title != "Book flights, hotel" || first.Notes != "Window seat\nNo red-eye" {
+		t.Fatalf("unexpected text fields: %+v", first)
+	}
+	if first.Deadline != "2025-03-01" || strings.Join(first.Tags, "|") != "Travel|Work,Q1" {
+		t.Fatalf("unexpected deadline or tags: %+v", first)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	start := time.Date(2025, 2, 20, 9, 0, 0, 0, berlin).In(time.Local)
+	if want := start.Format("2006-01-02") + "@" + start.Format("15:04"); first.When != want {
+		t.Fatalf("when = %q, want %q", first.When, want)
+	}
+
+	second := items[1].Attributes
+	if second.Title != "A very long summary that an exporter folded across two content lines" {
+		t.Fatalf("folded line not unfolded: %q", second.Title)
+	}
+	completed := time.Date(2025, 1, 5, 14, 30, 0, 0, time.UTC).In(time.Local).Format(time.RFC3339)
+	if second.Completed == nil || !*second.Completed || second.CompletionDate != completed {
+		t.Fatalf("unexpected completion: %+v", second)
+	}
+
+	if third := items[2].Attributes; third.Canceled == nil || !*third.Canceled || third.Completed != nil {
+		t.Fatalf("unexpected canceled to-do: %+v", third)
+	}
+}
+
+func TestICSRejectsBadDates(t *testing.T) {
+	_, err := ICS(strings.NewReader("BEGIN:VTODO\nSUMMARY:x\nDUE:tomorrow\nEND:VTODO\n"))
+	if err == nil || !strings.Contains(err.Error(), "DUE") {
+		t.Fatalf("expected DUE error, got %v", err)
+	}
+}
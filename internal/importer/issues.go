@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// issue covers the fields shared by `gh issue list --json`, the GitHub REST
+// API, and the GitLab issues API.
+type issue struct {
+	Title       string          `json:"title"`
+	URL         string          `json:"url"`
+	HTMLURL     string          `json:"html_url"`
+	WebURL      string          `json:"web_url"`
+	State       string          `json:"state"`
+	Labels      []issueLabel    `json:"labels"`
+	Milestone   *issueMilestone `json:"milestone"`
+	ClosedAt    string          `json:"closedAt"`
+	ClosedAtAPI string          `json:"closed_at"`
+}
+
+// issueLabel decodes both label objects and plain label names.
+type issueLabel string
+
+func (l *issueLabel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*l = issueLabel(name)
+		return nil
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return errors.New("label must be a name or an object with a name")
+	}
+	*l = issueLabel(obj.Name)
+	return nil
+}
+
+type issueMilestone struct {
+	DueOn    string `json:"dueOn"`
+	DueOnAPI string `json:"due_on"`
+	DueDate  string `json:"due_date"`
+}
+
+// Issues converts a JSON array of GitHub or GitLab issues into to-dos. Each
+// to-do's notes hold the issue URL and a marker, so importing the same issue
+// again updates the to-do instead of creating another. Labels become tags
+// through labelTags: a mapped label uses the mapped tag, a label mapped to ""
+// is dropped, and other labels keep their name. The milestone's due date
+// becomes the deadline, and closed issues are completed.
+func Issues(data []byte, labelTags map[string]string) ([]things.JSONItem, error) {
+	var issues []issue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		var single issue
+		if json.Unmarshal(data, &single) != nil {
+			return nil, fmt.Errorf("issues must be a JSON array of issue objects: %w", err)
+		}
+		issues = []issue{single}
+	}
+
+	items := make([]things.JSONItem, 0, len(issues))
+	for i, is := range issues {
+		link := firstNonEmpty(is.HTMLURL, is.WebURL, is.URL)
+		if is.Title == "" || link == "" {
+			return nil, fmt.Errorf("issue %d: title and url are required", i+1)
+		}
+
+		attrs := things.JSONAttributes{
+			Title: is.Title,
+			Notes: link + "\n\n" + things.Marker("issue:"+link),
+			Tags:  issueTags(is.Labels, labelTags),
+		}
+		if is.Milestone != nil {
+			if due := firstNonEmpty(is.Milestone.DueOn, is.Milestone.DueOnAPI, is.Milestone.DueDate); len(due) >= len("2006-01-02") {
+				attrs.Deadline = due[:len("2006-01-02")]
+			}
+		}
+		if strings.EqualFold(is.State, "closed") {
+			done := true
+			attrs.Completed = &done
+			if t, err := time.Parse(time.RFC3339, firstNonEmpty(is.ClosedAt, is.ClosedAtAPI)); err == nil {
+				attrs.CompletionDate = t.Format(time.RFC3339)
+			}
+		}
+		items = append(items, things.JSONItem{Type: things.JSONToDo, Attributes: attrs})
+	}
+	return items, nil
+}
+
+func issueTags(labels []issueLabel, labelTags map[string]string) []string {
+	var tags []string
+	for _, label := range labels {
+		tag, ok := labelTags[string(label)]
+		if !ok {
+			tag = string(label)
+		}
+		if tag != "" && !containsFold(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+const ghIssues = `[
+  {
+    "number": 12,
+    "title": "Login fails on Safari",
+    "url": "https://github.com/acme/web/issues/12",
+    "state": "OPEN",
+    "labels": [{"name": "bug"}, {"name": "p1"}, {"name": "triage"}],
+    "milestone": {"title": "v2", "dueOn": "2025-03-01T08:00:00Z"}
+  },
+  {
+    "number": 9,
+    "title": "Old crash",
+    "url": "https://github.com/acme/web/issues/9",
+    "state": "CLOSED",
+    "closedAt": "2025-01-05T14:30:00Z",
+    "labels": [],
+    "milestone": null
+  }
+]`
+
+const glIssues = `[{
+  "iid": 4,
+  "title": "Update docs",
+  "web_url": "https://gitlab.com/acme/web/-/issues/4",
+  "state": "opened",
+  "labels": ["docs", "Docs"],
+  "milestone": {"title": "Q1", "due_date": "2025-03-31"}
+}]`
+
+func TestIssuesFromGitHub(t *testing.T) {
+	items, err := Issues([]byte(ghIssues), map[string]string{"p1": "Urgent", "triage": ""})
+	if err != nil {
+		t.Fatalf("Issues returned error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	open := items[0].Attributes
+	if open.Title != "Login fails on Safari" || open.Deadline != "2025-03-01" {
+		t.Errorf("open issue = %+v", open)
+	}
+	if got := strings.Join(open.Tags, ","); got != "bug,Urgent" {
+		t.Errorf("tags = %s, want bug,Urgent", got)
+	}
+	wantNotes := "https://github.com/acme/web/issues/12\n\n" + things.Marker("issue:https://github.com/acme/web/issues/12")
+	if open.Notes != wantNotes {
+		t.Errorf("notes = %q, want %q", open.Notes, wantNotes)
+	}
+	if open.Completed != nil {
+		t.Errorf("open issue marked completed")
+	}
+
+	closed := items[1].Attributes
+	if closed.Completed == nil || !*closed.Completed || closed.CompletionDate != "2025-01-05T14:30:00Z" {
+		t.Errorf("closed issue = %+v", closed)
+	}
+}
+
+func TestIssuesFromGitLab(t *testing.T) {
+	items, err := Issues([]byte(glIssues), nil)
+	if err != nil {
+		t.Fatalf("Issues returned error: %v", err)
+	}
+	attrs := items[0].Attributes
+	if attrs.Deadline != "2025-03-31" || strings.Join(attrs.Tags, ",") != "docs" {
+		t.Errorf("attributes = %+v", attrs)
+	}
+	if !strings.HasPrefix(attrs.Notes, "https://gitlab.com/acme/web/-/issues/4\n") {
+		t.Errorf("notes = %q", attrs.Notes)
+	}
+}
+
+func TestIssuesRejectsInvalidInput(t *testing.T) {
+	for _, src := range []string{`{"foo": 1}`, `[{"title": "No link"}]`, `not json`} {
+		if _, err := Issues([]byte(src), nil); err == nil {
+			t.Errorf("Issues(%s) returned no error", src)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package importer converts task outlines from other formats into Things
+// json command items.
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+var (
+	mdHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdListItem = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	mdFence    = regexp.MustCompile("^\\s*(```|~~~)")
+	mdTag      = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+	mdDue      = regexp.MustCompile(`@due\(([^)]*)\)`)
+)
+
+// Markdown converts a Markdown task outline into json command items. A level
+// one heading starts a project and deeper headings become headings in it.
+// Task list items ("- [ ]") become to-dos, and task list items nested under a
+// to-do become its checklist items; "- [x]" marks either as completed. Inline
+// #tags and @due(date) annotations set tags and the deadline of projects and
+// to-dos. Other text, plain bullets included, is added to the notes of the
+// enclosing to-do or project. To-dos before the first project are returned as
+// top-level items.
+func Markdown(src string) ([]things.JSONItem, error) {
+	p := &mdParser{project: -1, todo: -1}
+	inFence := false
+	for i, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if mdFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if err := p.line(expandTabs(line)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return p.items, nil
+}
+
+type mdParser struct {
+	items []things.JSONItem
+	// project indexes the open project in items, and todo the open to-do in
+	// the project's items or, without a project, in items.
+	project    int
+	todo       int
+	todoIndent int
+}
+
+func (p *mdParser) line(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	if m := mdHeading.FindStringSubmatch(line); m != nil {
+		p.todo = -1
+		if len(m[1]) == 1 {
+			title, attrs := annotations(m[2])
+			attrs.Title = title
+			p.items = append(p.items, things.JSONItem{Type: things.JSONProject, Attributes: attrs})
+			p.project = len(p.items) - 1
+			return nil
+		}
+		if p.project < 0 {
+			return fmt.Errorf("heading %q is not inside a project; start the outline with a level one heading", m[2])
+		}
+		project := &p.items[p.project].Attributes
+		project.Items = append(project.Items, things.JSONItem{
+			Type:       things.JSONHeading,
+			Attributes: things.JSONAttributes{Title: m[2]},
+		})
+		return nil
+	}
+
+	if m := mdListItem.FindStringSubmatch(line); m != nil {
+		indent, done, text := len(m[1]), m[2] == "x" || m[2] == "X", strings.TrimSpace(m[3])
+		if todo := p.openToDo(); todo != nil && indent > p.todoIndent {
+			item := things.JSONItem{Type: things.JSONChecklistItem, Attributes: things.JSONAttributes{Title: text}}
+			if done {
+				item.Attributes.Completed = &done
+			}
+			todo.Attributes.ChecklistItems = append(todo.Attributes.ChecklistItems, item)
+			return nil
+		}
+
+		title, attrs := annotations(text)
+		attrs.Title = title
+		if done {
+			attrs.Completed = &done
+		}
+		p.addToDo(things.JSONItem{Type: things.JSONToDo, Attributes: attrs}, indent)
+		return nil
+	}
+
+	text := strings.TrimSpace(line)
+	if todo := p.openToDo(); todo != nil && leadingSpace(line) > p.todoIndent {
+		todo.Attributes.Notes = appendLine(todo.Attributes.Notes, text)
+		return nil
+	}
+	p.todo = -1
+	if p.project >= 0 {
+		project := &p.items[p.project].Attributes
+		project.Notes = appendLine(project.Notes, text)
+	}
+	return nil
+}
+
+func (p *mdParser) addToDo(item things.JSONItem, indent int) {
+	p.todoIndent = indent
+	if p.project < 0 {
+		p.items = append(p.items, item)
+		p.todo = len(p.items) - 1
+		return
+	}
+	project := &p.items[p.project].Attributes
+	project.Items = append(project.Items, item)
+	p.todo = len(project.Items) - 1
+}
+
+func (p *mdParser) openToDo() *things.JSONItem {
+	if p.todo < 0 {
+		return nil
+	}
+	if p.project < 0 {
+		return &p.items[p.todo]
+	}
+	return &p.items[p.project].Attributes.Items[p.todo]
+}
+
+// annotations strips #tags and @due(date) from text and returns them as
+// attributes alongside the remaining title.
+func annotations(text string) (string, things.JSONAttributes) {
+	var attrs things.JSONAttributes
+	if m := mdDue.FindStringSubmatch(text); m != nil {
+		attrs.Deadline = strings.TrimSpace(m[1])
+		text = mdDue.ReplaceAllString(text, "")
+	}
+	for _, m := range mdTag.FindAllStringSubmatch(text, -1) {
+		attrs.Tags = append(attrs.Tags, m[1])
+	}
+	text = mdTag.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " "), attrs
+}
+
+func appendLine(notes, line string) string {
+	if notes == "" {
+		return line
+	}
+	return notes + "\n" + line
+}
+
+func leadingSpace(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func expandTabs(line string) string {
+	rest := strings.TrimLeft(line, "\t")
+	return strings.Repeat("    ", len(line)-len(rest)) + rest
+}
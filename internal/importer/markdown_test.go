@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+const outline = `# Launch v2 #release @due(2025-03-01)
+Everything needed to ship.
+
+## QA
+- [ ] Test login #urgent @due(2025-01-15)
+  - [ ] iOS
+  - [x] Android
+  Use the staging account.
+- [x] Write test plan
+
+## Rollout
+* [ ] Announce
+
+` + "```" + `
+- [ ] not a task
+` + "```" + `
+`
+
+func TestMarkdownBuildsProjectOutline(t *testing.T) {
+	items, err := Markdown(outline)
+	if err != nil {
+		t.Fatalf("Markdown returned error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected one project, got %d items", len(items))
+	}
+
+	project := items[0]
+	if project.Type != things.JSONProject || project.Attributes.Title != "Launch v2" {
+		t.Fatalf("unexpected project: %+v", project)
+	}
+	if project.Attributes.Deadline != "2025-03-01" || strings.Join(project.Attributes.Tags, ",") != "release" {
+		t.Fatalf("project annotations not parsed: %+v", project.Attributes)
+	}
+	if project.Attributes.Notes != "Everything needed to ship." {
+		t.Fatalf("unexpected project notes: %q", project.Attributes.Notes)
+	}
+
+	var kinds []string
+	for _, child := range project.Attributes.Items {
+		kinds = append(kinds, child.Type+":"+child.Attributes.Title)
+	}
+	want := "heading:QA,to-do:Test login,to-do:Write test plan,heading:Rollout,to-do:Announce"
+	if got := strings.Join(kinds, ","); got != want {
+		t.Fatalf("children = %s, want %s", got, want)
+	}
+
+	login := project.Attributes.Items[1].Attributes
+	if login.Deadline != "2025-01-15" || strings.Join(login.Tags, ",") != "urgent" {
+		t.Fatalf("to-do annotations not parsed: %+v", login)
+	}
+	if login.Notes != "Use the staging account." {
+		t.Fatalf("unexpected to-do notes: %q", login.Notes)
+	}
+	if len(login.ChecklistItems) != 2 || login.ChecklistItems[0].Attributes.Completed != nil || !*login.ChecklistItems[1].Attributes.Completed {
+		t.Fatalf("unexpected checklist: %+v", login.ChecklistItems)
+	}
+	if done := project.Attributes.Items[2].Attributes.Completed; done == nil || !*done {
+		t.Fatal("expected completed to-do")
+	}
+}
+
+func TestMarkdownLooseToDos(t *testing.T) {
+	items, err := Markdown("- [ ] Call mom\n\t- [ ] Ask about dinner\n* [ ] Buy milk #errand\n")
+	if err != nil {
+		t.Fatalf("Markdown returned error: %v", err)
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"type":"to-do","attributes":{"title":"Call mom","checklist-items":[{"type":"checklist-item","attributes":{"title":"Ask about dinner"}}]}},` +
+		`{"type":"to-do","attributes":{"title":"Buy milk","tags":["errand"]}}]`
+	if string(data) != want {
+		t.Fatalf("Markdown produced %s, want %s", data, want)
+	}
+}
+
+func TestMarkdownKeepsPlainBulletsAsNotes(t *testing.T) {
+	items, err := Markdown(`# Move house
+Before the move:
+- book the van early
+* ask about parking
+
+- [ ] Book van
+  - [ ] Check size
+  - bring the licence
++ keys go to the agent
+`)
+	if err != nil {
+		t.Fatalf("Markdown returned error: %v", err)
+	}
+
+	project := items[0].Attributes
+	if want := "Before the move:\n- book the van early\n* ask about parking\n+ keys go to the agent"; project.Notes != want {
+		t.Errorf("project notes = %q, want %q", project.Notes, want)
+	}
+	if len(project.Items) != 1 {
+		t.Fatalf("expected one to-do, got %+v", project.Items)
+	}
+	van := project.Items[0].Attributes
+	if van.Title != "Book van" || len(van.ChecklistItems) != 1 || van.Notes != "- bring the licence" {
+		t.Errorf("unexpected to-do: %+v", van)
+	}
+}
+
+func TestMarkdownRejectsHeadingOutsideProject(t *testing.T) {
+	_, err := Markdown("Intro\n\n## QA\n- [ ] Test\n")
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("expected line-numbered error, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+var (
+	tpTag      = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}_.-]+)(?:\(([^)]*)\))?`)
+	tpDateTime = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})[ T](\d{1,2}:\d{2})$`)
+)
+
+type tpKind int
+
+const (
+	tpProject tpKind = iota
+	tpHeading
+	tpToDo
+)
+
+type tpNode struct {
+	kind     tpKind
+	indent   int
+	item     *things.JSONItem
+	project  *tpNode
+	children []*tpNode
+}
+
+// TaskPaper converts a TaskPaper document into json command items.
+// Top-level projects become Things projects and nested projects become
+// headings. Tasks become to-dos, and tasks nested under a task become its
+// checklist items. Other lines are notes of the item above them. @due sets
+// the deadline, @defer sets when, @done marks the item completed with its
+// date as the completion date, and any other @tag becomes a tag.
+func TaskPaper(src string) ([]things.JSONItem, error) {
+	var roots, stack []*tpNode
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		text := strings.TrimSpace(raw)
+		if text == "" {
+			continue
+		}
+		indent := leadingSpace(expandTabs(raw))
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		var parent *tpNode
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		switch {
+		case strings.HasPrefix(text, "- ") || text == "-":
+			title, attrs, err := tpAttributes(strings.TrimPrefix(text, "-"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			attrs.Title = title
+			if parent != nil && parent.kind == tpToDo {
+				parent.item.Attributes.ChecklistItems = append(parent.item.Attributes.ChecklistItems, things.JSONItem{
+					Type:       things.JSONChecklistItem,
+					Attributes: things.JSONAttributes{Title: title, Completed: attrs.Completed},
+				})
+				continue
+			}
+			node := &tpNode{kind: tpToDo, indent: indent, item: &things.JSONItem{Type: things.JSONToDo, Attributes: attrs}}
+			if parent == nil {
+				roots = append(roots, node)
+			} else {
+				parent.project.children = append(parent.project.children, node)
+			}
+			stack = append(stack, node)
+
+		case isTaskPaperProject(text) && (parent == nil || parent.kind != tpToDo):
+			title, attrs, err := tpAttributes(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			attrs.Title = strings.TrimSuffix(title, ":")
+			if parent == nil {
+				node := &tpNode{kind: tpProject, indent: indent, item: &things.JSONItem{Type: things.JSONProject, Attributes: attrs}}
+				node.project = node
+				roots = append(roots, node)
+				stack = append(stack, node)
+				continue
+			}
+			node := &tpNode{
+				kind:    tpHeading,
+				indent:  indent,
+				item:    &things.JSONItem{Type: things.JSONHeading, Attributes: things.JSONAttributes{Title: attrs.Title}},
+				project: parent.project,
+			}
+			parent.project.children = append(parent.project.children, node)
+			stack = append(stack, node)
+
+		case parent != nil:
+			target := parent
+			if target.kind == tpHeading {
+				target = target.project
+			}
+			target.item.Attributes.Notes = appendLine(target.item.Attributes.Notes, text)
+		}
+	}
+
+	items := make([]things.JSONItem, 0, len(roots))
+	for _, root := range roots {
+		for _, child := range root.children {
+			root.item.Attributes.Items = append(root.item.Attributes.Items, *child.item)
+		}
+		items = append(items, *root.item)
+	}
+	return items, nil
+}
+
+func isTaskPaperProject(text string) bool {
+	title := strings.TrimSpace(tpTag.ReplaceAllString(text, ""))
+	return strings.HasSuffix(title, ":")
+}
+
+// tpAttributes strips the @tags from text and maps them onto attributes.
+func tpAttributes(text string) (string, things.JSONAttributes, error) {
+	var attrs things.JSONAttributes
+	for _, m := range tpTag.FindAllStringSubmatch(text, -1) {
+		name, value := m[1], strings.TrimSpace(m[2])
+		switch strings.ToLower(name) {
+		case "due":
+			attrs.Deadline = value
+			if m := tpDateTime.FindStringSubmatch(value); m != nil {
+				attrs.Deadline = m[1]
+			}
+		case "defer", "start":
+			attrs.When = value
+			if m := tpDateTime.FindStringSubmatch(value); m != nil {
+				attrs.When = m[1] + "@" + m[2]
+			}
+		case "done":
+			done := true
+			attrs.Completed = &done
+			if value != "" {
+				date, err := parseTaskPaperDate(value)
+				if err != nil {
+					return "", things.JSONAttributes{}, err
+				}
+				attrs.CompletionDate = date
+			}
+		default:
+			attrs.Tags = append(attrs.Tags, name)
+		}
+	}
+	title := strings.Join(strings.Fields(tpTag.ReplaceAllString(text, "")), " ")
+	return title, attrs, nil
+}
+
+// parseTaskPaperDate converts a TaskPaper date, with or without a time, into
+// the ISO8601 date time Things expects for completion dates.
+func parseTaskPaperDate(value string) (string, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t.Format(time.RFC3339), nil
+		}
+	}
+	return "", fmt.Errorf("@done(%s) is not a date like 2025-01-02 or 2025-01-02 14:30", value)
+}
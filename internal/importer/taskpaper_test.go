@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+const taskPaperDoc = `Launch v2: @release @due(2025-03-01)
+	Everything needed to ship.
+	- Draft announcement @writing @defer(2025-02-20 09:00)
+		Keep it short.
+	QA:
+		- Test login @due(2025-01-15)
+			- iOS @done
+			- Android
+		- Write test plan @done(2025-01-10)
+Call mom @errand:
+- Buy milk @errand
+`
+
+func TestTaskPaper(t *testing.T) {
+	items, err := TaskPaper(taskPaperDoc)
+	if err != nil {
+		t.Fatalf("TaskPaper returned error: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 top-level items, got %d", len(items))
+	}
+
+	project := items[0].Attributes
+	if items[0].Type != things.JSONProject || project.Title != "Launch v2" || project.Deadline != "2025-03-01" {
+		t.Fatalf("unexpected project: %+v", project)
+	}
+	if strings.Join(project.Tags, ",") != "release" || project.Notes != "Everything needed to ship." {
+		t.Fatalf("unexpected project tags or notes: %+v", project)
+	}
+
+	var children []string
+	for _, child := range project.Items {
+		children = append(children, child.Type+":"+child.Attributes.Title)
+	}
+	if got := strings.Join(children, ","); got != "to-do:Draft announcement,heading:QA,to-do:Test login,to-do:Write test plan" {
+		t.Fatalf("unexpected children: %s", got)
+	}
+
+	draft := project.Items[0].Attributes
+	if draft.When != "2025-02-20@09:00" || draft.Notes != "Keep it short." || draft.Tags[0] != "writing" {
+		t.Fatalf("unexpected draft: %+v", draft)
+	}
+	login := project.Items[2].Attributes
+	if done := login.ChecklistItems; login.Deadline != "2025-01-15" || len(done) != 2 || done[0].Attributes.Completed == nil || !*done[0].Attributes.Completed {
+		t.Fatalf("unexpected login: %+v", login)
+	}
+	plan := project.Items[3].Attributes
+	want := time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+	if plan.Completed == nil || !*plan.Completed || plan.CompletionDate != want {
+		t.Fatalf("unexpected completed task: %+v", plan)
+	}
+
+	if items[1].Type != things.JSONProject || items[1].Attributes.Title != "Call mom" {
+		t.Fatalf("expected second project, got %+v", items[1])
+	}
+	if items[2].Type != things.JSONToDo || items[2].Attributes.Tags[0] != "errand" {
+		t.Fatalf("expected loose to-do, got %+v", items[2])
+	}
+}
+
+func TestTaskPaperRejectsBadDoneDate(t *testing.T) {
+	_, err := TaskPaper("- Ship @done(last week)\n")
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected line-numbered date error, got %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+// Package review assembles a GTD weekly review from a Things snapshot: the
+// Inbox, overdue deadlines, projects without a next action, projects that saw
+// no activity for a while, and items that sat in Someday for long. Each item
+// carries the tool calls for the actions an agent is likely to suggest.
+package review
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/calendar"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// Defaults for Options.
+const (
+	DefaultStaleDays    = 14
+	DefaultSomedayWeeks = 8
+)
+
+// Options tune what counts as needing attention.
+type Options struct {
+	// Now is when the review happens; days are counted in its location.
+	Now time.Time
+	// StaleDays is how long a project may go without an edit, to it or one
+	// of its to-dos, before it is listed as stale.
+	StaleDays int
+	// SomedayWeeks is how long an item may sit in Someday before it is
+	// listed.
+	SomedayWeeks int
+}
+
+// Section keys, in the order a review walks through them.
+const (
+	SectionInbox        = "inbox"
+	SectionOverdue      = "overdue"
+	SectionNoNextAction = "no-next-action"
+	SectionStale        = "stale"
+	SectionSomeday      = "someday"
+)
+
+// Review is the checklist for one weekly review.
+type Review struct {
+	Date     string    `json:"date"`
+	Sections []Section `json:"sections"`
+}
+
+// Section is one step of the review. Show, when set, opens the matching list
+// in Things.
+type Section struct {
+	Key   string            `json:"key"`
+	Title string            `json:"title"`
+	Show  *things.ShowInput `json:"show,omitempty"`
+	Items []Item            `json:"items"`
+}
+
+// Item is a project or to-do to look at, with why it is listed and what can
+// be done about it.
+type Item struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Area     string   `json:"area,omitempty"`
+	Project  string   `json:"project,omitempty"`
+	Deadline string   `json:"deadline,omitempty"`
+	Reason   string   `json:"reason"`
+	Actions  []Action `json:"actions"`
+}
+
+// Action is a ready-made tool call: Arguments can be passed to Tool as is.
+type Action struct {
+	Label     string `json:"label"`
+	Tool      string `json:"tool"`
+	Arguments any    `json:"arguments"`
+}
+
+// Count returns how many items the review lists.
+func (r Review) Count() int {
+	n := 0
+	for _, s := range r.Sections {
+		n += len(s.Items)
+	}
+	return n
+}
+
+// Build assembles the review from snap. Closed items in snap count as
+// project activity and are otherwise ignored.
+func Build(snap *things.Snapshot, opts Options) Review {
+	staleDays := cmp.Or(opts.StaleDays, DefaultStaleDays)
+	somedayWeeks := cmp.Or(opts.SomedayWeeks, DefaultSomedayWeeks)
+	loc := opts.Now.Location()
+	today := calendar.Day(opts.Now)
+	todayStr := today.Format(time.DateOnly)
+
+	inbox := Section{Key: SectionInbox, Title: "Process the Inbox", Show: &things.ShowInput{ID: "inbox"}, Items: []Item{}}
+	overdue := Section{Key: SectionOverdue, Title: "Deal with overdue deadlines", Show: &things.ShowInput{ID: "deadlines"}, Items: []Item{}}
+	noNext := Section{Key: SectionNoNextAction, Title: "Give projects a next action", Items: []Item{}}
+	stale := Section{Key: SectionStale, Title: "Revisit stale projects", Items: []Item{}}
+	someday := Section{Key: SectionSomeday, Title: "Prune the Someday list", Show: &things.ShowInput{ID: "someday"}, Items: []Item{}}
+
+	for _, p := range activeProjects(snap, todayStr) {
+		item := Item{ID: p.ID, Type: "project", Title: p.Title, Area: p.area, Deadline: p.Deadline}
+		if p.openToDos == 0 {
+			item.Reason = "No open to-dos"
+			item.Actions = []Action{
+				show("Open the project to add a next action", p.ID),
+				updateProject("Complete the project if nothing is left to do", things.UpdateProjectInput{ID: p.ID, Completed: ptr(true)}),
+			}
+			noNext.Items = append(noNext.Items, item)
+			continue
+		}
+		if idle := calendar.DaysBetween(calendar.Day(p.lastActivity.In(loc)), today); !p.lastActivity.IsZero() && idle >= staleDays {
+			item.Reason = fmt.Sprintf("No activity for %d days", idle)
+			item.Actions = []Action{
+				show("Open the project to pick the next step", p.ID),
+				updateProject("Move the project to Someday if it is on hold", things.UpdateProjectInput{ID: p.ID, When: ptr("someday")}),
+			}
+			stale.Items = append(stale.Items, item)
+		}
+	}
+
+	for _, e := range snap.Entries() {
+		if e.Status != things.StatusOpen || e.Type == things.ItemHeading {
+			continue
+		}
+		if e.Deadline != "" && e.Deadline < todayStr {
+			item := newItem(e)
+			item.Reason = fmt.Sprintf("Deadline %s passed %d days ago", e.Deadline, calendar.DaysBetween(calendar.Parse(e.Deadline, loc), today))
+			item.Actions = []Action{
+				schedule(e, "Schedule it for today", "today"),
+				show("Open it to move the deadline or cancel it", e.ID),
+			}
+			overdue.Items = append(overdue.Items, item)
+		}
+		switch {
+		case e.Type == things.ItemToDo && e.When == "" && e.Project == "" && e.Area == "":
+			item := newItem(e)
+			item.Reason = "In the Inbox"
+			if !e.CreationDate.IsZero() {
+				item.Reason = fmt.Sprintf("In the Inbox for %d days", calendar.DaysBetween(calendar.Day(e.CreationDate.In(loc)), today))
+			}
+			item.Actions = []Action{
+				schedule(e, "Move it to Anytime; add a list to file it in a project or area", "anytime"),
+				schedule(e, "Schedule it for today", "today"),
+				show("Open it", e.ID),
+			}
+			inbox.Items = append(inbox.Items, item)
+		case e.When == "someday" && !e.CreationDate.IsZero():
+			weeks := calendar.DaysBetween(calendar.Day(e.CreationDate.In(loc)), today) / 7
+			if weeks < somedayWeeks {
+				continue
+			}
+			item := newItem(e)
+			item.Reason = fmt.Sprintf("In Someday for %d weeks", weeks)
+			cancel := things.UpdateInput{ID: e.ID, Canceled: ptr(true)}
+			cancelAction := Action{Label: "Cancel it if it no longer matters", Tool: "things-update", Arguments: cancel}
+			if e.Type == things.ItemProject {
+				cancelAction = updateProject(cancelAction.Label, things.UpdateProjectInput{ID: e.ID, Canceled: ptr(true)})
+			}
+			item.Actions = []Action{schedule(e, "Activate it", "anytime"), cancelAction}
+			someday.Items = append(someday.Items, item)
+		}
+	}
+
+	slices.SortStableFunc(overdue.Items, func(a, b Item) int { return cmp.Compare(a.Deadline, b.Deadline) })
+	return Review{Date: todayStr, Sections: []Section{inbox, overdue, noNext, stale, someday}}
+}
+
+type project struct {
+	things.Project
+	area         string
+	openToDos    int
+	lastActivity time.Time
+}
+
+// activeProjects returns the open projects that are not in Someday or
+// scheduled for a later day, with their open to-dos counted and the time of
+// the latest edit to them or their to-dos.
+func activeProjects(snap *things.Snapshot, today string) []project {
+	var out []project
+	add := func(projects []things.Project, area string) {
+		for _, p := range projects {
+			if p.Status != things.StatusOpen || p.When == "someday" || p.When != "anytime" && p.When > today {
+				continue
+			}
+			proj := project{Project: p, area: area, lastActivity: activity(p.Task)}
+			count := func(todos []things.ToDo) {
+				for _, todo := range todos {
+					if todo.Status == things.StatusOpen {
+						proj.openToDos++
+					}
+					if t := activity(todo.Task); t.After(proj.lastActivity) {
+						proj.lastActivity = t
+					}
+				}
+			}
+			count(p.ToDos)
+			for _, h := range p.Headings {
+				count(h.ToDos)
+			}
+			out = append(out, proj)
+		}
+	}
+	for _, area := range snap.Areas {
+		add(area.Projects, area.Title)
+	}
+	add(snap.Projects, "")
+	return out
+}
+
+// activity returns the latest of when t was created, edited, and closed.
+func activity(t things.Task) time.Time {
+	latest := t.CreationDate
+	if t.ModificationDate.After(latest) {
+		latest = t.ModificationDate
+	}
+	if t.CompletionDate != nil && t.CompletionDate.After(latest) {
+		latest = *t.CompletionDate
+	}
+	return latest
+}
+
+func newItem(e things.Entry) Item {
+	item := Item{ID: e.ID, Type: "to-do", Title: e.Title, Area: e.Area, Project: e.Project, Deadline: e.Deadline}
+	if e.Type == things.ItemProject {
+		item.Type = "project"
+	}
+	return item
+}
+
+func show(label, id string) Action {
+	return Action{Label: label, Tool: "things-show", Arguments: things.ShowInput{ID: id}}
+}
+
+func updateProject(label string, input things.UpdateProjectInput) Action {
+	return Action{Label: label, Tool: "things-update-project", Arguments: input}
+}
+
+// schedule sets when for e through the update tool matching its type.
+func schedule(e things.Entry, label, when string) Action {
+	if e.Type == things.ItemProject {
+		return updateProject(label, things.UpdateProjectInput{ID: e.ID, When: ptr(when)})
+	}
+	return Action{Label: label, Tool: "things-update", Arguments: things.UpdateInput{ID: e.ID, When: ptr(when)}}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
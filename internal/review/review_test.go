@@ -0,0 +1,123 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+func at(month time.Month, d int) time.Time {
+	return time.Date(2025, month, d, 9, 0, 0, 0, time.UTC)
+}
+
+func task(id, when, deadline string, created time.Time) things.Task {
+	return things.Task{ID: id, Title: id, Status: things.StatusOpen, When: when, Deadline: deadline, CreationDate: created, ModificationDate: created}
+}
+
+func reviewFixture() *things.Snapshot {
+	finished := at(3, 28)
+	closed := task("logged", "anytime", "", at(1, 1))
+	closed.Status = things.StatusCompleted
+	closed.CompletionDate = &finished
+	return &things.Snapshot{
+		Areas: []things.Area{{
+			Title: "Work",
+			Projects: []things.Project{
+				{Task: task("active", "anytime", "", at(1, 1)), Headings: []things.Heading{{Title: "Now", ToDos: []things.ToDo{
+					{Task: task("recent", "anytime", "", at(3, 25))},
+				}}}},
+				{Task: task("dormant", "anytime", "", at(1, 1)), ToDos: []things.ToDo{
+					{Task: task("forgotten", "anytime", "", at(2, 1))},
+					{Task: task("unfiled", "", "", at(2, 1))},
+				}},
+				{Task: task("finished", "anytime", "2025-03-20", at(1, 1)), ToDos: []things.ToDo{{Task: closed}}},
+				{Task: task("parked", "someday", "", at(3, 1))},
+				{Task: task("later", "2025-05-01", "", at(1, 1))},
+			},
+		}},
+		ToDos: []things.ToDo{
+			{Task: task("captured", "", "", at(3, 29))},
+			{Task: task("late", "2025-03-01", "2025-03-30", at(1, 1))},
+			{Task: task("dream", "someday", "", at(1, 1))},
+			{Task: task("fresh idea", "someday", "", at(3, 1))},
+		},
+	}
+}
+
+func summary(r Review) string {
+	var parts []string
+	for _, s := range r.Sections {
+		var ids []string
+		for _, item := range s.Items {
+			ids = append(ids, item.ID)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", s.Key, strings.Join(ids, ",")))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestBuild(t *testing.T) {
+	r := Build(reviewFixture(), Options{Now: at(4, 1)})
+
+	want := "inbox=captured overdue=finished,late no-next-action=finished stale=dormant someday=dream"
+	if got := summary(r); got != want {
+		t.Errorf("review = %s, want %s", got, want)
+	}
+	if r.Date != "2025-04-01" || r.Count() != 6 {
+		t.Errorf("date %s, count %d", r.Date, r.Count())
+	}
+
+	stale := r.Sections[3].Items[0]
+	if stale.Reason != "No activity for 59 days" || stale.Area != "Work" {
+		t.Errorf("stale item = %+v", stale)
+	}
+	overdue := r.Sections[1].Items[1]
+	if overdue.Reason != "Deadline 2025-03-30 passed 2 days ago" {
+		t.Errorf("overdue reason = %q", overdue.Reason)
+	}
+	if got := r.Sections[4].Items[0].Reason; got != "In Someday for 12 weeks" {
+		t.Errorf("someday reason = %q", got)
+	}
+}
+
+func TestBuildActions(t *testing.T) {
+	r := Build(reviewFixture(), Options{Now: at(4, 1)})
+
+	for _, tt := range []struct {
+		section int
+		tool    string
+		args    string
+	}{
+		{0, "things-update", `{"id":"captured","when":"anytime"}`},
+		{1, "things-update-project", `{"id":"finished","when":"today"}`},
+		{2, "things-show", `{"id":"finished"}`},
+		{3, "things-show", `{"id":"dormant"}`},
+		{4, "things-update", `{"id":"dream","when":"anytime"}`},
+	} {
+		action := r.Sections[tt.section].Items[0].Actions[0]
+		args, err := json.Marshal(action.Arguments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if action.Tool != tt.tool || string(args) != tt.args {
+			t.Errorf("%s: %s %s, want %s %s", r.Sections[tt.section].Key, action.Tool, args, tt.tool, tt.args)
+		}
+	}
+	cancel := r.Sections[4].Items[0].Actions[1]
+	if args, _ := json.Marshal(cancel.Arguments); string(args) != `{"id":"dream","canceled":true}` {
+		t.Errorf("cancel arguments = %s", args)
+	}
+}
+
+func TestBuildThresholds(t *testing.T) {
+	r := Build(reviewFixture(), Options{Now: at(4, 1), StaleDays: 90, SomedayWeeks: 4})
+
+	want := "inbox=captured overdue=finished,late no-next-action=finished stale= someday=parked,dream,fresh idea"
+	if got := summary(r); got != want {
+		t.Errorf("review = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpecNext(t *testing.T) {
+	from := time.Date(2025, 1, 31, 10, 7, 30, 0, time.UTC) // a Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 31, 10, 15, 0, 0, time.UTC)},
+		{"30 8 * * mon-fri", time.Date(2025, 2, 3, 8, 30, 0, 0, time.UTC)},
+		{"0 9 1 * *", time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 12 30 * *", time.Date(2025, 3, 30, 12, 0, 0, 0, time.UTC)},
+		{"0 0 15 * fri", time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 6 29 feb *", time.Date(2028, 2, 29, 6, 0, 0, 0, time.UTC)},
+		{"5-10/5,45 10 * * *", time.Date(2025, 1, 31, 10, 10, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		spec, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", tt.expr, err)
+		}
+		if got := spec.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSpecNextNever(t *testing.T) {
+	spec, err := Parse("0 0 31 feb *")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got := spec.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next = %v, want zero", got)
+	}
+}
+
+func TestParseRejectsBadExpressions(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * smarch *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/filelock"
+)
+
+const jobsVersion = 1
+
+// Job states.
+const (
+	JobPending  = "pending"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+// Job is a tool invocation deferred until FireAt.
+type Job struct {
+	ID         string          `json:"id"`
+	Tool       string          `json:"tool"`
+	Arguments  json.RawMessage `json:"arguments"`
+	FireAt     time.Time       `json:"fireAt"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	ClientName string          `json:"clientName,omitempty"`
+	// Owner names the server configuration that scheduled the job; only
+	// stores with the same Owner run it.
+	Owner      string     `json:"owner,omitempty"`
+	Status     string     `json:"status"`
+	ClaimedAt  *time.Time `json:"claimedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// JobStore keeps deferred jobs in a JSON file. Every operation locks the file
+// and reads it afresh, so several server processes can share one store; a due
+// job is claimed by exactly one of them, and only by a store with the Owner
+// that added it. Finished jobs are kept for a week so their results can be
+// listed.
+type JobStore struct {
+	path string
+	now  func() time.Time
+	// Owner is recorded on added jobs and limits which jobs RunDue claims,
+	// so servers started with another profile or as a dry run leave them
+	// alone.
+	Owner string
+	// CanRun, when set, limits RunDue to the jobs it accepts, such as those
+	// whose tool this server offers. Other jobs stay pending.
+	CanRun func(Job) bool
+	// Interval is how often Run looks for due jobs; it defaults to 30 seconds.
+	Interval time.Duration
+	// Logf reports runs and failures when set.
+	Logf func(format string, args ...any)
+}
+
+type jobsFile struct {
+	Version int   `json:"version"`
+	Jobs    []Job `json:"jobs"`
+}
+
+// jobRetention is how long finished jobs stay listed.
+const jobRetention = 7 * 24 * time.Hour
+
+// staleClaim is how long a job may stay running before it is taken to belong
+// to a server that stopped while running it.
+const staleClaim = 15 * time.Minute
+
+// OpenJobStore returns the store at path, creating the file on first write.
+func OpenJobStore(path string) (*JobStore, error) {
+	if path == "" {
+		return nil, errors.New("job store path is required")
+	}
+	return &JobStore{path: path, now: time.Now}, nil
+}
+
+// Add stores job as pending and returns it with its ID.
+func (s *JobStore) Add(job Job) (Job, error) {
+	var id [6]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return Job{}, err
+	}
+	job.ID = hex.EncodeToString(id[:])
+	job.CreatedAt = s.now()
+	job.Owner = s.Owner
+	job.Status = JobPending
+	err := s.update(func(f *jobsFile) error {
+		f.Jobs = append(f.Jobs, job)
+		return nil
+	})
+	return job, err
+}
+
+// List returns the jobs ordered by fire time; with pendingOnly set, only
+// jobs still waiting to run.
+func (s *JobStore) List(pendingOnly bool) ([]Job, error) {
+	var jobs []Job
+	err := s.update(func(f *jobsFile) error {
+		for _, j := range f.Jobs {
+			if !pendingOnly || j.Status == JobPending {
+				jobs = append(jobs, j)
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(jobs, func(a, b Job) int { return a.FireAt.Compare(b.FireAt) })
+	return jobs, err
+}
+
+// Cancel marks a pending job canceled so it never runs.
+func (s *JobStore) Cancel(id string) (Job, error) {
+	var job Job
+	err := s.update(func(f *jobsFile) error {
+		i := slices.IndexFunc(f.Jobs, func(j Job) bool { return j.ID == id })
+		if i < 0 {
+			return fmt.Errorf("no scheduled job %q", id)
+		}
+		if f.Jobs[i].Status != JobPending {
+			return fmt.Errorf("job %q is %s and can no longer be canceled", id, f.Jobs[i].Status)
+		}
+		now := s.now()
+		f.Jobs[i].Status = JobCanceled
+		f.Jobs[i].FinishedAt = &now
+		job = f.Jobs[i]
+		return nil
+	})
+	return job, err
+}
+
+// claim marks the pending jobs that are due and that this store may run as
+// running and returns them. Jobs left running by a server that stopped are
+// marked failed rather than run again, since they may already have taken
+// effect.
+func (s *JobStore) claim() ([]Job, error) {
+	var due []Job
+	err := s.update(func(f *jobsFile) error {
+		now := s.now()
+		for i := range f.Jobs {
+			j := &f.Jobs[i]
+			switch {
+			case j.Status == JobRunning && (j.ClaimedAt == nil || now.Sub(*j.ClaimedAt) > staleClaim):
+				j.Status = JobFailed
+				j.FinishedAt = &now
+				j.Error = "the server running the job stopped before it finished; it was not run again"
+			case j.Status == JobPending && !j.FireAt.After(now) && j.Owner == s.Owner && (s.CanRun == nil || s.CanRun(*j)):
+				j.Status = JobRunning
+				j.ClaimedAt = &now
+				due = append(due, *j)
+			}
+		}
+		return nil
+	})
+	return due, err
+}
+
+// finish records the outcome of a claimed job.
+func (s *JobStore) finish(id, result string, runErr error) error {
+	return s.update(func(f *jobsFile) error {
+		i := slices.IndexFunc(f.Jobs, func(j Job) bool { return j.ID == id })
+		if i < 0 {
+			return nil
+		}
+		now := s.now()
+		j := &f.Jobs[i]
+		j.FinishedAt = &now
+		j.Result = result
+		j.Status = JobDone
+		if runErr != nil {
+			j.Status = JobFailed
+			j.Error = runErr.Error()
+		}
+		return nil
+	})
+}
+
+// RunDue runs the jobs that are due through execute, which returns a summary
+// of what the job did, and records each outcome.
+func (s *JobStore) RunDue(ctx context.Context, execute func(context.Context, Job) (string, error)) error {
+	due, err := s.claim()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, job := range due {
+		result, runErr := execute(ctx, job)
+		if runErr != nil {
+			s.logf("scheduled %s %s: %v", job.Tool, job.ID, runErr)
+		} else {
+			s.logf("ran scheduled %s %s", job.Tool, job.ID)
+		}
+		if err := s.finish(job.ID, result, runErr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run runs due jobs right away and then every Interval until ctx is done.
+func (s *JobStore) Run(ctx context.Context, execute func(context.Context, Job) (string, error)) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.RunDue(ctx, execute); err != nil {
+			s.logf("scheduled jobs: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// update applies change to the store while holding its lock, dropping jobs
+// that finished more than jobRetention ago. The file is only rewritten when
+// something changed, and not at all when change fails.
+func (s *JobStore) update(change func(*jobsFile) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("write job store: %w", err)
+	}
+	unlock, err := filelock.Lock(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("lock job store: %w", err)
+	}
+	defer unlock()
+
+	f := &jobsFile{Version: jobsVersion}
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read job store: %w", err)
+	default:
+		if err := json.Unmarshal(data, f); err != nil {
+			return fmt.Errorf("parse job store %s: %w", s.path, err)
+		}
+		if f.Version != jobsVersion {
+			return fmt.Errorf("job store %s has unsupported version %d", s.path, f.Version)
+		}
+	}
+
+	cutoff := s.now().Add(-jobRetention)
+	f.Jobs = slices.DeleteFunc(f.Jobs, func(j Job) bool {
+		return j.FinishedAt != nil && j.FinishedAt.Before(cutoff)
+	})
+	if err := change(f); err != nil {
+		return err
+	}
+
+	updated, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(updated, data) || (data == nil && len(f.Jobs) == 0) {
+		return nil
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, updated, 0o600); err != nil {
+		return fmt.Errorf("write job store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write job store: %w", err)
+	}
+	return nil
+}
+
+func (s *JobStore) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
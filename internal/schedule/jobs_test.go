@@ -0,0 +1,171 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestJobStore(t *testing.T, path string, c *clock) *JobStore {
+	t.Helper()
+	s, err := OpenJobStore(path)
+	if err != nil {
+		t.Fatalf("OpenJobStore returned error: %v", err)
+	}
+	s.now = c.now
+	return s
+}
+
+func TestJobStoreRunsDueJobsOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	c := &clock{time.Date(2025, 1, 3, 17, 0, 0, 0, time.UTC)}
+	s := newTestJobStore(t, path, c)
+
+	monday := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	add, err := s.Add(Job{Tool: "things-add", Arguments: json.RawMessage(`{"title":"Plan week"}`), FireAt: monday})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	later, _ := s.Add(Job{Tool: "things-show", FireAt: monday.Add(time.Hour)})
+	if add.ID == "" || add.ID == later.ID || add.Status != JobPending {
+		t.Fatalf("added jobs %+v, %+v", add, later)
+	}
+
+	var ran []string
+	execute := func(_ context.Context, j Job) (string, error) {
+		var args bytes.Buffer
+		json.Compact(&args, j.Arguments)
+		ran = append(ran, j.Tool+" "+args.String())
+		return "Dispatched", nil
+	}
+	if err := s.RunDue(context.Background(), execute); err != nil || len(ran) != 0 {
+		t.Fatalf("ran %v before the fire time: %v", ran, err)
+	}
+
+	c.t = monday.Add(time.Minute)
+	other := newTestJobStore(t, path, c)
+	if err := s.RunDue(context.Background(), execute); err != nil {
+		t.Fatalf("RunDue returned error: %v", err)
+	}
+	if err := other.RunDue(context.Background(), execute); err != nil {
+		t.Fatalf("RunDue returned error: %v", err)
+	}
+	if len(ran) != 1 || ran[0] != `things-add {"title":"Plan week"}` {
+		t.Fatalf("ran %v", ran)
+	}
+
+	jobs, err := s.List(false)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].Status != JobDone || jobs[0].Result != "Dispatched" || jobs[1].Status != JobPending {
+		t.Fatalf("jobs = %+v", jobs)
+	}
+	if pending, _ := s.List(true); len(pending) != 1 || pending[0].ID != later.ID {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	// Finished jobs are dropped after a week.
+	if _, err := s.Cancel(later.ID); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+	c.t = c.t.Add(8 * 24 * time.Hour)
+	if jobs, _ := s.List(false); len(jobs) != 0 {
+		t.Fatalf("old jobs kept: %+v", jobs)
+	}
+}
+
+func TestJobStoreRecordsFailures(t *testing.T) {
+	c := &clock{time.Date(2025, 1, 3, 17, 0, 0, 0, time.UTC)}
+	s := newTestJobStore(t, filepath.Join(t.TempDir(), "jobs.json"), c)
+	job, _ := s.Add(Job{Tool: "things-update", FireAt: c.t})
+	err := s.RunDue(context.Background(), func(context.Context, Job) (string, error) {
+		return "", errors.New("auth token missing")
+	})
+	if err != nil {
+		t.Fatalf("RunDue returned error: %v", err)
+	}
+	jobs, _ := s.List(false)
+	if len(jobs) != 1 || jobs[0].Status != JobFailed || jobs[0].Error != "auth token missing" {
+		t.Fatalf("jobs = %+v", jobs)
+	}
+	if _, err := s.Cancel(job.ID); err == nil {
+		t.Fatal("expected error canceling a finished job")
+	}
+	if _, err := s.Cancel("missing"); err == nil {
+		t.Fatal("expected error canceling an unknown job")
+	}
+}
+
+func TestJobStoreRunsOnlyItsOwnersJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	c := &clock{time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)}
+	work := newTestJobStore(t, path, c)
+	work.Owner = "work"
+	dryRun := newTestJobStore(t, path, c)
+	dryRun.Owner = "work (dry run)"
+	limited := newTestJobStore(t, path, c)
+	limited.Owner = "work"
+	limited.CanRun = func(j Job) bool { return j.Tool != "things-add" }
+
+	job, err := work.Add(Job{Tool: "things-add", FireAt: c.t})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if job.Owner != "work" {
+		t.Fatalf("Owner = %q, want work", job.Owner)
+	}
+
+	var ran []string
+	execute := func(_ context.Context, j Job) (string, error) {
+		ran = append(ran, j.ID)
+		return "", nil
+	}
+	for _, s := range []*JobStore{dryRun, limited} {
+		if err := s.RunDue(context.Background(), execute); err != nil {
+			t.Fatalf("RunDue returned error: %v", err)
+		}
+	}
+	if pending, _ := work.List(true); len(ran) != 0 || len(pending) != 1 {
+		t.Fatalf("ran %v by another owner or without the tool; pending %+v", ran, pending)
+	}
+	if err := work.RunDue(context.Background(), execute); err != nil || len(ran) != 1 {
+		t.Fatalf("owner ran %v: %v", ran, err)
+	}
+}
+
+func TestJobStoreFailsStaleClaims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	c := &clock{time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)}
+	s := newTestJobStore(t, path, c)
+	job, _ := s.Add(Job{Tool: "things-add", FireAt: c.t})
+
+	// A server claims the job and stops before finishing it.
+	if _, err := s.claim(); err != nil {
+		t.Fatalf("claim returned error: %v", err)
+	}
+	c.t = c.t.Add(time.Minute)
+	if due, _ := s.claim(); len(due) != 0 {
+		t.Fatalf("claimed a running job again: %+v", due)
+	}
+	if jobs, _ := s.List(false); jobs[0].Status != JobRunning {
+		t.Fatalf("fresh claim status = %s", jobs[0].Status)
+	}
+
+	c.t = c.t.Add(staleClaim)
+	var ran []string
+	if err := s.RunDue(context.Background(), func(_ context.Context, j Job) (string, error) {
+		ran = append(ran, j.ID)
+		return "", nil
+	}); err != nil {
+		t.Fatalf("RunDue returned error: %v", err)
+	}
+	jobs, _ := s.List(false)
+	if len(ran) != 0 || jobs[0].ID != job.ID || jobs[0].Status != JobFailed || jobs[0].Error == "" || jobs[0].FinishedAt == nil {
+		t.Fatalf("ran %v; stale job = %+v", ran, jobs[0])
+	}
+}
@@ -0,0 +1,229 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/filelock"
+)
+
+const stateVersion = 1
+
+// Rule runs Action each time Spec comes due.
+type Rule struct {
+	Name   string
+	Spec   Spec
+	Action func(ctx context.Context, due time.Time) error
+}
+
+// Run reports one rule that came due in a tick. Missed counts the
+// occurrences since the rule last ran; they are all covered by this one run.
+type Run struct {
+	Rule   string
+	Due    time.Time
+	Missed int
+	Err    error
+}
+
+// Scheduler checks its rules against a state file recording when each rule
+// last ran. A rule seen for the first time starts counting from then rather
+// than running for occurrences before it existed.
+//
+// The state file is locked while it is read and updated, and a rule is
+// recorded as run before its action starts, so several processes sharing the
+// file run each occurrence once between them. An action that fails is logged
+// and not retried until the rule's next occurrence.
+type Scheduler struct {
+	rules []Rule
+	path  string
+	now   func() time.Time
+	// Interval is how often Run checks the rules; it defaults to 30 seconds.
+	Interval time.Duration
+	// Logf reports runs and failures when set.
+	Logf func(format string, args ...any)
+}
+
+type stateFile struct {
+	Version int                  `json:"version"`
+	LastRun map[string]time.Time `json:"lastRun"`
+}
+
+// New returns a scheduler for rules keeping its state at path.
+func New(path string, rules []Rule) (*Scheduler, error) {
+	if path == "" {
+		return nil, errors.New("schedule state path is required")
+	}
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, errors.New("schedule rules need a name")
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate schedule rule %q", r.Name)
+		}
+		seen[r.Name] = true
+	}
+	return &Scheduler{rules: rules, path: path, now: time.Now}, nil
+}
+
+// Run checks the rules right away and then every Interval until ctx is done.
+// Checking often, rather than sleeping until the next occurrence, notices a
+// wake from sleep promptly.
+func (s *Scheduler) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Tick(ctx); err != nil {
+			s.logf("schedule: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs every rule that has come due since it last ran, once, and
+// reports what ran.
+func (s *Scheduler) Tick(ctx context.Context) ([]Run, error) {
+	now := s.now()
+	var due []Run
+	err := s.update(func(state *stateFile) {
+		for _, r := range s.rules {
+			last, ok := state.LastRun[r.Name]
+			if !ok {
+				state.LastRun[r.Name] = now
+				continue
+			}
+			run := Run{Rule: r.Name}
+			for next := r.Spec.Next(last); !next.IsZero() && !next.After(now); next = r.Spec.Next(next) {
+				run.Due = next
+				run.Missed++
+			}
+			if run.Missed > 0 {
+				state.LastRun[r.Name] = now
+				due = append(due, run)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range due {
+		run := &due[i]
+		for _, r := range s.rules {
+			if r.Name == run.Rule {
+				run.Err = r.Action(ctx, run.Due)
+			}
+		}
+		switch {
+		case run.Err != nil:
+			s.logf("schedule: %s: %v", run.Rule, run.Err)
+		case run.Missed > 1:
+			s.logf("schedule: ran %s once for %d missed occurrences", run.Rule, run.Missed)
+		default:
+			s.logf("schedule: ran %s", run.Rule)
+		}
+	}
+	return due, nil
+}
+
+// Upcoming is a rule's last and next run.
+type Upcoming struct {
+	Rule    string
+	LastRun time.Time
+	Next    time.Time
+}
+
+// Upcoming lists when each rule last ran and runs next. Rules that have not
+// been seen yet count from now.
+func (s *Scheduler) Upcoming() ([]Upcoming, error) {
+	state, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var out []Upcoming
+	for _, r := range s.rules {
+		last, ok := state.LastRun[r.Name]
+		from := last
+		if !ok {
+			from = now
+		}
+		out = append(out, Upcoming{Rule: r.Name, LastRun: last, Next: r.Spec.Next(from)})
+	}
+	return out, nil
+}
+
+// update applies change to the state file while holding its lock.
+func (s *Scheduler) update(change func(*stateFile)) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("write schedule state: %w", err)
+	}
+	unlock, err := filelock.Lock(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("lock schedule state: %w", err)
+	}
+	defer unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	change(state)
+	return s.save(state)
+}
+
+func (s *Scheduler) load() (*stateFile, error) {
+	state := &stateFile{Version: stateVersion, LastRun: map[string]time.Time{}}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schedule state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parse schedule state %s: %w", s.path, err)
+	}
+	if state.Version != stateVersion {
+		return nil, fmt.Errorf("schedule state %s has unsupported version %d", s.path, state.Version)
+	}
+	if state.LastRun == nil {
+		state.LastRun = map[string]time.Time{}
+	}
+	return state, nil
+}
+
+// save writes the state atomically so a crash never leaves a torn file.
+func (s *Scheduler) save(state *stateFile) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write schedule state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write schedule state: %w", err)
+	}
+	return nil
+}
+
+func (s *Scheduler) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
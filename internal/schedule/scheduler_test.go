@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestScheduler(t *testing.T, path string, c *clock, runs *[]time.Time) *Scheduler {
+	t.Helper()
+	spec, err := Parse("0 8 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(path, []Rule{{Name: "water", Spec: spec, Action: func(_ context.Context, due time.Time) error {
+		*runs = append(*runs, due)
+		return nil
+	}}})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	s.now = c.now
+	return s
+}
+
+func TestTickCatchesUpMissedRunsOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	c := &clock{time.Date(2025, 1, 1, 7, 0, 0, 0, time.UTC)}
+	var runs []time.Time
+	s := newTestScheduler(t, path, c, &runs)
+
+	// The first tick only records the rule.
+	if got, err := s.Tick(context.Background()); err != nil || len(got) != 0 {
+		t.Fatalf("first tick = %+v, %v", got, err)
+	}
+	// Three mornings pass while asleep.
+	c.t = time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC)
+	got, err := s.Tick(context.Background())
+	if err != nil {
+		t.Fatalf("Tick returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].Missed != 3 || !got[0].Due.Equal(time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("tick after sleep = %+v", got)
+	}
+	if got, _ := s.Tick(context.Background()); len(got) != 0 || len(runs) != 1 {
+		t.Fatalf("rule ran again: %+v, %d runs", got, len(runs))
+	}
+
+	// A restarted process reads the same state.
+	restarted := newTestScheduler(t, path, c, &runs)
+	c.t = time.Date(2025, 1, 4, 8, 0, 30, 0, time.UTC)
+	if got, _ := restarted.Tick(context.Background()); len(got) != 1 || got[0].Missed != 1 {
+		t.Fatalf("tick after restart = %+v", got)
+	}
+	if got, _ := s.Tick(context.Background()); len(got) != 0 {
+		t.Fatalf("second process ran the same occurrence: %+v", got)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("runs = %v", runs)
+	}
+}
+
+func TestTickReportsFailedActions(t *testing.T) {
+	spec, _ := Parse("@hourly")
+	s, err := New(filepath.Join(t.TempDir(), "schedule.json"), []Rule{{Name: "fail", Spec: spec, Action: func(context.Context, time.Time) error {
+		return errors.New("things is not installed")
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &clock{time.Date(2025, 1, 1, 7, 30, 0, 0, time.UTC)}
+	s.now = c.now
+	s.Tick(context.Background())
+	c.t = c.t.Add(time.Hour)
+	got, err := s.Tick(context.Background())
+	if err != nil || len(got) != 1 || got[0].Err == nil {
+		t.Fatalf("tick = %+v, %v", got, err)
+	}
+	// A failed run is not retried before the next occurrence.
+	c.t = c.t.Add(time.Minute)
+	if got, _ := s.Tick(context.Background()); len(got) != 0 {
+		t.Fatalf("failed rule retried: %+v", got)
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	c := &clock{time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)}
+	var runs []time.Time
+	s := newTestScheduler(t, filepath.Join(t.TempDir(), "schedule.json"), c, &runs)
+	up, err := s.Upcoming()
+	if err != nil {
+		t.Fatalf("Upcoming returned error: %v", err)
+	}
+	if len(up) != 1 || !up[0].LastRun.IsZero() || !up[0].Next.Equal(time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("upcoming = %+v", up)
+	}
+}
+
+func TestNewRejectsDuplicateNames(t *testing.T) {
+	if _, err := New("state.json", []Rule{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Fatal("expected error for duplicate rule names")
+	}
+}
@@ -0,0 +1,204 @@
+// Package stats computes productivity figures from a Things snapshot that
+// includes the Logbook: completions per day, area, project, and tag, the time
+// from creation to completion, overdue deadlines, and the age of the Someday
+// backlog.
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/calendar"
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// Options select the days completions are counted for. From and To are
+// inclusive days in Now's location; overdue deadlines and backlog ages are
+// measured at Now.
+type Options struct {
+	From time.Time
+	To   time.Time
+	Now  time.Time
+}
+
+// Report holds the figures for a range of days. Completion counts cover
+// to-dos; completed projects are counted on their own.
+type Report struct {
+	From              string  `json:"from"`
+	To                string  `json:"to"`
+	Completed         int     `json:"completed"`
+	Canceled          int     `json:"canceled"`
+	CompletedProjects int     `json:"completedProjects"`
+	PerDay            []Count `json:"perDay"`
+	PerArea           []Count `json:"perArea"`
+	PerProject        []Count `json:"perProject"`
+	PerTag            []Count `json:"perTag"`
+	// AverageDaysToComplete and MedianDaysToComplete measure the time from
+	// creation to completion of the to-dos completed in the range.
+	AverageDaysToComplete float64 `json:"averageDaysToComplete"`
+	MedianDaysToComplete  float64 `json:"medianDaysToComplete"`
+	Overdue               []Item  `json:"overdue"`
+	Someday               Backlog `json:"someday"`
+}
+
+// Count is a number of completions for a day, area, project, or tag.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Item is an open project or to-do an agent may want to act on.
+type Item struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Area        string `json:"area,omitempty"`
+	Project     string `json:"project,omitempty"`
+	Deadline    string `json:"deadline,omitempty"`
+	DaysOverdue int    `json:"daysOverdue,omitempty"`
+	AgeDays     int    `json:"ageDays,omitempty"`
+}
+
+// Backlog describes the open items in Someday.
+type Backlog struct {
+	Count          int     `json:"count"`
+	AverageAgeDays float64 `json:"averageAgeDays"`
+	Oldest         []Item  `json:"oldest"`
+}
+
+// Names used for completions outside any area or project.
+const (
+	NoArea    = "No Area"
+	NoProject = "No Project"
+)
+
+// oldestShown is how many of the oldest Someday items a report lists.
+const oldestShown = 5
+
+// Compute builds the report for opts from snap, which should include closed
+// items.
+func Compute(snap *things.Snapshot, opts Options) Report {
+	loc := opts.Now.Location()
+	start := calendar.Day(opts.From.In(loc))
+	end := calendar.Day(opts.To.In(loc)).AddDate(0, 0, 1)
+	today := calendar.Day(opts.Now)
+
+	r := Report{
+		From:    start.Format(time.DateOnly),
+		To:      end.AddDate(0, 0, -1).Format(time.DateOnly),
+		PerDay:  []Count{},
+		Overdue: []Item{},
+		Someday: Backlog{Oldest: []Item{}},
+	}
+	perDay, perArea, perProject, perTag := map[string]int{}, map[string]int{}, map[string]int{}, map[string]int{}
+	var cycle []float64
+	var somedayAge float64
+	var someday []Item
+
+	for _, e := range snap.Entries() {
+		if e.Status == things.StatusOpen {
+			item := newItem(e)
+			if e.Deadline != "" && e.Deadline < today.Format(time.DateOnly) {
+				item.Deadline = e.Deadline
+				item.DaysOverdue = calendar.DaysBetween(calendar.Parse(e.Deadline, loc), today)
+				r.Overdue = append(r.Overdue, item)
+			}
+			if e.When == "someday" {
+				if !e.CreationDate.IsZero() {
+					item.AgeDays = calendar.DaysBetween(calendar.Day(e.CreationDate.In(loc)), today)
+				}
+				somedayAge += float64(item.AgeDays)
+				someday = append(someday, item)
+			}
+			continue
+		}
+
+		if e.CompletionDate == nil {
+			continue
+		}
+		closed := e.CompletionDate.In(loc)
+		if closed.Before(start) || !closed.Before(end) {
+			continue
+		}
+		switch {
+		case e.Status == things.StatusCanceled:
+			if e.Type == things.ItemToDo {
+				r.Canceled++
+			}
+			continue
+		case e.Type == things.ItemProject:
+			r.CompletedProjects++
+			continue
+		}
+
+		r.Completed++
+		perDay[closed.Format(time.DateOnly)]++
+		perArea[cmp.Or(e.Area, NoArea)]++
+		perProject[cmp.Or(e.Project, NoProject)]++
+		for _, tag := range e.Tags {
+			perTag[tag]++
+		}
+		if !e.CreationDate.IsZero() && !e.CompletionDate.Before(e.CreationDate) {
+			cycle = append(cycle, e.CompletionDate.Sub(e.CreationDate).Hours()/24)
+		}
+	}
+
+	for name, n := range perDay {
+		r.PerDay = append(r.PerDay, Count{name, n})
+	}
+	slices.SortFunc(r.PerDay, func(a, b Count) int { return cmp.Compare(a.Name, b.Name) })
+	r.PerArea = ranked(perArea)
+	r.PerProject = ranked(perProject)
+	r.PerTag = ranked(perTag)
+	r.AverageDaysToComplete, r.MedianDaysToComplete = averageAndMedian(cycle)
+
+	slices.SortStableFunc(r.Overdue, func(a, b Item) int { return cmp.Compare(a.Deadline, b.Deadline) })
+	r.Someday.Count = len(someday)
+	if len(someday) > 0 {
+		r.Someday.AverageAgeDays = round1(somedayAge / float64(len(someday)))
+	}
+	slices.SortStableFunc(someday, func(a, b Item) int { return cmp.Compare(b.AgeDays, a.AgeDays) })
+	r.Someday.Oldest = append(r.Someday.Oldest, someday[:min(oldestShown, len(someday))]...)
+	return r
+}
+
+func newItem(e things.Entry) Item {
+	item := Item{ID: e.ID, Type: "to-do", Title: e.Title, Area: e.Area, Project: e.Project}
+	if e.Type == things.ItemProject {
+		item.Type = "project"
+	}
+	return item
+}
+
+// ranked orders counts from most to fewest, then by name.
+func ranked(counts map[string]int) []Count {
+	out := []Count{}
+	for name, n := range counts {
+		out = append(out, Count{name, n})
+	}
+	slices.SortFunc(out, func(a, b Count) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Name, b.Name))
+	})
+	return out
+}
+
+func averageAndMedian(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	slices.Sort(values)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	median := values[len(values)/2]
+	if len(values)%2 == 0 {
+		median = (values[len(values)/2-1] + median) / 2
+	}
+	return round1(sum / float64(len(values))), round1(median)
+}
+
+func round1(v float64) float64 {
+	return float64(int64(v*10+0.5)) / 10
+}
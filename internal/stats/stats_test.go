@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+func at(month time.Month, d, hour int) time.Time {
+	return time.Date(2025, month, d, hour, 0, 0, 0, time.UTC)
+}
+
+func done(status string, created, completed time.Time, title string, tags ...string) things.ToDo {
+	return things.ToDo{Task: things.Task{ID: title, Title: title, Status: status, Tags: tags, CreationDate: created, CompletionDate: &completed}}
+}
+
+func open(title, when, deadline string, created time.Time) things.ToDo {
+	return things.ToDo{Task: things.Task{ID: title, Title: title, Status: things.StatusOpen, When: when, Deadline: deadline, CreationDate: created}}
+}
+
+func statsFixture() *things.Snapshot {
+	shipped := at(1, 20, 17)
+	return &things.Snapshot{
+		Areas: []things.Area{{
+			Title: "Work",
+			ToDos: []things.ToDo{
+				done(things.StatusCompleted, at(1, 1, 9), at(1, 3, 9), "Expenses", "Admin"),
+				open("Old idea", "someday", "", at(1, 1, 12)),
+			},
+			Projects: []things.Project{
+				{
+					Task: things.Task{ID: "launch", Title: "Launch", Status: things.StatusCompleted, CreationDate: at(1, 1, 9), CompletionDate: &shipped},
+					ToDos: []things.ToDo{
+						done(things.StatusCompleted, at(1, 2, 9), at(1, 3, 21), "Plan", "Urgent", "Admin"),
+						done(things.StatusCompleted, at(1, 10, 9), at(1, 20, 9), "Ship"),
+						done(things.StatusCanceled, at(1, 10, 9), at(1, 15, 9), "Dropped"),
+					},
+					Headings: []things.Heading{{Title: "QA", ToDos: []things.ToDo{
+						done(things.StatusCompleted, at(1, 5, 9), at(1, 6, 9), "Test", "Urgent"),
+					}}},
+				},
+				{
+					Task: things.Task{ID: "hiring", Title: "Hiring", Status: things.StatusOpen, Deadline: "2025-01-25", When: "someday", CreationDate: at(1, 5, 0)},
+				},
+			},
+		}},
+		ToDos: []things.ToDo{
+			done(things.StatusCompleted, at(1, 3, 8), at(1, 3, 20), "Call mom"),
+			done(things.StatusCompleted, at(1, 1, 9), at(2, 2, 9), "Next month"),
+			open("Renew passport", "", "2025-01-30", at(1, 1, 9)),
+			open("Taxes", "anytime", "2025-03-01", at(1, 1, 9)),
+		},
+	}
+}
+
+func counts(cs []Count) string {
+	var parts []string
+	for _, c := range cs {
+		parts = append(parts, fmt.Sprintf("%s=%d", c.Name, c.Count))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestCompute(t *testing.T) {
+	r := Compute(statsFixture(), Options{From: at(1, 1, 0), To: at(1, 31, 0), Now: at(2, 1, 12)})
+
+	if r.From != "2025-01-01" || r.To != "2025-01-31" {
+		t.Errorf("range = %s..%s", r.From, r.To)
+	}
+	if r.Completed != 5 || r.Canceled != 1 || r.CompletedProjects != 1 {
+		t.Errorf("completed %d, canceled %d, projects %d", r.Completed, r.Canceled, r.CompletedProjects)
+	}
+	for name, tt := range map[string]struct {
+		got  []Count
+		want string
+	}{
+		"per day":     {r.PerDay, "2025-01-03=3 2025-01-06=1 2025-01-20=1"},
+		"per area":    {r.PerArea, "Work=4 No Area=1"},
+		"per project": {r.PerProject, "Launch=3 No Project=2"},
+		"per tag":     {r.PerTag, "Admin=2 Urgent=2"},
+	} {
+		if got := counts(tt.got); got != tt.want {
+			t.Errorf("%s = %s, want %s", name, got, tt.want)
+		}
+	}
+	// 2 days, 1.5 days, 10 days, 1 day, and half a day.
+	if r.AverageDaysToComplete != 3 || r.MedianDaysToComplete != 1.5 {
+		t.Errorf("days to complete: average %v, median %v", r.AverageDaysToComplete, r.MedianDaysToComplete)
+	}
+
+	if len(r.Overdue) != 2 || r.Overdue[0].Title != "Hiring" || r.Overdue[0].DaysOverdue != 7 || r.Overdue[0].Type != "project" || r.Overdue[1].Title != "Renew passport" {
+		t.Errorf("overdue = %+v", r.Overdue)
+	}
+	if r.Someday.Count != 2 || r.Someday.AverageAgeDays != 29 || r.Someday.Oldest[0].Title != "Old idea" || r.Someday.Oldest[0].AgeDays != 31 {
+		t.Errorf("someday = %+v", r.Someday)
+	}
+}
+
+func TestComputeEmptyRange(t *testing.T) {
+	r := Compute(statsFixture(), Options{From: at(6, 1, 0), To: at(6, 30, 0), Now: at(7, 1, 0)})
+	if r.Completed != 0 || len(r.PerDay) != 0 || r.PerTag == nil || r.AverageDaysToComplete != 0 {
+		t.Errorf("report = %+v", r)
+	}
+}
@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	dateBase   = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}|[A-Za-z_][A-Za-z0-9_]*)?`)
+	dateOffset = regexp.MustCompile(`^([+-])(\d+)([dwmy])`)
+)
+
+// passThrough are when values Things understands that are not dates.
+var passThrough = map[string]bool{"today": true, "tomorrow": true, "evening": true, "anytime": true, "someday": true}
+
+// ResolveDate evaluates a date expression relative to today. An expression
+// is an optional base followed by offsets such as +3d, -1w, +2m, or +1y.
+// The base is a YYYY-MM-DD date, today, tomorrow, or the name of a variable
+// holding another expression; without one, offsets count from today. Plain
+// when keywords such as someday pass through, and an @time suffix is kept.
+func ResolveDate(expr string, vars map[string]string, today time.Time) (string, error) {
+	return resolveDepth(strings.TrimSpace(expr), vars, today, 0)
+}
+
+func resolveDepth(expr string, vars map[string]string, today time.Time, depth int) (string, error) {
+	if expr == "" || passThrough[strings.ToLower(expr)] {
+		return expr, nil
+	}
+	if depth > 8 {
+		return "", fmt.Errorf("date %q refers to itself", expr)
+	}
+	expr, clock, hasClock := strings.Cut(expr, "@")
+
+	base := dateBase.FindString(expr)
+	rest := expr[len(base):]
+	var t time.Time
+	switch {
+	case base == "" || strings.EqualFold(base, "today"):
+		t = today
+	case strings.EqualFold(base, "tomorrow"):
+		t = today.AddDate(0, 0, 1)
+	case len(base) == len("2006-01-02") && base[4] == '-':
+		parsed, err := time.ParseInLocation("2006-01-02", base, today.Location())
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q", base)
+		}
+		t = parsed
+	default:
+		value, ok := vars[base]
+		if !ok {
+			return "", fmt.Errorf("date %q uses unknown variable %q", expr, base)
+		}
+		resolved, err := resolveDepth(strings.TrimSpace(value), vars, today, depth+1)
+		if err != nil {
+			return "", fmt.Errorf("variable %s: %w", base, err)
+		}
+		if t, err = time.ParseInLocation("2006-01-02", resolved, today.Location()); err != nil {
+			return "", fmt.Errorf("variable %s is %q, not a date", base, value)
+		}
+	}
+
+	for rest != "" {
+		m := dateOffset.FindStringSubmatch(rest)
+		if m == nil {
+			return "", fmt.Errorf("invalid date %q; use a date, a variable, or offsets like +3d, -1w, +2m", expr)
+		}
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "-" {
+			n = -n
+		}
+		switch m[3] {
+		case "d":
+			t = t.AddDate(0, 0, n)
+		case "w":
+			t = t.AddDate(0, 0, 7*n)
+		case "m":
+			t = t.AddDate(0, n, 0)
+		case "y":
+			t = t.AddDate(n, 0, 0)
+		}
+		rest = rest[len(m[0]):]
+	}
+
+	date := t.Format("2006-01-02")
+	if hasClock {
+		date += "@" + clock
+	}
+	return date, nil
+}
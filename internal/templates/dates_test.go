@@ -0,0 +1,46 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveDate(t *testing.T) {
+	today := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	vars := map[string]string{"release": "2025-03-14", "kickoff": "release-2w", "loop": "loop+1d"}
+
+	tests := map[string]string{
+		"":                 "",
+		"someday":          "someday",
+		"Evening":          "Evening",
+		"+3d":              "2025-02-03",
+		"today+1w":         "2025-02-07",
+		"tomorrow":         "tomorrow",
+		"tomorrow+1d":      "2025-02-02",
+		"+1m":              "2025-03-03",
+		"2025-06-01-1y":    "2024-06-01",
+		"release":          "2025-03-14",
+		"release-1w":       "2025-03-07",
+		"release-1w+2d":    "2025-03-09",
+		"kickoff+1d":       "2025-03-01",
+		"release-1d@09:30": "2025-03-13@09:30",
+	}
+	for expr, want := range tests {
+		got, err := ResolveDate(expr, vars, today)
+		if err != nil || got != want {
+			t.Errorf("ResolveDate(%q) = %q, %v; want %q", expr, got, err, want)
+		}
+	}
+
+	for expr, wantErr := range map[string]string{
+		"launch+1d": "unknown variable",
+		"+3x":       "invalid date",
+		"release 1": "invalid date",
+		"loop":      "refers to itself",
+	} {
+		if _, err := ResolveDate(expr, vars, today); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ResolveDate(%q) error = %v, want %q", expr, err, wantErr)
+		}
+	}
+}
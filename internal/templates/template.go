@@ -0,0 +1,288 @@
+// Package templates expands project templates, YAML or JSON files describing
+// a project with headings, to-dos, checklists, and relative dates, into json
+// command items.
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+// Template describes a project to create. Text fields are Go text/template
+// strings executed with the variables, so "Release {{.version}}" works. When
+// and deadline fields are date expressions such as +3d or release-1w.
+type Template struct {
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Variables   []Variable `yaml:"variables"`
+
+	Title    string    `yaml:"title"`
+	Notes    string    `yaml:"notes"`
+	Area     string    `yaml:"area"`
+	When     string    `yaml:"when"`
+	Deadline string    `yaml:"deadline"`
+	Tags     []string  `yaml:"tags"`
+	ToDos    []ToDo    `yaml:"todos"`
+	Headings []Heading `yaml:"headings"`
+
+	// Path is the file the template was read from.
+	Path string `yaml:"-"`
+}
+
+// Variable is a value supplied when the template is applied.
+type Variable struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Default     string `yaml:"default" json:"default,omitempty"`
+	Required    bool   `yaml:"required" json:"required,omitempty"`
+}
+
+// Heading groups to-dos in the project.
+type Heading struct {
+	Title string `yaml:"title"`
+	ToDos []ToDo `yaml:"todos"`
+}
+
+// ToDo is a to-do in the project.
+type ToDo struct {
+	Title     string   `yaml:"title"`
+	Notes     string   `yaml:"notes"`
+	When      string   `yaml:"when"`
+	Deadline  string   `yaml:"deadline"`
+	Tags      []string `yaml:"tags"`
+	Checklist []string `yaml:"checklist"`
+}
+
+// extensions lists the file types LoadDir reads.
+var extensions = []string{".yaml", ".yml", ".json"}
+
+// Load reads the template file at path. The name defaults to the file name
+// without its extension.
+func Load(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+
+	var t Template
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse template %s: %w", path, err)
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	t.Path = path
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("template %s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// LoadDir reads every template in dir, sorted by name. A missing directory
+// holds no templates.
+func LoadDir(dir string) ([]*Template, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read templates: %w", err)
+	}
+
+	var list []*Template
+	seen := map[string]string{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !slices.Contains(extensions, ext) {
+			continue
+		}
+		t, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("templates %s and %s are both named %q", other, t.Path, t.Name)
+		}
+		seen[t.Name] = t.Path
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Find returns the template called name in dir.
+func Find(dir, name string) (*Template, error) {
+	list, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, t := range list {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("template %q not found; %s has no templates", name, dir)
+	}
+	return nil, fmt.Errorf("template %q not found; available templates: %s", name, strings.Join(names, ", "))
+}
+
+func (t *Template) validate() error {
+	if strings.TrimSpace(t.Title) == "" {
+		return errors.New("title is required")
+	}
+	seen := map[string]bool{}
+	for _, v := range t.Variables {
+		if v.Name == "" {
+			return errors.New("every variable needs a name")
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("variable %q is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+	}
+	return nil
+}
+
+// Expand executes the template with vars, filling in declared defaults, and
+// returns the project as a json command item. Dates are resolved against
+// today.
+func (t *Template) Expand(vars map[string]string, today time.Time) (things.JSONItem, error) {
+	values, err := t.values(vars)
+	if err != nil {
+		return things.JSONItem{}, err
+	}
+	x := &expander{values: values, today: today}
+
+	project := things.JSONAttributes{
+		Title:    x.text("title", t.Title),
+		Notes:    x.text("notes", t.Notes),
+		Area:     x.text("area", t.Area),
+		When:     x.date("when", t.When),
+		Deadline: x.date("deadline", t.Deadline),
+		Tags:     x.tags("tags", t.Tags),
+	}
+	for i, todo := range t.ToDos {
+		project.Items = append(project.Items, x.todo(fmt.Sprintf("todos[%d]", i), todo))
+	}
+	for i, h := range t.Headings {
+		field := fmt.Sprintf("headings[%d]", i)
+		project.Items = append(project.Items, things.JSONItem{
+			Type:       things.JSONHeading,
+			Attributes: things.JSONAttributes{Title: x.text(field+".title", h.Title)},
+		})
+		for j, todo := range h.ToDos {
+			project.Items = append(project.Items, x.todo(fmt.Sprintf("%s.todos[%d]", field, j), todo))
+		}
+	}
+	if x.err != nil {
+		return things.JSONItem{}, x.err
+	}
+	return things.JSONItem{Type: things.JSONProject, Attributes: project}, nil
+}
+
+// values merges vars over the declared defaults, rejecting unknown names and
+// missing required values.
+func (t *Template) values(vars map[string]string) (map[string]string, error) {
+	values := map[string]string{}
+	declared := map[string]bool{}
+	var missing []string
+	for _, v := range t.Variables {
+		declared[v.Name] = true
+		value, ok := vars[v.Name]
+		if !ok || value == "" {
+			value = v.Default
+		}
+		if value == "" && v.Required {
+			missing = append(missing, v.Name)
+		}
+		values[v.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("template %q needs variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+	for name := range vars {
+		if !declared[name] {
+			return nil, fmt.Errorf("template %q has no variable %q", t.Name, name)
+		}
+	}
+	return values, nil
+}
+
+// expander executes template fields and keeps the first error.
+type expander struct {
+	values map[string]string
+	today  time.Time
+	err    error
+}
+
+func (x *expander) text(field, src string) string {
+	if x.err != nil || !strings.Contains(src, "{{") {
+		return src
+	}
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(src)
+	if err != nil {
+		x.err = fmt.Errorf("%s: %w", field, err)
+		return ""
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, x.values); err != nil {
+		x.err = fmt.Errorf("%s: %w", field, err)
+		return ""
+	}
+	return b.String()
+}
+
+func (x *expander) date(field, src string) string {
+	expr := x.text(field, src)
+	if x.err != nil {
+		return ""
+	}
+	date, err := ResolveDate(expr, x.values, x.today)
+	if err != nil {
+		x.err = fmt.Errorf("%s: %w", field, err)
+	}
+	return date
+}
+
+func (x *expander) tags(field string, tags []string) []string {
+	var out []string
+	for i, tag := range tags {
+		out = append(out, x.text(fmt.Sprintf("%s[%d]", field, i), tag))
+	}
+	return out
+}
+
+func (x *expander) todo(field string, todo ToDo) things.JSONItem {
+	attrs := things.JSONAttributes{
+		Title:    x.text(field+".title", todo.Title),
+		Notes:    x.text(field+".notes", todo.Notes),
+		When:     x.date(field+".when", todo.When),
+		Deadline: x.date(field+".deadline", todo.Deadline),
+		Tags:     x.tags(field+".tags", todo.Tags),
+	}
+	for i, item := range todo.Checklist {
+		attrs.ChecklistItems = append(attrs.ChecklistItems, things.JSONItem{
+			Type:       things.JSONChecklistItem,
+			Attributes: things.JSONAttributes{Title: x.text(fmt.Sprintf("%s.checklist[%d]", field, i), item)},
+		})
+	}
+	return things.JSONItem{Type: things.JSONToDo, Attributes: attrs}
+}
@@ -0,0 +1,130 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/things"
+)
+
+const releaseTemplate = `
+description: Ship a new version
+variables:
+  - name: version
+    required: true
+  - name: release
+    description: release day
+    default: +2w
+title: Release {{.version}}
+area: Work
+tags: [release]
+deadline: release
+todos:
+  - title: Write release notes for {{.version}}
+    deadline: release-2d
+headings:
+  - title: Freeze
+    todos:
+      - title: Cut branch release/{{.version}}
+        when: release-1w
+        tags: [eng]
+        checklist:
+          - Bump version to {{.version}}
+          - Tag rc1
+`
+
+func writeTemplate(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExpandTemplate(t *testing.T) {
+	tmpl, err := Load(writeTemplate(t, t.TempDir(), "release.yaml", releaseTemplate))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if tmpl.Name != "release" {
+		t.Errorf("name = %q, want the file name", tmpl.Name)
+	}
+
+	today := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	item, err := tmpl.Expand(map[string]string{"version": "2.1"}, today)
+	if err != nil {
+		t.Fatalf("Expand returned error: %v", err)
+	}
+	project := item.Attributes
+	if item.Type != things.JSONProject || project.Title != "Release 2.1" || project.Area != "Work" || project.Deadline != "2025-03-17" {
+		t.Errorf("project = %+v", project)
+	}
+	if len(project.Items) != 3 {
+		t.Fatalf("got %d project items, want 3", len(project.Items))
+	}
+	notes, heading, branch := project.Items[0].Attributes, project.Items[1], project.Items[2].Attributes
+	if notes.Title != "Write release notes for 2.1" || notes.Deadline != "2025-03-15" {
+		t.Errorf("first to-do = %+v", notes)
+	}
+	if heading.Type != things.JSONHeading || heading.Attributes.Title != "Freeze" {
+		t.Errorf("heading = %+v", heading)
+	}
+	if branch.When != "2025-03-10" || len(branch.ChecklistItems) != 2 || branch.ChecklistItems[0].Attributes.Title != "Bump version to 2.1" {
+		t.Errorf("heading to-do = %+v", branch)
+	}
+
+	item, err = tmpl.Expand(map[string]string{"version": "2.2", "release": "2025-04-01"}, today)
+	if err != nil || item.Attributes.Deadline != "2025-04-01" {
+		t.Errorf("explicit release gave %q, %v", item.Attributes.Deadline, err)
+	}
+}
+
+func TestExpandRejectsBadVariables(t *testing.T) {
+	tmpl, err := Load(writeTemplate(t, t.TempDir(), "release.yaml", releaseTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		vars map[string]string
+		want string
+	}{
+		{map[string]string{}, "needs variables: version"},
+		{map[string]string{"version": "1", "verison": "2"}, `no variable "verison"`},
+		{map[string]string{"version": "1", "release": "later"}, "deadline"},
+	}
+	for _, tt := range tests {
+		if _, err := tmpl.Expand(tt.vars, time.Now()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expand(%v) error = %v, want %q", tt.vars, err, tt.want)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "release.yaml", releaseTemplate)
+	writeTemplate(t, dir, "onboarding.json", `{"name": "onboard", "title": "Onboard {{.who}}", "variables": [{"name": "who", "required": true}]}`)
+	writeTemplate(t, dir, "README.md", "not a template")
+
+	list, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir returned error: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "onboard" || list[1].Name != "release" {
+		t.Fatalf("templates = %+v", list)
+	}
+	if _, err := Find(dir, "incident"); err == nil || !strings.Contains(err.Error(), "onboard, release") {
+		t.Errorf("Find error = %v", err)
+	}
+	if list, err := LoadDir(filepath.Join(dir, "missing")); err != nil || len(list) != 0 {
+		t.Errorf("missing dir = %v, %v", list, err)
+	}
+
+	writeTemplate(t, dir, "bad.yaml", "title: X\ntodo: []\n")
+	if _, err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), "bad.yaml") {
+		t.Errorf("unknown field error = %v", err)
+	}
+}
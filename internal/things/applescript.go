@@ -0,0 +1,193 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// scriptClasses maps the item kinds accepted by the AppleScript commands to
+// their class names in the Things dictionary.
+var scriptClasses = map[string]string{
+	"to-do":   "to do",
+	"project": "project",
+	"area":    "area",
+	"tag":     "tag",
+}
+
+type AddAreaInput struct {
+	Title string   `json:"title"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// AddArea creates an area and returns its ID, or on a dry-run client the
+// script that would create it.
+func (c *Client) AddArea(ctx context.Context, input AddAreaInput) (string, error) {
+	if strings.TrimSpace(input.Title) == "" {
+		return "", errors.New("title is required")
+	}
+
+	props := "name:" + appleScriptString(input.Title)
+	if len(input.Tags) > 0 {
+		props += ", tag names:" + appleScriptString(strings.Join(input.Tags, ", "))
+	}
+	script := fmt.Sprintf(`tell application "Things3"
+	set newArea to make new area with properties {%s}
+	return id of newArea
+end tell`, props)
+
+	return c.runScript(ctx, "add-area", map[string]string{"title": input.Title, "tags": strings.Join(input.Tags, ",")}, script)
+}
+
+type AddTagInput struct {
+	Title  string `json:"title"`
+	Parent string `json:"parent,omitempty"`
+}
+
+// AddTag creates a tag, optionally nested under an existing parent tag, and
+// returns its ID, or on a dry-run client the script. Existing tags are left
+// untouched.
+func (c *Client) AddTag(ctx context.Context, input AddTagInput) (string, error) {
+	if strings.TrimSpace(input.Title) == "" {
+		return "", errors.New("title is required")
+	}
+
+	tag := appleScriptString(input.Title)
+	var parent string
+	if input.Parent != "" {
+		parent = fmt.Sprintf("\n\tset parent tag of newTag to tag %s", appleScriptString(input.Parent))
+	}
+	script := fmt.Sprintf(`tell application "Things3"
+	if exists tag %s then
+		return id of tag %s
+	end if
+	set newTag to make new tag with properties {name:%s}%s
+	return id of newTag
+end tell`, tag, tag, tag, parent)
+
+	return c.runScript(ctx, "add-tag", map[string]string{"title": input.Title, "parent": input.Parent}, script)
+}
+
+type DeleteInput struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// Delete removes an item and returns its ID, or on a dry-run client the
+// script. To-dos and projects end up in the Trash; areas and tags are removed
+// outright.
+func (c *Client) Delete(ctx context.Context, input DeleteInput) (string, error) {
+	class, err := scriptClass(input.Kind, "to-do", "project", "area", "tag")
+	if err != nil {
+		return "", err
+	}
+	if input.ID == "" {
+		return "", errors.New("id is required")
+	}
+
+	script := fmt.Sprintf(`tell application "Things3"
+	delete %s id %s
+end tell`, class, appleScriptString(input.ID))
+
+	return c.runItemScript(ctx, "delete", map[string]string{"id": input.ID, "kind": input.Kind}, script)
+}
+
+type MoveToTrashInput struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// MoveToTrash moves a to-do or project into the Trash list and returns its
+// ID, or on a dry-run client the script.
+func (c *Client) MoveToTrash(ctx context.Context, input MoveToTrashInput) (string, error) {
+	class, err := scriptClass(input.Kind, "to-do", "project")
+	if err != nil {
+		return "", err
+	}
+	if input.ID == "" {
+		return "", errors.New("id is required")
+	}
+
+	script := fmt.Sprintf(`tell application "Things3"
+	move %s id %s to list "Trash"
+end tell`, class, appleScriptString(input.ID))
+
+	return c.runItemScript(ctx, "move-to-trash", map[string]string{"id": input.ID, "kind": input.Kind}, script)
+}
+
+// runScript runs script and returns its output, reporting it as command with
+// params to OnDispatch. A dry-run client returns the script without running
+// it.
+func (c *Client) runScript(ctx context.Context, command string, params map[string]string, script string) (string, error) {
+	if c.dryRun {
+		return script, nil
+	}
+	out, err := c.scripts.Run(ctx, script)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", strings.ReplaceAll(command, "-", " "), err)
+	}
+	if c.onDispatch != nil {
+		c.onDispatch(ctx, scriptEvent(ctx, command, params))
+	}
+	return out, nil
+}
+
+// runItemScript runs a script acting on the item params["id"] and returns
+// that ID, or the script on a dry-run client.
+func (c *Client) runItemScript(ctx context.Context, command string, params map[string]string, script string) (string, error) {
+	out, err := c.runScript(ctx, command, params, script)
+	if err != nil || c.dryRun {
+		return out, err
+	}
+	return params["id"], nil
+}
+
+func scriptEvent(ctx context.Context, command string, params map[string]string) DispatchEvent {
+	event := DispatchEvent{Command: command, Client: clientName(ctx)}
+	for key, value := range params {
+		if value == "" {
+			continue
+		}
+		if event.Params == nil {
+			event.Params = map[string]string{}
+		}
+		event.Params[key] = value
+	}
+	return event
+}
+
+func scriptClass(kind string, allowed ...string) (string, error) {
+	for _, candidate := range allowed {
+		if kind == candidate {
+			return scriptClasses[kind], nil
+		}
+	}
+	sort.Strings(allowed)
+	return "", fmt.Errorf("kind must be one of %s", strings.Join(allowed, ", "))
+}
+
+// appleScriptString quotes value as an AppleScript string literal.
+func appleScriptString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
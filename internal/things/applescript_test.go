@@ -0,0 +1,127 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddAreaEscapesUserStrings(t *testing.T) {
+	scripts := &fakeScriptRunner{output: "area-id"}
+	client := NewClient(Config{Launcher: &fakeLauncher{}, Scripts: scripts})
+
+	id, err := client.AddArea(context.Background(), AddAreaInput{
+		Title: `Work" & (do shell script "rm -rf ~") & "`,
+	})
+	if err != nil {
+		t.Fatalf("AddArea returned error: %v", err)
+	}
+	if id != "area-id" {
+		t.Fatalf("AddArea returned %q, want area-id", id)
+	}
+
+	want := `{name:"Work\" & (do shell script \"rm -rf ~\") & \""}`
+	if !strings.Contains(scripts.scripts[0], want) {
+		t.Fatalf("script %q does not contain escaped title %q", scripts.scripts[0], want)
+	}
+}
+
+func TestDeleteValidatesKind(t *testing.T) {
+	scripts := &fakeScriptRunner{}
+	client := NewClient(Config{Launcher: &fakeLauncher{}, Scripts: scripts})
+
+	if _, err := client.Delete(context.Background(), DeleteInput{ID: "x", Kind: "heading"}); err == nil {
+		t.Fatalf("expected error for unsupported kind")
+	}
+	if _, err := client.MoveToTrash(context.Background(), MoveToTrashInput{ID: "x", Kind: "area"}); err == nil {
+		t.Fatalf("expected error for area in move to trash")
+	}
+	if len(scripts.scripts) != 0 {
+		t.Fatalf("expected no scripts, saw %v", scripts.scripts)
+	}
+}
+
+func TestDeleteBuildsScript(t *testing.T) {
+	scripts := &fakeScriptRunner{}
+	client := NewClient(Config{Launcher: &fakeLauncher{}, Scripts: scripts})
+
+	if _, err := client.Delete(context.Background(), DeleteInput{ID: "todo-1", Kind: "to-do"}); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if !strings.Contains(scripts.scripts[0], `delete to do id "todo-1"`) {
+		t.Fatalf("unexpected script %q", scripts.scripts[0])
+	}
+}
+
+func TestRunScriptWrapsRunnerError(t *testing.T) {
+	scripts := &fakeScriptRunner{err: errors.New("not authorized")}
+	client := NewClient(Config{Launcher: &fakeLauncher{}, Scripts: scripts})
+
+	_, err := client.MoveToTrash(context.Background(), MoveToTrashInput{ID: "todo-1", Kind: "to-do"})
+	if err == nil || !strings.Contains(err.Error(), "move to trash: not authorized") {
+		t.Fatalf("expected wrapped error, got %v", err)
+	}
+}
+
+func TestRunScriptReportsEvents(t *testing.T) {
+	var events []DispatchEvent
+	scripts := &fakeScriptRunner{output: "area-id"}
+	client := NewClient(Config{Scripts: scripts, OnDispatch: func(_ context.Context, e DispatchEvent) {
+		events = append(events, e)
+	}})
+	ctx := WithClientName(context.Background(), "claude")
+
+	if _, err := client.AddArea(ctx, AddAreaInput{Title: "Work"}); err != nil {
+		t.Fatalf("AddArea returned error: %v", err)
+	}
+	if _, err := client.MoveToTrash(ctx, MoveToTrashInput{ID: "todo-1", Kind: "to-do"}); err != nil {
+		t.Fatalf("MoveToTrash returned error: %v", err)
+	}
+	scripts.err = errors.New("not authorized")
+	if _, err := client.Delete(ctx, DeleteInput{ID: "todo-2", Kind: "to-do"}); err == nil {
+		t.Fatal("Delete succeeded")
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want the two scripts that ran", events)
+	}
+	if e := events[0]; e.Command != "add-area" || e.Client != "claude" || e.URL != "" || len(e.Params) != 1 || e.Params["title"] != "Work" {
+		t.Errorf("add area event = %+v", e)
+	}
+	if e := events[1]; e.Command != "move-to-trash" || e.Params["id"] != "todo-1" || e.Params["kind"] != "to-do" {
+		t.Errorf("move to trash event = %+v", e)
+	}
+}
+
+func TestAppleScriptStringEscapesControlCharacters(t *testing.T) {
+	got := appleScriptString("a\\b\"c\nd")
+	want := `"a\\b\"c\nd"`
+	if got != want {
+		t.Fatalf("appleScriptString returned %s, want %s", got, want)
+	}
+}
+
+func TestDryRunDoesNotRunScripts(t *testing.T) {
+	scripts := &fakeScriptRunner{output: "should-not-run"}
+	client := NewClient(Config{Launcher: &fakeLauncher{}, Scripts: scripts, DryRun: true})
+	ctx := context.Background()
+
+	for name, run := range map[string]func() (string, error){
+		"add area":      func() (string, error) { return client.AddArea(ctx, AddAreaInput{Title: "Work"}) },
+		"add tag":       func() (string, error) { return client.AddTag(ctx, AddTagInput{Title: "Urgent"}) },
+		"delete":        func() (string, error) { return client.Delete(ctx, DeleteInput{ID: "todo-1", Kind: "to-do"}) },
+		"move to trash": func() (string, error) { return client.MoveToTrash(ctx, MoveToTrashInput{ID: "todo-1", Kind: "to-do"}) },
+	} {
+		out, err := run()
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		if !strings.HasPrefix(out, `tell application "Things3"`) {
+			t.Errorf("%s returned %q, want the script", name, out)
+		}
+	}
+	if len(scripts.scripts) != 0 {
+		t.Fatalf("dry run ran scripts: %v", scripts.scripts)
+	}
+}
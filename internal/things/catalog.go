@@ -0,0 +1,111 @@
+package things
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CatalogSource supplies the areas, projects, headings, and tags used to
+// resolve names into IDs. Both *DB and *Catalog implement it.
+type CatalogSource interface {
+	Catalog(ctx context.Context) (*Catalog, error)
+}
+
+// Catalog is a snapshot of the lists known to Things.
+type Catalog struct {
+	Areas    []CatalogArea    `json:"areas,omitempty" yaml:"areas"`
+	Projects []CatalogProject `json:"projects,omitempty" yaml:"projects"`
+	// Tags is nil when the catalog does not track tags, which disables tag
+	// validation.
+	Tags []CatalogTag `json:"tags,omitempty" yaml:"tags"`
+}
+
+type CatalogArea struct {
+	ID    string `json:"id" yaml:"id"`
+	Title string `json:"title" yaml:"title"`
+}
+
+type CatalogProject struct {
+	ID       string           `json:"id" yaml:"id"`
+	Title    string           `json:"title" yaml:"title"`
+	AreaID   string           `json:"areaId,omitempty" yaml:"areaId"`
+	Headings []CatalogHeading `json:"headings,omitempty" yaml:"headings"`
+}
+
+type CatalogHeading struct {
+	ID    string `json:"id" yaml:"id"`
+	Title string `json:"title" yaml:"title"`
+}
+
+type CatalogTag struct {
+	ID    string `json:"id,omitempty" yaml:"id"`
+	Title string `json:"title" yaml:"title"`
+}
+
+// Catalog returns the catalog itself so a static file can stand in for the
+// database.
+func (c *Catalog) Catalog(context.Context) (*Catalog, error) {
+	return c, nil
+}
+
+// LoadCatalog reads a user-maintained YAML (or JSON) catalog from path.
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read catalog: %w", err)
+	}
+
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("parse catalog %s: %w", path, err)
+	}
+	if err := catalog.validate(); err != nil {
+		return nil, fmt.Errorf("catalog %s: %w", path, err)
+	}
+	return &catalog, nil
+}
+
+func (c *Catalog) validate() error {
+	for i, area := range c.Areas {
+		if area.ID == "" || area.Title == "" {
+			return fmt.Errorf("areas[%d]: id and title are required", i)
+		}
+	}
+	for i, project := range c.Projects {
+		if project.ID == "" || project.Title == "" {
+			return fmt.Errorf("projects[%d]: id and title are required", i)
+		}
+		for j, heading := range project.Headings {
+			if heading.ID == "" || heading.Title == "" {
+				return fmt.Errorf("projects[%d].headings[%d]: id and title are required", i, j)
+			}
+		}
+	}
+	for i, tag := range c.Tags {
+		if tag.Title == "" {
+			return fmt.Errorf("tags[%d]: title is required", i)
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) project(id string) (CatalogProject, bool) {
+	for _, project := range c.Projects {
+		if project.ID == id {
+			return project, true
+		}
+	}
+	return CatalogProject{}, false
+}
+
+func (c *Catalog) area(id string) (CatalogArea, bool) {
+	for _, area := range c.Areas {
+		if area.ID == id {
+			return area, true
+		}
+	}
+	return CatalogArea{}, false
+}
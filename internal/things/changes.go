@@ -0,0 +1,172 @@
+package things
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of change between two reads of the database.
+const (
+	ChangeAdded     = "added"
+	ChangeChanged   = "changed"
+	ChangeCompleted = "completed"
+	ChangeCanceled  = "canceled"
+	ChangeDeleted   = "deleted"
+)
+
+// Change is a project or to-do that was added, edited, closed, or deleted
+// between two reads. Fields names what an edit touched: title, notes, when,
+// deadline, tags, checklist, area, project, or heading.
+type Change struct {
+	Kind    string   `json:"kind"`
+	Type    string   `json:"type"`
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Area    string   `json:"area,omitempty"`
+	Project string   `json:"project,omitempty"`
+	Fields  []string `json:"fields,omitempty"`
+}
+
+// ItemStates holds the open projects and to-dos from one read, keyed by ID.
+type ItemStates map[string]Entry
+
+// ItemStates reads the open projects and to-dos.
+func (d *DB) ItemStates(ctx context.Context) (ItemStates, error) {
+	snap, err := d.Snapshot(ctx, SnapshotOptions{})
+	if err != nil {
+		return nil, err
+	}
+	states := ItemStates{}
+	for _, e := range snap.Entries() {
+		states[e.ID] = e
+	}
+	return states, nil
+}
+
+// Changes reads the open items again and reports how they differ from
+// before, returning the new states for the next comparison. Items that are no
+// longer open are looked up to tell completed and canceled ones from deleted
+// ones; emptying the Trash or moving an item there counts as deleting it.
+func (d *DB) Changes(ctx context.Context, before ItemStates) ([]Change, ItemStates, error) {
+	after, err := d.ItemStates(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var gone []string
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			gone = append(gone, id)
+		}
+	}
+	closed, err := d.closedStatuses(ctx, gone)
+	if err != nil {
+		return nil, nil, err
+	}
+	return diffStates(before, after, closed), after, nil
+}
+
+// closedStatuses returns the status of each of ids that is still in the
+// database, outside the Trash, and no longer open.
+func (d *DB) closedStatuses(ctx context.Context, ids []string) (map[string]string, error) {
+	statuses := map[string]string{}
+	if len(ids) == 0 {
+		return statuses, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := `SELECT uuid, status FROM TMTask WHERE trashed = 0 AND status != ? AND uuid IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+	rows, err := d.db.QueryContext(ctx, query, append([]any{statusOpen}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("query closed items: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var status int
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, fmt.Errorf("scan closed item: %w", err)
+		}
+		statuses[id] = taskStatus(status)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query closed items: %w", err)
+	}
+	return statuses, nil
+}
+
+// diffStates compares two reads. closed gives the status of items that left
+// the open set without being deleted. Changes are ordered by kind, then area,
+// project, and title.
+func diffStates(before, after ItemStates, closed map[string]string) []Change {
+	var changes []Change
+	for id, e := range after {
+		old, ok := before[id]
+		switch {
+		case !ok:
+			changes = append(changes, newChange(ChangeAdded, e))
+		default:
+			if fields := changedFields(old, e); len(fields) > 0 {
+				c := newChange(ChangeChanged, e)
+				c.Fields = fields
+				changes = append(changes, c)
+			}
+		}
+	}
+	for id, e := range before {
+		if _, ok := after[id]; ok {
+			continue
+		}
+		kind := ChangeDeleted
+		switch closed[id] {
+		case StatusCompleted:
+			kind = ChangeCompleted
+		case StatusCanceled:
+			kind = ChangeCanceled
+		}
+		changes = append(changes, newChange(kind, e))
+	}
+
+	order := []string{ChangeAdded, ChangeChanged, ChangeCompleted, ChangeCanceled, ChangeDeleted}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(order, a.Kind), slices.Index(order, b.Kind)),
+			cmp.Compare(a.Area, b.Area),
+			cmp.Compare(a.Project, b.Project),
+			cmp.Compare(a.Title, b.Title),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return changes
+}
+
+func newChange(kind string, e Entry) Change {
+	c := Change{Kind: kind, Type: "to-do", ID: e.ID, Title: e.Title, Area: e.Area, Project: e.Project}
+	if e.Type == ItemProject {
+		c.Type = "project"
+	}
+	return c
+}
+
+func changedFields(a, b Entry) []string {
+	var fields []string
+	add := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+	add("title", a.Title != b.Title)
+	add("notes", a.Notes != b.Notes)
+	add("when", a.When != b.When)
+	add("deadline", a.Deadline != b.Deadline)
+	add("tags", !slices.Equal(a.Tags, b.Tags))
+	add("checklist", !slices.Equal(a.Checklist, b.Checklist))
+	add("area", a.Area != b.Area)
+	add("project", a.Project != b.Project)
+	add("heading", a.Heading != b.Heading)
+	return fields
+}
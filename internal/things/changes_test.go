@@ -0,0 +1,80 @@
+package things
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// execFixture writes to a fixture database behind the read-only DB.
+func execFixture(t *testing.T, db *DB, stmts ...string) {
+	t.Helper()
+	raw, err := sql.Open("sqlite", db.path)
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer raw.Close()
+	for _, stmt := range stmts {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+}
+
+func TestDBChanges(t *testing.T) {
+	db := newFixtureDB(t,
+		`INSERT INTO TMArea (uuid, title, "index") VALUES ('area-work', 'Work', 0)`,
+		`INSERT INTO TMTag (uuid, title, "index") VALUES ('tag-urgent', 'Urgent', 0)`,
+		`INSERT INTO TMTask (uuid, type, title, area) VALUES ('proj-launch', 1, 'Launch', 'area-work')`,
+		`INSERT INTO TMTask (uuid, type, title, project) VALUES ('todo-edit', 0, 'Write plan', 'proj-launch')`,
+		`INSERT INTO TMTask (uuid, type, title, project) VALUES ('todo-done', 0, 'Book venue', 'proj-launch')`,
+		`INSERT INTO TMTask (uuid, type, title) VALUES ('todo-cancel', 0, 'Call mom')`,
+		`INSERT INTO TMTask (uuid, type, title) VALUES ('todo-trash', 0, 'Old idea')`,
+		`INSERT INTO TMTask (uuid, type, title) VALUES ('todo-purge', 0, 'Typo')`,
+		`INSERT INTO TMTask (uuid, type, title) VALUES ('todo-same', 0, 'Untouched')`,
+	)
+	ctx := context.Background()
+	before, err := db.ItemStates(ctx)
+	if err != nil {
+		t.Fatalf("ItemStates returned error: %v", err)
+	}
+	if len(before) != 7 {
+		t.Fatalf("states = %d, want 7", len(before))
+	}
+
+	execFixture(t, db,
+		`INSERT INTO TMTask (uuid, type, title, area) VALUES ('todo-new', 0, 'Buy milk', 'area-work')`,
+		`UPDATE TMTask SET title = 'Write launch plan', deadline = 132844416 WHERE uuid = 'todo-edit'`,
+		`INSERT INTO TMTaskTag (tasks, tags) VALUES ('todo-edit', 'tag-urgent')`,
+		`UPDATE TMTask SET status = 3 WHERE uuid = 'todo-done'`,
+		`UPDATE TMTask SET status = 2 WHERE uuid = 'todo-cancel'`,
+		`UPDATE TMTask SET trashed = 1 WHERE uuid = 'todo-trash'`,
+		`DELETE FROM TMTask WHERE uuid = 'todo-purge'`,
+	)
+	changes, after, err := db.Changes(ctx, before)
+	if err != nil {
+		t.Fatalf("Changes returned error: %v", err)
+	}
+
+	var got []string
+	for _, c := range changes {
+		got = append(got, fmt.Sprintf("%s %s %s/%s/%s %s", c.Kind, c.Type, c.Area, c.Project, c.Title, strings.Join(c.Fields, ",")))
+	}
+	want := []string{
+		"added to-do Work//Buy milk ",
+		"changed to-do Work/Launch/Write launch plan title,deadline,tags",
+		"completed to-do Work/Launch/Book venue ",
+		"canceled to-do //Call mom ",
+		"deleted to-do //Old idea ",
+		"deleted to-do //Typo ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes, _, err := db.Changes(ctx, after); err != nil || len(changes) != 0 {
+		t.Errorf("second read reported %+v, %v", changes, err)
+	}
+}
@@ -0,0 +1,67 @@
+package things
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// clearableField describes a field that can be emptied by an update: the
+// parameter Things expects and the input fields that would conflict.
+type clearableField struct {
+	param string
+	isSet func() bool
+}
+
+func updateClearable(input UpdateInput) map[string]clearableField {
+	return map[string]clearableField{
+		"notes": {"notes", func() bool {
+			return input.Notes != nil || input.PrependNotes != nil || input.AppendNotes != nil
+		}},
+		"when":     {"when", func() bool { return input.When != nil }},
+		"deadline": {"deadline", func() bool { return input.Deadline != nil }},
+		"tags":     {"tags", func() bool { return len(input.Tags) > 0 || len(input.AddTags) > 0 }},
+		"checklistItems": {"checklist-items", func() bool {
+			return len(input.ChecklistItems) > 0 || len(input.PrependChecklistItems) > 0 || len(input.AppendChecklistItems) > 0
+		}},
+		"list":    {"list-id", func() bool { return input.List != nil || input.ListID != nil }},
+		"heading": {"heading-id", func() bool { return input.Heading != nil || input.HeadingID != nil }},
+	}
+}
+
+func updateProjectClearable(input UpdateProjectInput) map[string]clearableField {
+	return map[string]clearableField{
+		"notes": {"notes", func() bool {
+			return input.Notes != nil || input.PrependNotes != nil || input.AppendNotes != nil
+		}},
+		"when":     {"when", func() bool { return input.When != nil }},
+		"deadline": {"deadline", func() bool { return input.Deadline != nil }},
+		"tags":     {"tags", func() bool { return len(input.Tags) > 0 || len(input.AddTags) > 0 }},
+		"area":     {"area-id", func() bool { return input.Area != nil || input.AreaID != nil }},
+	}
+}
+
+// clearParams validates the requested fields and returns the Things
+// parameters to send empty, in the order requested.
+func clearParams(clear []string, fields map[string]clearableField) ([]string, error) {
+	params := make([]string, 0, len(clear))
+	for _, name := range clear {
+		field, ok := fields[name]
+		if !ok {
+			allowed := make([]string, 0, len(fields))
+			for key := range fields {
+				allowed = append(allowed, key)
+			}
+			sort.Strings(allowed)
+			return nil, fmt.Errorf("cannot clear %q; clearable fields are %s", name, strings.Join(allowed, ", "))
+		}
+		if field.isSet() {
+			return nil, fmt.Errorf("cannot both set and clear %s", name)
+		}
+		if !slices.Contains(params, field.param) {
+			params = append(params, field.param)
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,66 @@
+package things
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestUpdateClearsRequestedFields(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher})
+
+	res, err := client.Update(context.Background(), UpdateInput{
+		AuthToken: "token",
+		ID:        "todo-id",
+		Clear:     []string{"tags", "checklistItems", "list"},
+	})
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	want := "things:///update?auth-token=token&checklist-items=&id=todo-id&list-id=&tags="
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("Update dispatched %q, want %q", got, want)
+	}
+	if len(res.Cleared) != 3 {
+		t.Fatalf("expected cleared fields in result, got %v", res.Cleared)
+	}
+}
+
+func TestUpdateRejectsUnknownOrConflictingClear(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}})
+
+	_, err := client.Update(context.Background(), UpdateInput{AuthToken: "token", ID: "todo-id", Clear: []string{"title"}})
+	if err == nil || !strings.Contains(err.Error(), "clearable fields are") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+
+	_, err = client.Update(context.Background(), UpdateInput{
+		AuthToken: "token",
+		ID:        "todo-id",
+		AddTags:   []string{"Urgent"},
+		Clear:     []string{"tags"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "both set and clear tags") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
+
+func TestUpdateProjectClearsArea(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher})
+
+	if _, err := client.UpdateProject(context.Background(), UpdateProjectInput{
+		AuthToken: "token",
+		ID:        "project-id",
+		Clear:     []string{"area", "deadline"},
+	}); err != nil {
+		t.Fatalf("UpdateProject returned error: %v", err)
+	}
+
+	want := "things:///update-project?area-id=&auth-token=token&deadline=&id=project-id"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("UpdateProject dispatched %q, want %q", got, want)
+	}
+}
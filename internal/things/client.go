@@ -1,12 +1,14 @@
 package things
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"net/url"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // Launcher abstracts how Things URLs get dispatched. Useful for testing.
@@ -29,15 +31,114 @@ func (o openLauncher) Launch(ctx context.Context, target string) error {
 	return cmd.Run()
 }
 
+// ScriptRunner executes AppleScript for operations the URL scheme lacks.
+// Useful for testing.
+type ScriptRunner interface {
+	Run(ctx context.Context, script string) (string, error)
+}
+
+type osascriptRunner struct{}
+
+// Run feeds script to osascript on stdin so user strings never reach the
+// command line.
+func (osascriptRunner) Run(ctx context.Context, script string) (string, error) {
+	cmd := exec.CommandContext(ctx, "osascript", "-")
+	cmd.Stdin = strings.NewReader(script)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
 // Client handles invoking the Things URL scheme.
 type Client struct {
-	launcher Launcher
+	launcher    Launcher
+	scripts     ScriptRunner
+	resolver    *Resolver
+	db          *DB
+	createTags  bool
+	dryRun      bool
+	strict      bool
+	authToken   string
+	reveal      *bool
+	defaults    ItemDefaults
+	limiter     *rateLimiter
+	idempotency *IdempotencyStore
+	onDispatch  func(context.Context, DispatchEvent)
+	now         func() time.Time
+	wait        func(context.Context, time.Duration) error
 }
 
 // Config controls client behaviour.
 type Config struct {
 	Activate bool
 	Launcher Launcher
+	// Catalog, when set, is used to resolve list, heading, and area names
+	// into IDs before dispatch.
+	Catalog CatalogSource
+	// CreateMissingTags creates tags the catalog does not know about via
+	// AppleScript instead of rejecting the call.
+	CreateMissingTags bool
+	Scripts           ScriptRunner
+	// DB, when set, is read after dispatch to report the IDs of created items.
+	DB *DB
+	// DryRun builds URLs without launching them and AppleScript without
+	// running it; tags are not created either.
+	DryRun bool
+	// Strict rejects calls containing parameters Things would ignore instead
+	// of reporting them as warnings.
+	Strict bool
+	// AuthToken is used by update and json calls that do not supply one.
+	AuthToken string
+	// Reveal is the default for calls that leave reveal unset.
+	Reveal *bool
+	// Defaults are applied to to-dos and projects created through Add,
+	// AddProject, and json create operations.
+	Defaults ItemDefaults
+	// RateLimit caps dispatches per window; zero means unlimited.
+	RateLimit RateLimit
+	// Idempotency, when set, makes create calls that carry an idempotency
+	// key safe to retry.
+	Idempotency *IdempotencyStore
+	// OnDispatch, when set, is called after each URL is handed to Things
+	// and after each AppleScript command succeeds. Dry runs are not
+	// reported.
+	OnDispatch func(context.Context, DispatchEvent)
+}
+
+// DispatchEvent describes a URL sent to Things, with the auth token removed,
+// or an AppleScript command run against it, which has no URL.
+type DispatchEvent struct {
+	Command string            `json:"command"`
+	URL     string            `json:"url,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
+	Client  string            `json:"client,omitempty"`
+}
+
+// ItemDefaults route and tag items that do not say otherwise.
+type ItemDefaults struct {
+	// List or ListID, with an optional Heading, receives to-dos created
+	// without a list.
+	List    string
+	ListID  string
+	Heading string
+	// Tags are added to every created to-do and project.
+	Tags []string
+	// Provenance appends a footer naming the MCP client and creation time
+	// to the notes of created items.
+	Provenance bool
+}
+
+// RateLimit allows Requests dispatches in any window of length Per.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
 }
 
 // NewClient builds a new Client using the supplied config.
@@ -47,7 +148,51 @@ func NewClient(cfg Config) *Client {
 		launcher = openLauncher{activate: cfg.Activate}
 	}
 
-	return &Client{launcher: launcher}
+	scripts := cfg.Scripts
+	if scripts == nil {
+		scripts = osascriptRunner{}
+	}
+
+	client := &Client{
+		launcher:    launcher,
+		scripts:     scripts,
+		db:          cfg.DB,
+		createTags:  cfg.CreateMissingTags,
+		dryRun:      cfg.DryRun,
+		strict:      cfg.Strict,
+		authToken:   cfg.AuthToken,
+		reveal:      cfg.Reveal,
+		defaults:    cfg.Defaults,
+		idempotency: cfg.Idempotency,
+		onDispatch:  cfg.OnDispatch,
+		now:         time.Now,
+		wait:        sleep,
+	}
+	if cfg.RateLimit.Requests > 0 && cfg.RateLimit.Per > 0 {
+		client.limiter = newRateLimiter(cfg.RateLimit.Requests, cfg.RateLimit.Per)
+	}
+	if cfg.Catalog != nil {
+		client.resolver = NewResolver(cfg.Catalog)
+	}
+	return client
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// DryRun reports whether the client builds URLs and scripts without running
+// them.
+func (c *Client) DryRun() bool {
+	return c.dryRun
 }
 
 func (c *Client) dispatch(ctx context.Context, command string, params url.Values) (string, error) {
@@ -59,13 +204,41 @@ func (c *Client) dispatch(ctx context.Context, command string, params url.Values
 	if encoded := encodeQuery(params); encoded != "" {
 		target += "?" + encoded
 	}
+	if c.dryRun {
+		return target, nil
+	}
+	if err := c.limiter.allow(); err != nil {
+		return "", err
+	}
 	if err := c.launcher.Launch(ctx, target); err != nil {
 		return "", fmt.Errorf("launch %q: %w", target, err)
 	}
+	if c.onDispatch != nil {
+		c.onDispatch(ctx, dispatchEvent(ctx, command, params))
+	}
 
 	return target, nil
 }
 
+func dispatchEvent(ctx context.Context, command string, params url.Values) DispatchEvent {
+	event := DispatchEvent{Command: command, URL: "things:///" + command, Client: clientName(ctx)}
+	public := url.Values{}
+	for key, values := range params {
+		if key == "auth-token" || len(values) == 0 {
+			continue
+		}
+		public[key] = values
+		if event.Params == nil {
+			event.Params = map[string]string{}
+		}
+		event.Params[key] = values[0]
+	}
+	if encoded := encodeQuery(public); encoded != "" {
+		event.URL += "?" + encoded
+	}
+	return event
+}
+
 func encodeQuery(values url.Values) string {
 	if len(values) == 0 {
 		return ""
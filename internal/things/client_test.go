@@ -61,6 +61,30 @@ func TestDispatchRequiresCommand(t *testing.T) {
 	}
 }
 
+func TestDispatchReportsEventsWithoutAuthToken(t *testing.T) {
+	var events []DispatchEvent
+	client := NewClient(Config{Launcher: &fakeLauncher{}, OnDispatch: func(_ context.Context, e DispatchEvent) {
+		events = append(events, e)
+	}})
+	ctx := WithClientName(context.Background(), "claude")
+
+	if _, err := client.dispatch(ctx, "update", mapValues(map[string]string{"id": "abc", "auth-token": "secret", "title": "Pay rent"})); err != nil {
+		t.Fatalf("dispatch returned error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("events = %+v", events)
+	}
+	e := events[0]
+	if e.Command != "update" || e.Client != "claude" || e.URL != "things:///update?id=abc&title=Pay%20rent" || e.Params["title"] != "Pay rent" || e.Params["auth-token"] != "" {
+		t.Fatalf("event = %+v", e)
+	}
+
+	dry := NewClient(Config{DryRun: true, OnDispatch: func(context.Context, DispatchEvent) { t.Fatal("dry run reported") }})
+	if _, err := dry.dispatch(ctx, "add", mapValues(map[string]string{"title": "x"})); err != nil {
+		t.Fatalf("dispatch returned error: %v", err)
+	}
+}
+
 func mapValues(values map[string]string) map[string][]string {
 	if len(values) == 0 {
 		return map[string][]string{}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type AddInput struct {
@@ -29,11 +30,59 @@ type AddInput struct {
 	Reveal         *bool    `json:"reveal,omitempty"`
 	CreationDate   string   `json:"creationDate,omitempty"`
 	CompletionDate string   `json:"completionDate,omitempty"`
+	IdempotencyKey string   `json:"idempotencyKey,omitempty" jsonschema:"optional key that makes retries safe: a repeat call with the same key and payload returns the original result instead of creating the items again"`
+	OnDuplicate    string   `json:"onDuplicate,omitempty" jsonschema:"check open to-dos in the target list for a similar title first: skip creates nothing, warn creates it anyway with a warning, update appends the notes to the existing item"`
 }
 
-func (c *Client) Add(ctx context.Context, input AddInput) (string, error) {
+func (c *Client) Add(ctx context.Context, input AddInput) (AddResult, error) {
+	return idempotent(c, "add", input.IdempotencyKey, input, func() (AddResult, error) {
+		return c.add(ctx, input)
+	})
+}
+
+func (c *Client) add(ctx context.Context, input AddInput) (AddResult, error) {
 	if len(input.Titles) == 0 && input.Title == "" && input.UseClipboard == "" && !boolValue(input.ShowQuickEntry) {
-		return "", errors.New("provide at least one of title, titles, useClipboard, or showQuickEntry")
+		return AddResult{}, errors.New("provide at least one of title, titles, useClipboard, or showQuickEntry")
+	}
+	if err := validateDuplicateMode(input.OnDuplicate); err != nil {
+		return AddResult{}, err
+	}
+	// Duplicates get the caller's notes; the provenance footer is only for
+	// what is created.
+	notes := input.Notes
+	c.applyAddDefaults(ctx, &input)
+	warnings := lintAdd(input)
+	if err := c.checkLint(warnings); err != nil {
+		return AddResult{}, err
+	}
+	resolved, err := c.resolveAdd(ctx, &input)
+	if err != nil {
+		return AddResult{}, err
+	}
+	if err := c.checkTags(ctx, &input.Tags); err != nil {
+		return AddResult{}, err
+	}
+	input.When = normalizeWhen(input.When)
+	input.Deadline = normalizeDate(input.Deadline)
+	if !boolValue(input.ShowQuickEntry) {
+		input.Reveal = c.defaultReveal(input.Reveal)
+	}
+
+	titles := input.Titles
+	if len(titles) == 0 && input.Title != "" {
+		titles = []string{input.Title}
+	}
+	titles, dups, err := c.checkDuplicates(ctx, input.OnDuplicate, ItemToDo, input.ListID, input.List, titles, notes)
+	if err != nil {
+		return AddResult{}, err
+	}
+	if len(dups) > 0 && len(titles) == 0 {
+		res := AddResult{Duplicates: dups}
+		res.Warnings = warnings
+		return res, nil
+	}
+	if len(input.Titles) > 0 {
+		input.Titles = titles
 	}
 
 	params := url.Values{}
@@ -62,7 +111,29 @@ func (c *Client) Add(ctx context.Context, input AddInput) (string, error) {
 	setString(params, "creation-date", input.CreationDate)
 	setString(params, "completion-date", input.CompletionDate)
 
-	return c.dispatch(ctx, "add", params)
+	started := time.Now()
+	target, err := c.dispatch(ctx, "add", params)
+	if err != nil {
+		return AddResult{}, err
+	}
+
+	res := AddResult{
+		Dispatch:   c.newDispatch(target),
+		Titles:     titles,
+		ListID:     input.ListID,
+		List:       firstNonEmpty(resolved.ListTitle, input.List),
+		HeadingID:  input.HeadingID,
+		Heading:    firstNonEmpty(resolved.HeadingTitle, input.Heading),
+		When:       input.When,
+		Deadline:   input.Deadline,
+		Tags:       input.Tags,
+		Duplicates: dups,
+	}
+	res.Warnings = append(warnings, duplicateWarnings(dups)...)
+	if !boolValue(input.ShowQuickEntry) || len(input.Titles) > 0 {
+		res.IDs = c.lookupCreated(ctx, ItemToDo, titles, started)
+	}
+	return res, nil
 }
 
 type AddProjectInput struct {
@@ -79,9 +150,51 @@ type AddProjectInput struct {
 	Reveal         *bool    `json:"reveal,omitempty"`
 	CreationDate   string   `json:"creationDate,omitempty"`
 	CompletionDate string   `json:"completionDate,omitempty"`
+	IdempotencyKey string   `json:"idempotencyKey,omitempty" jsonschema:"optional key that makes retries safe: a repeat call with the same key and payload returns the original result instead of creating the items again"`
+	OnDuplicate    string   `json:"onDuplicate,omitempty" jsonschema:"check open projects in the target area for a similar title first: skip creates nothing, warn creates it anyway with a warning, update appends the notes to the existing item"`
+}
+
+func (c *Client) AddProject(ctx context.Context, input AddProjectInput) (AddProjectResult, error) {
+	return idempotent(c, "add-project", input.IdempotencyKey, input, func() (AddProjectResult, error) {
+		return c.addProject(ctx, input)
+	})
 }
 
-func (c *Client) AddProject(ctx context.Context, input AddProjectInput) (string, error) {
+func (c *Client) addProject(ctx context.Context, input AddProjectInput) (AddProjectResult, error) {
+	if err := validateDuplicateMode(input.OnDuplicate); err != nil {
+		return AddProjectResult{}, err
+	}
+	notes := input.Notes
+	c.applyAddProjectDefaults(ctx, &input)
+	warnings := lintAddProject(input)
+	if err := c.checkLint(warnings); err != nil {
+		return AddProjectResult{}, err
+	}
+	resolved, err := c.resolveArea(ctx, &input.Area, &input.AreaID)
+	if err != nil {
+		return AddProjectResult{}, err
+	}
+	if err := c.checkTags(ctx, &input.Tags); err != nil {
+		return AddProjectResult{}, err
+	}
+	input.When = normalizeWhen(input.When)
+	input.Deadline = normalizeDate(input.Deadline)
+	input.Reveal = c.defaultReveal(input.Reveal)
+
+	var titles []string
+	if input.Title != "" {
+		titles = []string{input.Title}
+	}
+	titles, dups, err := c.checkDuplicates(ctx, input.OnDuplicate, ItemProject, input.AreaID, input.Area, titles, notes)
+	if err != nil {
+		return AddProjectResult{}, err
+	}
+	if len(dups) > 0 && len(titles) == 0 {
+		res := AddProjectResult{ID: dups[0].ID, Title: dups[0].ExistingTitle, Duplicates: dups}
+		res.Warnings = warnings
+		return res, nil
+	}
+
 	params := url.Values{}
 	setString(params, "title", input.Title)
 	setString(params, "notes", input.Notes)
@@ -101,11 +214,32 @@ func (c *Client) AddProject(ctx context.Context, input AddProjectInput) (string,
 	setString(params, "creation-date", input.CreationDate)
 	setString(params, "completion-date", input.CompletionDate)
 
-	return c.dispatch(ctx, "add-project", params)
+	started := time.Now()
+	target, err := c.dispatch(ctx, "add-project", params)
+	if err != nil {
+		return AddProjectResult{}, err
+	}
+
+	res := AddProjectResult{
+		Dispatch:   c.newDispatch(target),
+		Title:      input.Title,
+		AreaID:     input.AreaID,
+		Area:       firstNonEmpty(resolved.AreaTitle, input.Area),
+		When:       input.When,
+		Deadline:   input.Deadline,
+		Tags:       input.Tags,
+		ToDos:      input.ToDos,
+		Duplicates: dups,
+	}
+	res.Warnings = append(warnings, duplicateWarnings(dups)...)
+	if ids := c.lookupCreated(ctx, ItemProject, []string{input.Title}, started); len(ids) > 0 {
+		res.ID = ids[0]
+	}
+	return res, nil
 }
 
 type UpdateInput struct {
-	AuthToken             string   `json:"authToken"`
+	AuthToken             string   `json:"authToken,omitempty"`
 	ID                    string   `json:"id"`
 	Title                 *string  `json:"title,omitempty"`
 	Notes                 *string  `json:"notes,omitempty"`
@@ -128,15 +262,34 @@ type UpdateInput struct {
 	Duplicate             *bool    `json:"duplicate,omitempty"`
 	CreationDate          *string  `json:"creationDate,omitempty"`
 	CompletionDate        *string  `json:"completionDate,omitempty"`
+	Clear                 []string `json:"clear,omitempty" jsonschema:"fields to empty: notes, when, deadline, tags, checklistItems, list, or heading. Fields that are omitted are left unchanged"`
 }
 
-func (c *Client) Update(ctx context.Context, input UpdateInput) (string, error) {
+func (c *Client) Update(ctx context.Context, input UpdateInput) (UpdateResult, error) {
+	input.AuthToken = c.defaultAuthToken(input.AuthToken)
 	if input.AuthToken == "" {
-		return "", errors.New("authToken is required")
+		return UpdateResult{}, errors.New("authToken is required")
 	}
 	if input.ID == "" {
-		return "", errors.New("id is required")
+		return UpdateResult{}, errors.New("id is required")
+	}
+	warnings := lintUpdate(input)
+	if err := c.checkLint(warnings); err != nil {
+		return UpdateResult{}, err
+	}
+	cleared, err := clearParams(input.Clear, updateClearable(input))
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	resolved, err := c.resolveUpdate(ctx, &input)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	if err := c.checkTags(ctx, &input.Tags, &input.AddTags); err != nil {
+		return UpdateResult{}, err
 	}
+	input.When = normalizeOptional(input.When, normalizeWhen)
+	input.Deadline = normalizeOptional(input.Deadline, normalizeDate)
 
 	params := url.Values{}
 	params.Set("auth-token", input.AuthToken)
@@ -173,16 +326,39 @@ func (c *Client) Update(ctx context.Context, input UpdateInput) (string, error)
 	setBool(params, "duplicate", input.Duplicate)
 	setOptionalString(params, "creation-date", input.CreationDate)
 	setOptionalString(params, "completion-date", input.CompletionDate)
+	for _, param := range cleared {
+		params.Set(param, "")
+	}
 
 	if len(params) <= 2 {
-		return "", errors.New("provide at least one field to update")
+		return UpdateResult{}, errors.New("provide at least one field to update")
+	}
+	setBool(params, "reveal", c.defaultReveal(input.Reveal))
+
+	target, err := c.dispatch(ctx, "update", params)
+	if err != nil {
+		return UpdateResult{}, err
 	}
 
-	return c.dispatch(ctx, "update", params)
+	res := UpdateResult{
+		Dispatch:  c.newDispatch(target),
+		ID:        input.ID,
+		ListID:    stringValue(input.ListID),
+		List:      firstNonEmpty(resolved.ListTitle, stringValue(input.List)),
+		HeadingID: stringValue(input.HeadingID),
+		Heading:   firstNonEmpty(resolved.HeadingTitle, stringValue(input.Heading)),
+		When:      input.When,
+		Deadline:  input.Deadline,
+		Tags:      input.Tags,
+		AddTags:   input.AddTags,
+		Cleared:   input.Clear,
+	}
+	res.Warnings = warnings
+	return res, nil
 }
 
 type UpdateProjectInput struct {
-	AuthToken      string   `json:"authToken"`
+	AuthToken      string   `json:"authToken,omitempty"`
 	ID             string   `json:"id"`
 	Title          *string  `json:"title,omitempty"`
 	Notes          *string  `json:"notes,omitempty"`
@@ -200,15 +376,40 @@ type UpdateProjectInput struct {
 	Duplicate      *bool    `json:"duplicate,omitempty"`
 	CreationDate   *string  `json:"creationDate,omitempty"`
 	CompletionDate *string  `json:"completionDate,omitempty"`
+	Clear          []string `json:"clear,omitempty" jsonschema:"fields to empty: notes, when, deadline, tags, or area. Fields that are omitted are left unchanged"`
 }
 
-func (c *Client) UpdateProject(ctx context.Context, input UpdateProjectInput) (string, error) {
+func (c *Client) UpdateProject(ctx context.Context, input UpdateProjectInput) (UpdateProjectResult, error) {
+	input.AuthToken = c.defaultAuthToken(input.AuthToken)
 	if input.AuthToken == "" {
-		return "", errors.New("authToken is required")
+		return UpdateProjectResult{}, errors.New("authToken is required")
 	}
 	if input.ID == "" {
-		return "", errors.New("id is required")
+		return UpdateProjectResult{}, errors.New("id is required")
+	}
+	warnings := lintUpdateProject(input)
+	if err := c.checkLint(warnings); err != nil {
+		return UpdateProjectResult{}, err
 	}
+	cleared, err := clearParams(input.Clear, updateProjectClearable(input))
+	if err != nil {
+		return UpdateProjectResult{}, err
+	}
+	var resolved Resolution
+	if input.Area != nil && input.AreaID == nil {
+		area, areaID := *input.Area, ""
+		if resolved, err = c.resolveArea(ctx, &area, &areaID); err != nil {
+			return UpdateProjectResult{}, err
+		}
+		if areaID != "" {
+			input.AreaID, input.Area = &areaID, nil
+		}
+	}
+	if err := c.checkTags(ctx, &input.Tags, &input.AddTags); err != nil {
+		return UpdateProjectResult{}, err
+	}
+	input.When = normalizeOptional(input.When, normalizeWhen)
+	input.Deadline = normalizeOptional(input.Deadline, normalizeDate)
 
 	params := url.Values{}
 	params.Set("auth-token", input.AuthToken)
@@ -234,12 +435,33 @@ func (c *Client) UpdateProject(ctx context.Context, input UpdateProjectInput) (s
 	setBool(params, "duplicate", input.Duplicate)
 	setOptionalString(params, "creation-date", input.CreationDate)
 	setOptionalString(params, "completion-date", input.CompletionDate)
+	for _, param := range cleared {
+		params.Set(param, "")
+	}
 
 	if len(params) <= 2 {
-		return "", errors.New("provide at least one field to update")
+		return UpdateProjectResult{}, errors.New("provide at least one field to update")
 	}
+	setBool(params, "reveal", c.defaultReveal(input.Reveal))
 
-	return c.dispatch(ctx, "update-project", params)
+	target, err := c.dispatch(ctx, "update-project", params)
+	if err != nil {
+		return UpdateProjectResult{}, err
+	}
+
+	res := UpdateProjectResult{
+		Dispatch: c.newDispatch(target),
+		ID:       input.ID,
+		AreaID:   stringValue(input.AreaID),
+		Area:     firstNonEmpty(resolved.AreaTitle, stringValue(input.Area)),
+		When:     input.When,
+		Deadline: input.Deadline,
+		Tags:     input.Tags,
+		AddTags:  input.AddTags,
+		Cleared:  input.Clear,
+	}
+	res.Warnings = warnings
+	return res, nil
 }
 
 type ShowInput struct {
@@ -248,9 +470,14 @@ type ShowInput struct {
 	Filter []string `json:"filter,omitempty"`
 }
 
-func (c *Client) Show(ctx context.Context, input ShowInput) (string, error) {
+func (c *Client) Show(ctx context.Context, input ShowInput) (ShowResult, error) {
 	if input.ID == "" && input.Query == "" {
-		return "", errors.New("provide id or query")
+		return ShowResult{}, errors.New("provide id or query")
+	}
+
+	warnings := lintShow(input)
+	if err := c.checkLint(warnings); err != nil {
+		return ShowResult{}, err
 	}
 
 	params := url.Values{}
@@ -262,52 +489,89 @@ func (c *Client) Show(ctx context.Context, input ShowInput) (string, error) {
 		params.Set("filter", strings.Join(input.Filter, ","))
 	}
 
-	return c.dispatch(ctx, "show", params)
+	target, err := c.dispatch(ctx, "show", params)
+	if err != nil {
+		return ShowResult{}, err
+	}
+
+	res := ShowResult{Dispatch: c.newDispatch(target), Target: firstNonEmpty(input.ID, input.Query)}
+	res.Warnings = warnings
+	return res, nil
 }
 
 type SearchInput struct {
 	Query string `json:"query,omitempty"`
 }
 
-func (c *Client) Search(ctx context.Context, input SearchInput) (string, error) {
+func (c *Client) Search(ctx context.Context, input SearchInput) (SearchResult, error) {
 	params := url.Values{}
 	setString(params, "query", input.Query)
-	return c.dispatch(ctx, "search", params)
+	target, err := c.dispatch(ctx, "search", params)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	return SearchResult{Dispatch: c.newDispatch(target)}, nil
 }
 
 type VersionInput struct{}
 
-func (c *Client) Version(ctx context.Context, _ VersionInput) (string, error) {
-	return c.dispatch(ctx, "version", url.Values{})
+func (c *Client) Version(ctx context.Context, _ VersionInput) (VersionResult, error) {
+	target, err := c.dispatch(ctx, "version", url.Values{})
+	if err != nil {
+		return VersionResult{}, err
+	}
+	return VersionResult{Dispatch: c.newDispatch(target)}, nil
 }
 
 type JSONInput struct {
-	AuthToken string          `json:"authToken,omitempty"`
-	Data      json.RawMessage `json:"data"`
-	Reveal    *bool           `json:"reveal,omitempty"`
+	AuthToken      string          `json:"authToken,omitempty"`
+	Data           json.RawMessage `json:"data"`
+	Reveal         *bool           `json:"reveal,omitempty"`
+	IdempotencyKey string          `json:"idempotencyKey,omitempty" jsonschema:"optional key that makes retries safe: a repeat call with the same key and payload returns the original result instead of dispatching again"`
 }
 
-func (c *Client) JSON(ctx context.Context, input JSONInput) (string, error) {
+func (c *Client) JSON(ctx context.Context, input JSONInput) (JSONResult, error) {
+	// The auth token is not part of the payload; a retry may pick it up
+	// from the config instead.
+	payload := input
+	payload.AuthToken = ""
+	return idempotent(c, "json", input.IdempotencyKey, payload, func() (JSONResult, error) {
+		return c.json(ctx, input)
+	})
+}
+
+func (c *Client) json(ctx context.Context, input JSONInput) (JSONResult, error) {
 	if len(input.Data) == 0 {
-		return "", errors.New("data is required")
+		return JSONResult{}, errors.New("data is required")
 	}
 	if !json.Valid(input.Data) {
-		return "", errors.New("data must be valid JSON")
+		return JSONResult{}, errors.New("data must be valid JSON")
 	}
 
 	var compact bytes.Buffer
 	if err := json.Compact(&compact, input.Data); err != nil {
-		return "", fmt.Errorf("compact data: %w", err)
+		return JSONResult{}, fmt.Errorf("compact data: %w", err)
 	}
 
-	params := url.Values{}
-	if input.AuthToken != "" {
-		params.Set("auth-token", input.AuthToken)
+	var items []json.RawMessage
+	if err := json.Unmarshal(compact.Bytes(), &items); err != nil {
+		return JSONResult{}, errors.New("data must be a JSON array of items")
+	}
+	data, err := c.applyJSONDefaults(ctx, compact.Bytes())
+	if err != nil {
+		return JSONResult{}, err
 	}
-	params.Set("data", compact.String())
-	setBool(params, "reveal", input.Reveal)
 
-	return c.dispatch(ctx, "json", params)
+	params := url.Values{}
+	setString(params, "auth-token", c.defaultAuthToken(input.AuthToken))
+	params.Set("data", string(data))
+	setBool(params, "reveal", c.defaultReveal(input.Reveal))
+
+	target, err := c.dispatch(ctx, "json", params)
+	if err != nil {
+		return JSONResult{}, err
+	}
+	return JSONResult{Dispatch: c.newDispatch(target), Items: len(items)}, nil
 }
 
 func setString(params url.Values, key, value string) {
@@ -338,6 +602,22 @@ func joinLines(values []string) string {
 	return strings.Join(values, "\n")
 }
 
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 func boolValue(value *bool) bool {
 	if value == nil {
 		return false
@@ -0,0 +1,272 @@
+package things
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const groupContainer = "Library/Group Containers/JLMPQHK86H.com.culturedcode.ThingsMac"
+
+// ItemType is the kind of row stored in the TMTask table.
+type ItemType int
+
+const (
+	ItemToDo    ItemType = 0
+	ItemProject ItemType = 1
+	ItemHeading ItemType = 2
+)
+
+// Statuses as stored in the TMTask table.
+const (
+	statusOpen = 0
+)
+
+// DB reads the Things SQLite database. It never writes; all changes still go
+// through the URL scheme.
+type DB struct {
+	db   *sql.DB
+	path string
+}
+
+// DefaultDBPath locates the Things database inside the current user's group
+// container.
+func DefaultDBPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locate home directory: %w", err)
+	}
+
+	patterns := []string{
+		filepath.Join(home, groupContainer, "ThingsData-*", "Things Database.thingsdatabase", "main.sqlite"),
+		filepath.Join(home, groupContainer, "Things Database.thingsdatabase", "main.sqlite"),
+	}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", err
+		}
+		if len(matches) > 0 {
+			return matches[0], nil
+		}
+	}
+	return "", errors.New("things database not found")
+}
+
+// OpenDB opens the database at path in read-only mode.
+func OpenDB(path string) (*DB, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve database path: %w", err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	dsn := (&url.URL{Scheme: "file", Path: abs, RawQuery: "mode=ro"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	return &DB{db: db, path: abs}, nil
+}
+
+// Close releases the underlying connection pool.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Catalog reads the tags and the open areas, projects, and headings from the
+// database.
+func (d *DB) Catalog(ctx context.Context) (*Catalog, error) {
+	catalog := &Catalog{Tags: []CatalogTag{}}
+
+	rows, err := d.db.QueryContext(ctx, `SELECT uuid, title FROM TMArea ORDER BY "index"`)
+	if err != nil {
+		return nil, fmt.Errorf("query areas: %w", err)
+	}
+	for rows.Next() {
+		var area CatalogArea
+		if err := rows.Scan(&area.ID, &area.Title); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan area: %w", err)
+		}
+		catalog.Areas = append(catalog.Areas, area)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query areas: %w", err)
+	}
+
+	rows, err = d.db.QueryContext(ctx, `
+		SELECT uuid, COALESCE(title, ''), COALESCE(area, '')
+		FROM TMTask
+		WHERE type = ? AND status = ? AND trashed = 0
+		ORDER BY "index"`, ItemProject, statusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("query projects: %w", err)
+	}
+	projects := map[string]int{}
+	for rows.Next() {
+		var project CatalogProject
+		if err := rows.Scan(&project.ID, &project.Title, &project.AreaID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects[project.ID] = len(catalog.Projects)
+		catalog.Projects = append(catalog.Projects, project)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query projects: %w", err)
+	}
+
+	rows, err = d.db.QueryContext(ctx, `SELECT uuid, COALESCE(title, '') FROM TMTag ORDER BY "index"`)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	for rows.Next() {
+		var tag CatalogTag
+		if err := rows.Scan(&tag.ID, &tag.Title); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		catalog.Tags = append(catalog.Tags, tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+
+	rows, err = d.db.QueryContext(ctx, `
+		SELECT uuid, COALESCE(title, ''), COALESCE(project, '')
+		FROM TMTask
+		WHERE type = ? AND status = ? AND trashed = 0
+		ORDER BY "index"`, ItemHeading, statusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("query headings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var heading CatalogHeading
+		var projectID string
+		if err := rows.Scan(&heading.ID, &heading.Title, &projectID); err != nil {
+			return nil, fmt.Errorf("scan heading: %w", err)
+		}
+		if idx, ok := projects[projectID]; ok {
+			catalog.Projects[idx].Headings = append(catalog.Projects[idx].Headings, heading)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query headings: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// CreatedSince returns the IDs of items of type kind that were created at or
+// after since and whose titles appear in titles, ordered to match titles.
+func (d *DB) CreatedSince(ctx context.Context, kind ItemType, titles []string, since time.Time) ([]string, error) {
+	if len(titles) == 0 {
+		return nil, nil
+	}
+
+	args := []any{kind, unixSeconds(since)}
+	for _, title := range titles {
+		args = append(args, title)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(titles)), ",")
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT uuid, title
+		FROM TMTask
+		WHERE type = ? AND trashed = 0 AND creationDate >= ? AND title IN (`+placeholders+`)
+		ORDER BY creationDate, "index"`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query created items: %w", err)
+	}
+	defer rows.Close()
+
+	byTitle := map[string][]string{}
+	for rows.Next() {
+		var id, title string
+		if err := rows.Scan(&id, &title); err != nil {
+			return nil, fmt.Errorf("scan created item: %w", err)
+		}
+		byTitle[title] = append(byTitle[title], id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query created items: %w", err)
+	}
+
+	var ids []string
+	for _, title := range titles {
+		if queue := byTitle[title]; len(queue) > 0 {
+			ids = append(ids, queue[0])
+			byTitle[title] = queue[1:]
+		}
+	}
+	return ids, nil
+}
+
+// OpenItem is an open to-do or project as read for duplicate checks.
+type OpenItem struct {
+	ID    string
+	Title string
+}
+
+// OpenItems returns the open, untrashed items of type kind filed directly in
+// container. For to-dos the container is a project, including its headings,
+// or an area; an empty container selects unfiled to-dos such as the Inbox.
+// For projects the container is an area, or empty for projects without one.
+func (d *DB) OpenItems(ctx context.Context, kind ItemType, container string) ([]OpenItem, error) {
+	query := `SELECT uuid, COALESCE(title, '') FROM TMTask WHERE type = ? AND status = ? AND trashed = 0`
+	args := []any{kind, statusOpen}
+	switch {
+	case kind == ItemToDo && container == "":
+		query += ` AND project IS NULL AND area IS NULL AND heading IS NULL`
+	case kind == ItemToDo:
+		query += ` AND (project = ? OR area = ? OR heading IN (SELECT uuid FROM TMTask WHERE type = ? AND project = ?))`
+		args = append(args, container, container, ItemHeading, container)
+	case container == "":
+		query += ` AND area IS NULL`
+	default:
+		query += ` AND area = ?`
+		args = append(args, container)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query+` ORDER BY "index"`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query open items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []OpenItem
+	for rows.Next() {
+		var item OpenItem
+		if err := rows.Scan(&item.ID, &item.Title); err != nil {
+			return nil, fmt.Errorf("scan open item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query open items: %w", err)
+	}
+	return items, nil
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
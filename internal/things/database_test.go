@@ -0,0 +1,131 @@
+package things
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// fixtureSchema mirrors the subset of the Things schema the reader touches.
+const fixtureSchema = `
+CREATE TABLE TMArea (
+	uuid TEXT PRIMARY KEY,
+	title TEXT,
+	visible INTEGER,
+	"index" INTEGER DEFAULT 0
+);
+CREATE TABLE TMTask (
+	uuid TEXT PRIMARY KEY,
+	type INTEGER DEFAULT 0,
+	title TEXT,
+	notes TEXT DEFAULT '',
+	status INTEGER DEFAULT 0,
+	trashed INTEGER DEFAULT 0,
+	start INTEGER DEFAULT 0,
+	startDate INTEGER,
+	startBucket INTEGER DEFAULT 0,
+	deadline INTEGER,
+	stopDate REAL,
+	creationDate REAL,
+	userModificationDate REAL,
+	area TEXT,
+	project TEXT,
+	heading TEXT,
+	"index" INTEGER DEFAULT 0,
+	todayIndex INTEGER DEFAULT 0,
+	rt1_recurrenceRule BLOB
+);
+CREATE TABLE TMTag (
+	uuid TEXT PRIMARY KEY,
+	title TEXT,
+	shortcut TEXT,
+	parent TEXT,
+	"index" INTEGER DEFAULT 0
+);
+CREATE TABLE TMTaskTag (
+	tasks TEXT,
+	tags TEXT
+);
+CREATE TABLE TMAreaTag (
+	areas TEXT,
+	tags TEXT
+);
+CREATE TABLE TMChecklistItem (
+	uuid TEXT PRIMARY KEY,
+	title TEXT,
+	status INTEGER DEFAULT 0,
+	stopDate REAL,
+	"index" INTEGER DEFAULT 0,
+	task TEXT,
+	creationDate REAL,
+	userModificationDate REAL
+);
+`
+
+// newFixtureDB builds a throwaway database from the schema plus stmts and
+// opens it read-only through OpenDB.
+func newFixtureDB(t *testing.T, stmts ...string) *DB {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "main.sqlite")
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("create fixture: %v", err)
+	}
+	for _, stmt := range append([]string{fixtureSchema}, stmts...) {
+		if _, err := raw.Exec(stmt); err != nil {
+			raw.Close()
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	raw.Close()
+
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("OpenDB returned error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDBCatalogSkipsClosedAndTrashedItems(t *testing.T) {
+	db := newFixtureDB(t,
+		`INSERT INTO TMArea (uuid, title, "index") VALUES ('area-work', 'Work', 0), ('area-home', 'Home', 1)`,
+		`INSERT INTO TMTask (uuid, type, title, area) VALUES ('proj-launch', 1, 'Launch', 'area-work')`,
+		`INSERT INTO TMTask (uuid, type, title, status) VALUES ('proj-done', 1, 'Done', 3)`,
+		`INSERT INTO TMTask (uuid, type, title, trashed) VALUES ('proj-trash', 1, 'Trash', 1)`,
+		`INSERT INTO TMTask (uuid, type, title, project) VALUES ('head-qa', 2, 'QA', 'proj-launch')`,
+		`INSERT INTO TMTask (uuid, type, title, project, status) VALUES ('head-old', 2, 'Old', 'proj-launch', 3)`,
+		`INSERT INTO TMTask (uuid, type, title, project) VALUES ('todo-1', 0, 'Ship it', 'proj-launch')`,
+		`INSERT INTO TMTag (uuid, title) VALUES ('tag-urgent', 'Urgent')`,
+	)
+
+	catalog, err := db.Catalog(context.Background())
+	if err != nil {
+		t.Fatalf("Catalog returned error: %v", err)
+	}
+
+	if len(catalog.Areas) != 2 || catalog.Areas[0].Title != "Work" {
+		t.Fatalf("unexpected areas %+v", catalog.Areas)
+	}
+	if len(catalog.Projects) != 1 {
+		t.Fatalf("expected 1 open project, got %+v", catalog.Projects)
+	}
+	project := catalog.Projects[0]
+	if project.ID != "proj-launch" || project.AreaID != "area-work" {
+		t.Fatalf("unexpected project %+v", project)
+	}
+	if len(project.Headings) != 1 || project.Headings[0].ID != "head-qa" {
+		t.Fatalf("unexpected headings %+v", project.Headings)
+	}
+	if len(catalog.Tags) != 1 || catalog.Tags[0].Title != "Urgent" {
+		t.Fatalf("unexpected tags %+v", catalog.Tags)
+	}
+}
+
+func TestOpenDBRejectsMissingFile(t *testing.T) {
+	if _, err := OpenDB(filepath.Join(t.TempDir(), "missing.sqlite")); err == nil {
+		t.Fatalf("expected error for missing database")
+	}
+}
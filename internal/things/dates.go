@@ -0,0 +1,47 @@
+package things
+
+import (
+	"strings"
+	"time"
+)
+
+var dateKeywords = map[string]bool{
+	"today":    true,
+	"tomorrow": true,
+	"evening":  true,
+	"anytime":  true,
+	"someday":  true,
+}
+
+// normalizeWhen canonicalizes a when value: keywords are lower-cased and
+// numeric dates zero-padded, keeping any @time reminder. Anything else, such
+// as "next tuesday", is left for Things to interpret.
+func normalizeWhen(value string) string {
+	date, clock, hasTime := strings.Cut(strings.TrimSpace(value), "@")
+	date = normalizeDate(date)
+	if hasTime {
+		return date + "@" + strings.TrimSpace(clock)
+	}
+	return date
+}
+
+// normalizeDate canonicalizes a date string the same way as normalizeWhen,
+// without the time component.
+func normalizeDate(value string) string {
+	value = strings.TrimSpace(value)
+	if lower := strings.ToLower(value); dateKeywords[lower] {
+		return lower
+	}
+	if t, err := time.Parse("2006-1-2", value); err == nil {
+		return t.Format(time.DateOnly)
+	}
+	return value
+}
+
+func normalizeOptional(value *string, normalize func(string) string) *string {
+	if value == nil {
+		return nil
+	}
+	normalized := normalize(*value)
+	return &normalized
+}
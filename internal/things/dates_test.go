@@ -0,0 +1,20 @@
+package things
+
+import "testing"
+
+func TestNormalizeWhen(t *testing.T) {
+	cases := map[string]string{
+		"Today":            "today",
+		" EVENING ":        "evening",
+		"2025-1-5":         "2025-01-05",
+		"2025-1-5@ 9:30PM": "2025-01-05@9:30PM",
+		"evening@6pm":      "evening@6pm",
+		"next tuesday":     "next tuesday",
+		"":                 "",
+	}
+	for in, want := range cases {
+		if got := normalizeWhen(in); got != want {
+			t.Errorf("normalizeWhen(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
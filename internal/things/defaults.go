@@ -0,0 +1,182 @@
+package things
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type clientNameKey struct{}
+
+// WithClientName records the name of the MCP client making a call so
+// provenance footers can credit it.
+func WithClientName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, clientNameKey{}, name)
+}
+
+func clientName(ctx context.Context) string {
+	name, _ := ctx.Value(clientNameKey{}).(string)
+	return name
+}
+
+// applyAddDefaults routes and tags a to-do that does not say otherwise. The
+// default heading is only used together with the default list.
+func (c *Client) applyAddDefaults(ctx context.Context, input *AddInput) {
+	d := c.defaults
+	if input.List == "" && input.ListID == "" && (d.List != "" || d.ListID != "") {
+		input.List, input.ListID = d.List, d.ListID
+		if input.Heading == "" && input.HeadingID == "" {
+			input.Heading = d.Heading
+		}
+	}
+	input.Tags = mergeTags(input.Tags, d.Tags)
+	// The clipboard replaces the notes, so a footer would only be ignored.
+	if input.UseClipboard != "replace-notes" {
+		input.Notes = c.withProvenance(ctx, input.Notes)
+	}
+}
+
+func (c *Client) applyAddProjectDefaults(ctx context.Context, input *AddProjectInput) {
+	input.Tags = mergeTags(input.Tags, c.defaults.Tags)
+	input.Notes = c.withProvenance(ctx, input.Notes)
+}
+
+// applyJSONDefaults applies the item defaults to the create operations in a
+// compacted json command payload. Items are decoded generically so attributes
+// the server does not model pass through untouched. Only top-level items are
+// routed and signed; to-dos nested in a project are tagged.
+func (c *Client) applyJSONDefaults(ctx context.Context, data []byte) ([]byte, error) {
+	d := c.defaults
+	if len(d.Tags) == 0 && d.List == "" && d.ListID == "" && !d.Provenance {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var items []map[string]any
+	if err := dec.Decode(&items); err != nil {
+		return nil, fmt.Errorf("decode data: %w", err)
+	}
+
+	for _, item := range items {
+		attrs, ok := createAttributes(item)
+		if !ok {
+			continue
+		}
+		switch item["type"] {
+		case JSONToDo:
+			if attrs["list"] == nil && attrs["list-id"] == nil && (d.List != "" || d.ListID != "") {
+				setNonEmpty(attrs, "list", d.List)
+				setNonEmpty(attrs, "list-id", d.ListID)
+				if attrs["heading"] == nil && attrs["heading-id"] == nil {
+					setNonEmpty(attrs, "heading", d.Heading)
+				}
+			}
+		case JSONProject:
+			children, _ := attrs["items"].([]any)
+			for _, child := range children {
+				child, ok := child.(map[string]any)
+				if !ok || child["type"] != JSONToDo {
+					continue
+				}
+				if childAttrs, ok := createAttributes(child); ok {
+					mergeJSONTags(childAttrs, d.Tags)
+				}
+			}
+		default:
+			continue
+		}
+		mergeJSONTags(attrs, d.Tags)
+		notes, _ := attrs["notes"].(string)
+		setNonEmpty(attrs, "notes", c.withProvenance(ctx, notes))
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(items); err != nil {
+		return nil, fmt.Errorf("encode data: %w", err)
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// createAttributes returns the attributes of a create operation, adding an
+// empty attributes object when the item has none.
+func createAttributes(item map[string]any) (map[string]any, bool) {
+	if op, ok := item["operation"]; ok && op != JSONCreate {
+		return nil, false
+	}
+	attrs, ok := item["attributes"].(map[string]any)
+	if !ok {
+		if item["attributes"] != nil {
+			return nil, false
+		}
+		attrs = map[string]any{}
+		item["attributes"] = attrs
+	}
+	return attrs, true
+}
+
+func mergeJSONTags(attrs map[string]any, extra []string) {
+	if len(extra) == 0 {
+		return
+	}
+	raw, _ := attrs["tags"].([]any)
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		if tag, ok := tag.(string); ok {
+			tags = append(tags, tag)
+		}
+	}
+	attrs["tags"] = mergeTags(tags, extra)
+}
+
+func setNonEmpty(attrs map[string]any, key, value string) {
+	if value != "" {
+		attrs[key] = value
+	}
+}
+
+// withProvenance appends the provenance footer to notes when enabled.
+func (c *Client) withProvenance(ctx context.Context, notes string) string {
+	if !c.defaults.Provenance {
+		return notes
+	}
+	footer := "Created via things-mcp"
+	if name := clientName(ctx); name != "" {
+		footer = "Created by " + name + " via things-mcp"
+	}
+	footer += " on " + c.now().Format("2006-01-02 15:04 MST")
+	if notes == "" {
+		return footer
+	}
+	return strings.TrimRight(notes, "\n") + "\n\n" + footer
+}
+
+// mergeTags appends extra tags that are not already present, ignoring case.
+func mergeTags(tags, extra []string) []string {
+	out := tags
+	for _, tag := range extra {
+		if !slices.ContainsFunc(out, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			out = append(slices.Clip(out), tag)
+		}
+	}
+	return out
+}
+
+func (c *Client) defaultReveal(reveal *bool) *bool {
+	if reveal == nil {
+		return c.reveal
+	}
+	return reveal
+}
+
+func (c *Client) defaultAuthToken(token string) string {
+	if token == "" {
+		return c.authToken
+	}
+	return token
+}
@@ -0,0 +1,144 @@
+package things
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAddAppliesConfiguredDefaults(t *testing.T) {
+	launcher := &fakeLauncher{}
+	reveal := false
+	client := NewClient(Config{
+		Launcher: launcher,
+		Reveal:   &reveal,
+		Defaults: ItemDefaults{ListID: "inbox-project", Heading: "Agent", Tags: []string{"agent"}},
+	})
+
+	if _, err := client.Add(context.Background(), AddInput{Title: "Buy milk", Tags: []string{"Agent", "errand"}}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	want := "things:///add?heading=Agent&list-id=inbox-project&reveal=false&tags=Agent%2Cerrand&title=Buy%20milk"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("Add dispatched %q, want %q", got, want)
+	}
+}
+
+func TestAddKeepsExplicitList(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{
+		Launcher: launcher,
+		Defaults: ItemDefaults{List: "Agent Inbox", Heading: "Agent"},
+	})
+
+	if _, err := client.Add(context.Background(), AddInput{Title: "Buy milk", List: "Errands"}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	want := "things:///add?list=Errands&title=Buy%20milk"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("Add dispatched %q, want %q", got, want)
+	}
+}
+
+func TestDefaultAuthToken(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, AuthToken: "configured"})
+
+	title := "New title"
+	if _, err := client.Update(context.Background(), UpdateInput{ID: "todo-id", Title: &title}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	want := "things:///update?auth-token=configured&id=todo-id&title=New%20title"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("Update dispatched %q, want %q", got, want)
+	}
+}
+
+func TestAddAppendsProvenanceFooter(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Defaults: ItemDefaults{Provenance: true}})
+	client.now = func() time.Time { return time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC) }
+
+	ctx := WithClientName(context.Background(), "claude-code")
+	if _, err := client.Add(ctx, AddInput{Title: "Book flights", Notes: "Prefer morning"}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	want := "things:///add?notes=Prefer%20morning%0A%0ACreated%20by%20claude-code%20via%20things-mcp%20on%202025-03-04%2009%3A30%20UTC&title=Book%20flights"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("Add dispatched %q, want %q", got, want)
+	}
+}
+
+func TestAddProjectAppliesTagsAndFooter(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Defaults: ItemDefaults{Tags: []string{"agent"}, Provenance: true}})
+	client.now = func() time.Time { return time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC) }
+
+	res, err := client.AddProject(context.Background(), AddProjectInput{Title: "Trip"})
+	if err != nil {
+		t.Fatalf("AddProject returned error: %v", err)
+	}
+
+	want := "things:///add-project?notes=Created%20via%20things-mcp%20on%202025-03-04%2009%3A30%20UTC&tags=agent&title=Trip"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("AddProject dispatched %q, want %q", got, want)
+	}
+	if len(res.Tags) != 1 || res.Tags[0] != "agent" {
+		t.Fatalf("expected default tag in result, got %v", res.Tags)
+	}
+}
+
+func TestJSONAppliesDefaultsToCreates(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{
+		Launcher: launcher,
+		Defaults: ItemDefaults{ListID: "review-id", Heading: "Agent", Tags: []string{"agent"}},
+	})
+
+	data := `[
+		{"type":"to-do","attributes":{"title":"Loose","tags":["Home"]}},
+		{"type":"to-do","attributes":{"title":"Routed","list":"Errands"}},
+		{"type":"project","attributes":{"title":"Trip","items":[{"type":"heading","attributes":{"title":"Prep"}},{"type":"to-do","attributes":{"title":"Pack"}}]}},
+		{"type":"to-do","operation":"update","id":"abc","attributes":{"title":"Renamed"}}
+	]`
+	if _, err := client.JSON(context.Background(), JSONInput{Data: json.RawMessage(data)}); err != nil {
+		t.Fatalf("JSON returned error: %v", err)
+	}
+
+	u, err := url.Parse(launcher.calls[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []map[string]any
+	if err := json.Unmarshal([]byte(u.Query().Get("data")), &items); err != nil {
+		t.Fatalf("dispatched data is not JSON: %v", err)
+	}
+
+	attrs := func(i int) map[string]any { return items[i]["attributes"].(map[string]any) }
+	if got := attrs(0); got["list-id"] != "review-id" || got["heading"] != "Agent" || fmt.Sprint(got["tags"]) != "[Home agent]" {
+		t.Fatalf("loose to-do not routed and tagged: %v", got)
+	}
+	if got := attrs(1); got["list-id"] != nil || got["heading"] != nil || got["list"] != "Errands" {
+		t.Fatalf("explicit list overridden: %v", got)
+	}
+	project := attrs(2)
+	if fmt.Sprint(project["tags"]) != "[agent]" || project["list-id"] != nil {
+		t.Fatalf("project defaults wrong: %v", project)
+	}
+	children := project["items"].([]any)
+	if tags := children[1].(map[string]any)["attributes"].(map[string]any)["tags"]; fmt.Sprint(tags) != "[agent]" {
+		t.Fatalf("nested to-do not tagged: %v", tags)
+	}
+	if _, ok := children[0].(map[string]any)["attributes"].(map[string]any)["tags"]; ok {
+		t.Fatal("heading should not be tagged")
+	}
+	if got := attrs(3); got["tags"] != nil {
+		t.Fatalf("update operation should be left alone: %v", got)
+	}
+}
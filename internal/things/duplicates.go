@@ -0,0 +1,140 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Ways to handle an add that matches an open item.
+const (
+	DuplicateSkip   = "skip"
+	DuplicateWarn   = "warn"
+	DuplicateUpdate = "update"
+)
+
+// duplicateThreshold is the similarity above which two normalized titles are
+// considered the same item.
+const duplicateThreshold = 0.85
+
+// Duplicate reports a requested title that matched an open item and what was
+// done about it: skipped, warned, or updated.
+type Duplicate struct {
+	Title         string `json:"title"`
+	ID            string `json:"id"`
+	ExistingTitle string `json:"existingTitle"`
+	Action        string `json:"action"`
+}
+
+func validateDuplicateMode(mode string) error {
+	switch mode {
+	case "", DuplicateSkip, DuplicateWarn, DuplicateUpdate:
+		return nil
+	}
+	return fmt.Errorf("onDuplicate must be %s, %s, or %s", DuplicateSkip, DuplicateWarn, DuplicateUpdate)
+}
+
+// checkDuplicates compares titles with the open items of kind in container
+// and applies mode to the matches. It returns the titles that still need to
+// be created. The update mode appends notes to the existing item instead; a
+// match is skipped when there are no notes to append.
+func (c *Client) checkDuplicates(ctx context.Context, mode string, kind ItemType, container, list string, titles []string, notes string) ([]string, []Duplicate, error) {
+	if mode == "" || len(titles) == 0 {
+		return titles, nil, nil
+	}
+	if c.db == nil {
+		return nil, nil, errors.New("onDuplicate needs the Things database; start the server with -db")
+	}
+	if list != "" {
+		return nil, nil, fmt.Errorf("onDuplicate needs %q to resolve to an ID; configure a catalog or pass the ID", list)
+	}
+
+	open, err := c.db.OpenItems(ctx, kind, container)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var remaining []string
+	var dups []Duplicate
+	for _, title := range titles {
+		match, ok := closestItem(title, open)
+		if !ok {
+			remaining = append(remaining, title)
+			continue
+		}
+		dup := Duplicate{Title: title, ID: match.ID, ExistingTitle: match.Title}
+		switch {
+		case mode == DuplicateWarn:
+			dup.Action = "warned"
+			remaining = append(remaining, title)
+		case mode == DuplicateUpdate && notes != "":
+			if err := c.appendNotes(ctx, kind, match.ID, notes); err != nil {
+				return nil, nil, fmt.Errorf("update duplicate %q: %w", match.Title, err)
+			}
+			dup.Action = "updated"
+		default:
+			dup.Action = "skipped"
+		}
+		dups = append(dups, dup)
+	}
+	return remaining, dups, nil
+}
+
+func (c *Client) appendNotes(ctx context.Context, kind ItemType, id, notes string) error {
+	if kind == ItemProject {
+		_, err := c.UpdateProject(ctx, UpdateProjectInput{ID: id, AppendNotes: &notes})
+		return err
+	}
+	_, err := c.Update(ctx, UpdateInput{ID: id, AppendNotes: &notes})
+	return err
+}
+
+// duplicateWarnings describes warned duplicates for the result.
+func duplicateWarnings(dups []Duplicate) []Warning {
+	var warnings []Warning
+	for _, dup := range dups {
+		if dup.Action == "warned" {
+			warnings = append(warnings, Warning{
+				Field:   "title",
+				Message: fmt.Sprintf("%q looks like a duplicate of open item %q (%s)", dup.Title, dup.ExistingTitle, dup.ID),
+			})
+		}
+	}
+	return warnings
+}
+
+// closestItem returns the open item whose title is most similar to title,
+// if any is similar enough.
+func closestItem(title string, items []OpenItem) (OpenItem, bool) {
+	needle := normalizeTitle(title)
+	var best OpenItem
+	bestScore := 0.0
+	for _, item := range items {
+		if score := titleSimilarity(needle, normalizeTitle(item.Title)); score > bestScore {
+			best, bestScore = item, score
+		}
+	}
+	return best, bestScore >= duplicateThreshold
+}
+
+// normalizeTitle lowercases a title and reduces punctuation and runs of
+// whitespace to single spaces.
+func normalizeTitle(title string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, title)
+	return strings.Join(strings.Fields(mapped), " ")
+}
+
+func titleSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	longest := max(len([]rune(a)), len([]rune(b)))
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
@@ -0,0 +1,196 @@
+package things
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func duplicateFixture(t *testing.T) *DB {
+	t.Helper()
+	return newFixtureDB(t,
+		`INSERT INTO TMArea (uuid, title) VALUES ('area-home', 'Home')`,
+		`INSERT INTO TMTask (uuid, type, title, area) VALUES ('proj-trip', 1, 'Trip', 'area-home')`,
+		`INSERT INTO TMTask (uuid, type, title, project) VALUES ('head-prep', 2, 'Prep', 'proj-trip')`,
+		`INSERT INTO TMTask (uuid, type, title, project) VALUES ('todo-flights', 0, 'Book flights!', 'proj-trip')`,
+		`INSERT INTO TMTask (uuid, type, title, heading) VALUES ('todo-pack', 0, 'Pack bags', 'head-prep')`,
+		`INSERT INTO TMTask (uuid, type, title, project, status) VALUES ('todo-visa', 0, 'Apply for visa', 'proj-trip', 3)`,
+		`INSERT INTO TMTask (uuid, type, title) VALUES ('todo-inbox', 0, 'Call mom')`,
+	)
+}
+
+func TestDBOpenItemsScopesToContainer(t *testing.T) {
+	db := duplicateFixture(t)
+
+	items, err := db.OpenItems(context.Background(), ItemToDo, "proj-trip")
+	if err != nil {
+		t.Fatalf("OpenItems returned error: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != "todo-flights" || items[1].ID != "todo-pack" {
+		t.Fatalf("unexpected project items: %+v", items)
+	}
+
+	items, err = db.OpenItems(context.Background(), ItemToDo, "")
+	if err != nil {
+		t.Fatalf("OpenItems returned error: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != "todo-inbox" {
+		t.Fatalf("unexpected unfiled items: %+v", items)
+	}
+
+	items, err = db.OpenItems(context.Background(), ItemProject, "area-home")
+	if err != nil {
+		t.Fatalf("OpenItems returned error: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != "proj-trip" {
+		t.Fatalf("unexpected area projects: %+v", items)
+	}
+}
+
+func TestAddSkipsDuplicates(t *testing.T) {
+	db := duplicateFixture(t)
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: db, Catalog: db})
+
+	res, err := client.Add(context.Background(), AddInput{
+		Titles:      []string{"book flight", "Rent car", "Apply for visa"},
+		List:        "Trip",
+		OnDuplicate: DuplicateSkip,
+	})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	want := "things:///add?list-id=proj-trip&titles=Rent%20car%0AApply%20for%20visa"
+	if len(launcher.calls) != 1 || launcher.calls[0] != want {
+		t.Fatalf("Add dispatched %v, want %q", launcher.calls, want)
+	}
+	if len(res.Duplicates) != 1 || res.Duplicates[0].ID != "todo-flights" || res.Duplicates[0].Action != "skipped" {
+		t.Fatalf("unexpected duplicates: %+v", res.Duplicates)
+	}
+}
+
+func TestAddSkipsWithoutDispatchWhenAllDuplicates(t *testing.T) {
+	db := duplicateFixture(t)
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: db})
+
+	res, err := client.Add(context.Background(), AddInput{Title: "Call Mom", OnDuplicate: DuplicateSkip})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if len(launcher.calls) != 0 || res.URL != "" || len(res.Duplicates) != 1 {
+		t.Fatalf("expected no dispatch, got %v and %+v", launcher.calls, res)
+	}
+}
+
+func TestAddWarnsOnDuplicates(t *testing.T) {
+	db := duplicateFixture(t)
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: db})
+
+	res, err := client.Add(context.Background(), AddInput{Title: "Pack bags", ListID: "proj-trip", OnDuplicate: DuplicateWarn})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if len(launcher.calls) != 1 {
+		t.Fatalf("expected the to-do to be added anyway, got %v", launcher.calls)
+	}
+	if fields := warningFields(res.Warnings); len(fields) != 1 || fields[0] != "title" {
+		t.Fatalf("expected duplicate warning, got %v", res.Warnings)
+	}
+}
+
+func TestAddConvertsDuplicateToUpdate(t *testing.T) {
+	db := duplicateFixture(t)
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: db, AuthToken: "token"})
+
+	res, err := client.Add(context.Background(), AddInput{
+		Title:       "Book  Flights",
+		Notes:       "Aisle seat",
+		ListID:      "proj-trip",
+		OnDuplicate: DuplicateUpdate,
+	})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	want := "things:///update?append-notes=Aisle%20seat&auth-token=token&id=todo-flights"
+	if len(launcher.calls) != 1 || launcher.calls[0] != want {
+		t.Fatalf("Add dispatched %v, want %q", launcher.calls, want)
+	}
+	if res.Duplicates[0].Action != "updated" {
+		t.Fatalf("unexpected duplicates: %+v", res.Duplicates)
+	}
+}
+
+func TestAddUpdateModeLeavesProvenanceOffDuplicates(t *testing.T) {
+	db := duplicateFixture(t)
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: db, AuthToken: "token", Defaults: ItemDefaults{Provenance: true}})
+
+	res, err := client.Add(context.Background(), AddInput{
+		Titles:      []string{"Book flights", "Rent car"},
+		ListID:      "proj-trip",
+		OnDuplicate: DuplicateUpdate,
+	})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if len(launcher.calls) != 1 || !strings.HasPrefix(launcher.calls[0], "things:///add?") {
+		t.Fatalf("Add dispatched %v, want only the new to-do", launcher.calls)
+	}
+	if !strings.Contains(launcher.calls[0], "notes=") {
+		t.Errorf("created to-do has no provenance footer: %s", launcher.calls[0])
+	}
+	if res.Duplicates[0].Action != "skipped" {
+		t.Fatalf("unexpected duplicates: %+v", res.Duplicates)
+	}
+}
+
+func TestAddProjectSkipsDuplicate(t *testing.T) {
+	db := duplicateFixture(t)
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: db})
+
+	res, err := client.AddProject(context.Background(), AddProjectInput{Title: "trip", AreaID: "area-home", OnDuplicate: DuplicateSkip})
+	if err != nil {
+		t.Fatalf("AddProject returned error: %v", err)
+	}
+	if len(launcher.calls) != 0 || res.ID != "proj-trip" {
+		t.Fatalf("expected existing project, got %v and %+v", launcher.calls, res)
+	}
+}
+
+func TestDuplicateCheckNeedsDatabase(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}})
+
+	_, err := client.Add(context.Background(), AddInput{Title: "Call mom", OnDuplicate: DuplicateSkip})
+	if err == nil || !strings.Contains(err.Error(), "needs the Things database") {
+		t.Fatalf("expected database error, got %v", err)
+	}
+	_, err = client.Add(context.Background(), AddInput{Title: "Call mom", OnDuplicate: "merge"})
+	if err == nil || !strings.Contains(err.Error(), "onDuplicate must be") {
+		t.Fatalf("expected mode error, got %v", err)
+	}
+}
+
+func TestNormalizedTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Book flights", "book flights!", true},
+		{"Book flight", "Book flights", true},
+		{"Book  -  flights", "book flights", true},
+		{"Book hotel", "Book flights", false},
+		{"Call mom", "Call dad", false},
+	}
+	for _, tt := range tests {
+		_, got := closestItem(tt.a, []OpenItem{{ID: "x", Title: tt.b}})
+		if got != tt.want {
+			t.Errorf("closestItem(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
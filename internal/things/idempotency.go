@@ -0,0 +1,239 @@
+package things
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/moonbase/things-mcp/internal/filelock"
+)
+
+const idempotencyVersion = 1
+
+// IdempotencyStore remembers the results of create calls by idempotency key
+// so a retried call returns the original result instead of creating the items
+// again. Entries expire after the store's TTL. The store is persisted as a
+// JSON file shared by every server process: it is re-read under a lock
+// before each lookup and merged before each write, so processes keep each
+// other's keys.
+type IdempotencyStore struct {
+	mu       sync.Mutex
+	path     string
+	ttl      time.Duration
+	now      func() time.Time
+	entries  map[string]idempotencyEntry
+	inFlight map[string]bool
+}
+
+type idempotencyEntry struct {
+	Command   string          `json:"command"`
+	Hash      string          `json:"hash"`
+	Result    json.RawMessage `json:"result"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type idempotencyFile struct {
+	Version int                         `json:"version"`
+	Entries map[string]idempotencyEntry `json:"entries"`
+}
+
+// OpenIdempotencyStore loads the store at path, creating it on first write.
+// An empty path keeps entries in memory only.
+func OpenIdempotencyStore(path string, ttl time.Duration) (*IdempotencyStore, error) {
+	if ttl <= 0 {
+		return nil, errors.New("idempotency ttl must be positive")
+	}
+	s := &IdempotencyStore{
+		path:     path,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  map[string]idempotencyEntry{},
+		inFlight: map[string]bool{},
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.prune()
+	return s, nil
+}
+
+// begin looks up key. It returns the stored entry when there is one, and
+// otherwise marks the key as in flight until finish is called.
+func (s *IdempotencyStore) begin(key string) (idempotencyEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.update(nil); err != nil {
+		return idempotencyEntry{}, false, err
+	}
+	if entry, ok := s.entries[key]; ok {
+		return entry, true, nil
+	}
+	if s.inFlight[key] {
+		return idempotencyEntry{}, false, fmt.Errorf("a call with idempotencyKey %q is still in progress", key)
+	}
+	s.inFlight[key] = true
+	return idempotencyEntry{}, false, nil
+}
+
+// finish clears the in-flight mark and records entry when it is not nil.
+func (s *IdempotencyStore) finish(key string, entry *idempotencyEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inFlight, key)
+	if entry == nil {
+		return nil
+	}
+	entry.CreatedAt = s.now()
+	return s.update(func() { s.entries[key] = *entry })
+}
+
+// update merges the file into the entries while holding its lock, then
+// applies change, when set, and writes the result back.
+func (s *IdempotencyStore) update(change func()) error {
+	if s.path == "" {
+		if change != nil {
+			change()
+		}
+		s.prune()
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("write idempotency store: %w", err)
+	}
+	unlock, err := filelock.Lock(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("lock idempotency store: %w", err)
+	}
+	defer unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.prune()
+	if change == nil {
+		return nil
+	}
+	change()
+	return s.save()
+}
+
+// load merges the entries on disk into s, keeping the newer of two entries
+// for the same key.
+func (s *IdempotencyStore) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read idempotency store: %w", err)
+	}
+	var file idempotencyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parse idempotency store %s: %w", s.path, err)
+	}
+	if file.Version != idempotencyVersion {
+		return fmt.Errorf("idempotency store %s has unsupported version %d", s.path, file.Version)
+	}
+	for key, entry := range file.Entries {
+		if current, ok := s.entries[key]; !ok || entry.CreatedAt.After(current.CreatedAt) {
+			s.entries[key] = entry
+		}
+	}
+	return nil
+}
+
+func (s *IdempotencyStore) prune() {
+	cutoff := s.now().Add(-s.ttl)
+	for key, entry := range s.entries {
+		if entry.CreatedAt.Before(cutoff) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// save writes the store atomically so a crash never leaves a torn file. The
+// caller holds the file lock.
+func (s *IdempotencyStore) save() error {
+	data, err := json.MarshalIndent(idempotencyFile{Version: idempotencyVersion, Entries: s.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write idempotency store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write idempotency store: %w", err)
+	}
+	return nil
+}
+
+// idempotent runs call once per key. A repeat with the same command and
+// payload returns the stored result marked as replayed; a repeat with a
+// different payload is rejected. Failed calls and dry runs are not stored, so
+// they can be retried under the same key.
+func idempotent[R any](c *Client, command, key string, payload any, call func() (R, error)) (R, error) {
+	var zero R
+	if key == "" || c.idempotency == nil {
+		return call()
+	}
+
+	hash, err := payloadHash(command, payload)
+	if err != nil {
+		return zero, err
+	}
+	entry, found, err := c.idempotency.begin(key)
+	if err != nil {
+		return zero, err
+	}
+	if found {
+		if entry.Command != command || entry.Hash != hash {
+			return zero, fmt.Errorf("idempotencyKey %q was already used for a different %s payload; use a new key for new items", key, entry.Command)
+		}
+		var res R
+		if err := json.Unmarshal(entry.Result, &res); err != nil {
+			return zero, fmt.Errorf("decode stored result for idempotencyKey %q: %w", key, err)
+		}
+		if d, ok := any(&res).(interface{ base() *Dispatch }); ok {
+			d.base().Replayed = true
+		}
+		return res, nil
+	}
+
+	res, err := call()
+	if err != nil || c.dryRun {
+		return res, errors.Join(err, c.idempotency.finish(key, nil))
+	}
+	result, err := json.Marshal(res)
+	if err != nil {
+		return res, errors.Join(err, c.idempotency.finish(key, nil))
+	}
+	// The items were created, so a failure to persist the key is reported as
+	// a warning rather than a failed call the agent would retry.
+	if err := c.idempotency.finish(key, &idempotencyEntry{Command: command, Hash: hash, Result: result}); err != nil {
+		if d, ok := any(&res).(interface{ base() *Dispatch }); ok {
+			d.base().Warnings = append(d.base().Warnings, Warning{Field: "idempotencyKey", Message: "was not saved: " + err.Error()})
+		}
+	}
+	return res, nil
+}
+
+func payloadHash(command string, payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("hash payload: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(command+"\n"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
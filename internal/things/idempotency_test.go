@@ -0,0 +1,131 @@
+package things
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newIdempotentClient(t *testing.T, launcher Launcher, path string) *Client {
+	t.Helper()
+	store, err := OpenIdempotencyStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenIdempotencyStore returned error: %v", err)
+	}
+	return NewClient(Config{Launcher: launcher, Idempotency: store})
+}
+
+func TestAddReplaysIdempotentRetry(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := newIdempotentClient(t, launcher, "")
+	input := AddInput{Title: "Book flights", IdempotencyKey: "trip-1"}
+
+	first, err := client.Add(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	second, err := client.Add(context.Background(), input)
+	if err != nil {
+		t.Fatalf("retried Add returned error: %v", err)
+	}
+
+	if len(launcher.calls) != 1 {
+		t.Fatalf("expected a single dispatch, got %d", len(launcher.calls))
+	}
+	if first.Replayed || !second.Replayed || second.URL != first.URL {
+		t.Fatalf("unexpected results: first %+v, second %+v", first, second)
+	}
+}
+
+func TestAddRejectsReusedKeyWithDifferentPayload(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := newIdempotentClient(t, launcher, "")
+
+	if _, err := client.Add(context.Background(), AddInput{Title: "Book flights", IdempotencyKey: "trip-1"}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	_, err := client.Add(context.Background(), AddInput{Title: "Book hotel", IdempotencyKey: "trip-1"})
+	if err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Fatalf("expected reused key error, got %v", err)
+	}
+	_, err = client.AddProject(context.Background(), AddProjectInput{Title: "Book flights", IdempotencyKey: "trip-1"})
+	if err == nil || !strings.Contains(err.Error(), "different add payload") {
+		t.Fatalf("expected reused key error across commands, got %v", err)
+	}
+	if len(launcher.calls) != 1 {
+		t.Fatalf("expected a single dispatch, got %d", len(launcher.calls))
+	}
+}
+
+func TestFailedCallsCanBeRetried(t *testing.T) {
+	launcher := &fakeLauncher{err: context.DeadlineExceeded}
+	client := newIdempotentClient(t, launcher, "")
+	input := JSONInput{Data: json.RawMessage(`[{"type":"to-do","attributes":{"title":"Pack"}}]`), IdempotencyKey: "pack"}
+
+	if _, err := client.JSON(context.Background(), input); err == nil {
+		t.Fatal("expected launcher error")
+	}
+	launcher.err = nil
+	res, err := client.JSON(context.Background(), input)
+	if err != nil {
+		t.Fatalf("retried JSON returned error: %v", err)
+	}
+	if res.Replayed || len(launcher.calls) != 2 {
+		t.Fatalf("expected the retry to dispatch, got %+v after %d calls", res, len(launcher.calls))
+	}
+}
+
+func TestIdempotencyStorePersistsAndExpires(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "idempotency.json")
+	launcher := &fakeLauncher{}
+	client := newIdempotentClient(t, launcher, path)
+	input := AddProjectInput{Title: "Trip", IdempotencyKey: "trip"}
+
+	if _, err := client.AddProject(context.Background(), input); err != nil {
+		t.Fatalf("AddProject returned error: %v", err)
+	}
+
+	restarted := newIdempotentClient(t, launcher, path)
+	res, err := restarted.AddProject(context.Background(), input)
+	if err != nil {
+		t.Fatalf("AddProject after restart returned error: %v", err)
+	}
+	if !res.Replayed || res.Title != "Trip" || len(launcher.calls) != 1 {
+		t.Fatalf("expected replay after restart, got %+v after %d calls", res, len(launcher.calls))
+	}
+
+	restarted.idempotency.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if res, err = restarted.AddProject(context.Background(), input); err != nil || res.Replayed {
+		t.Fatalf("expected expired key to dispatch again, got %+v, %v", res, err)
+	}
+}
+
+func TestIdempotencyStoreSharesKeysBetweenProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	launcher := &fakeLauncher{}
+	first := newIdempotentClient(t, launcher, path)
+	second := newIdempotentClient(t, launcher, path)
+
+	// Each server records a key after both loaded the empty store.
+	if _, err := first.Add(context.Background(), AddInput{Title: "Book flights", IdempotencyKey: "flights"}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if _, err := second.Add(context.Background(), AddInput{Title: "Book hotel", IdempotencyKey: "hotel"}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	for name, client := range map[string]*Client{"first": first, "second": second} {
+		for _, input := range []AddInput{{Title: "Book flights", IdempotencyKey: "flights"}, {Title: "Book hotel", IdempotencyKey: "hotel"}} {
+			res, err := client.Add(context.Background(), input)
+			if err != nil || !res.Replayed {
+				t.Errorf("%s server retrying %s: %+v, %v", name, input.IdempotencyKey, res, err)
+			}
+		}
+	}
+	if len(launcher.calls) != 2 {
+		t.Fatalf("dispatched %d times, want 2", len(launcher.calls))
+	}
+}
@@ -0,0 +1,208 @@
+package things
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ImportOptions route and dispatch the items produced by an importer.
+type ImportOptions struct {
+	Area    string `json:"area,omitempty" jsonschema:"area for imported projects that do not name one"`
+	AreaID  string `json:"areaId,omitempty"`
+	List    string `json:"list,omitempty" jsonschema:"project or area for imported to-dos that are not inside a project"`
+	ListID  string `json:"listId,omitempty"`
+	Preview bool   `json:"preview,omitempty" jsonschema:"return the generated json command payload without dispatching it"`
+	Reveal  *bool  `json:"reveal,omitempty"`
+}
+
+// ImportInput is a batch of json command items built by an importer.
+type ImportInput struct {
+	ImportOptions
+	Items []JSONItem
+	// Resume skips the chunks an interrupted import already dispatched.
+	Resume int
+	// Raw sends Items as given: items carrying a things-mcp-id marker are
+	// created rather than updated, so an import resumed after a partial run
+	// splits into the same chunks as the first.
+	Raw bool
+	// Progress, when set, is called after each chunk is dispatched with the
+	// number of chunks done so far and the items the chunk held. An error
+	// stops the import.
+	Progress func(done, total int, chunk []JSONItem) error
+}
+
+// ImportResult reports what an import generated and, unless previewing, how
+// it was dispatched.
+type ImportResult struct {
+	Preview        bool            `json:"preview,omitempty"`
+	Data           json.RawMessage `json:"data" jsonschema:"the json command payload, which can be passed to things-json"`
+	Projects       int             `json:"projects"`
+	Headings       int             `json:"headings"`
+	ToDos          int             `json:"toDos"`
+	ChecklistItems int             `json:"checklistItems"`
+	Updated        int             `json:"updated,omitempty" jsonschema:"existing to-dos that are updated, either by update items or because an earlier import created them"`
+	Dispatches     []Dispatch      `json:"dispatches,omitempty" jsonschema:"one json command per chunk of at most 250 items"`
+	Warnings       []string        `json:"warnings,omitempty"`
+	// ProjectIDs lists the IDs of created top-level projects, in order, when
+	// the database is available.
+	ProjectIDs []string `json:"projectIds,omitempty"`
+}
+
+// Things accepts at most 250 items per json command every ten seconds.
+const (
+	jsonChunkItems    = 250
+	jsonChunkInterval = 10 * time.Second
+)
+
+// Import routes top-level items to the requested area or list and creates
+// them through the json command, split into chunks Things will accept.
+func (c *Client) Import(ctx context.Context, input ImportInput) (ImportResult, error) {
+	if len(input.Items) == 0 {
+		return ImportResult{}, errors.New("nothing to import")
+	}
+	if err := c.routeImport(ctx, &input); err != nil {
+		return ImportResult{}, err
+	}
+	var warnings []string
+	if !input.Raw {
+		if c.db == nil && hasMarkers(input.Items) {
+			warnings = append(warnings, "without the Things database earlier imports cannot be found, so they are created again; start the server with -db")
+		}
+		if err := c.updateMarked(ctx, input.Items); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	data, err := json.Marshal(input.Items)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("encode items: %w", err)
+	}
+	res := ImportResult{Preview: input.Preview, Data: data, Warnings: warnings}
+	countItems(&res, input.Items)
+	if input.Preview {
+		return res, nil
+	}
+	// Things rejects any update without a token, whether the caller asked
+	// for it or a marker matched an earlier import.
+	if res.Updated > 0 && c.defaultAuthToken("") == "" {
+		return res, errors.New("updating existing to-dos needs an auth token; configure authToken")
+	}
+
+	started := time.Now()
+	chunks := chunkItems(input.Items, jsonChunkItems)
+	for i, chunk := range chunks {
+		if i < input.Resume {
+			continue
+		}
+		// A dry run sends nothing, so there is nothing to pace.
+		if i > input.Resume && !c.dryRun {
+			if err := c.wait(ctx, jsonChunkInterval); err != nil {
+				return res, err
+			}
+		}
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return res, fmt.Errorf("encode items: %w", err)
+		}
+		out, err := c.JSON(ctx, JSONInput{Data: data, Reveal: input.Reveal})
+		if err != nil {
+			return res, fmt.Errorf("dispatch chunk %d of %d (earlier chunks were sent): %w", i+1, len(chunks), err)
+		}
+		res.Dispatches = append(res.Dispatches, out.Dispatch)
+		if input.Progress != nil {
+			if err := input.Progress(i+1, len(chunks), chunk); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	var projects []string
+	for _, item := range input.Items {
+		if item.Type == JSONProject && item.Operation != JSONUpdate {
+			projects = append(projects, item.Attributes.Title)
+		}
+	}
+	res.ProjectIDs = c.lookupCreated(ctx, ItemProject, projects, started)
+	return res, nil
+}
+
+// chunkItems splits items into batches of at most limit items, counting
+// nested items and checklist items. An item larger than limit is sent alone.
+func chunkItems(items []JSONItem, limit int) [][]JSONItem {
+	var chunks [][]JSONItem
+	var chunk []JSONItem
+	size := 0
+	for _, item := range items {
+		n := itemCount(item)
+		if len(chunk) > 0 && size+n > limit {
+			chunks = append(chunks, chunk)
+			chunk, size = nil, 0
+		}
+		chunk = append(chunk, item)
+		size += n
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func itemCount(item JSONItem) int {
+	n := 1 + len(item.Attributes.ChecklistItems)
+	for _, child := range item.Attributes.Items {
+		n += itemCount(child)
+	}
+	return n
+}
+
+func (c *Client) routeImport(ctx context.Context, input *ImportInput) error {
+	opts := input.ImportOptions
+	if _, err := c.resolveArea(ctx, &opts.Area, &opts.AreaID); err != nil {
+		return err
+	}
+	list := AddInput{List: opts.List, ListID: opts.ListID}
+	if _, err := c.resolveAdd(ctx, &list); err != nil {
+		return err
+	}
+
+	for i := range input.Items {
+		item := &input.Items[i]
+		attrs := &item.Attributes
+		if item.Operation == JSONUpdate {
+			continue
+		}
+		switch item.Type {
+		case JSONProject:
+			if attrs.Area == "" && attrs.AreaID == "" {
+				attrs.Area, attrs.AreaID = opts.Area, opts.AreaID
+			}
+		case JSONToDo:
+			if attrs.List == "" && attrs.ListID == "" {
+				attrs.List, attrs.ListID = list.List, list.ListID
+			}
+		}
+	}
+	return nil
+}
+
+func countItems(res *ImportResult, items []JSONItem) {
+	for _, item := range items {
+		if item.Operation == JSONUpdate {
+			res.Updated++
+			continue
+		}
+		switch item.Type {
+		case JSONProject:
+			res.Projects++
+		case JSONHeading:
+			res.Headings++
+		case JSONToDo:
+			res.ToDos++
+		}
+		res.ChecklistItems += len(item.Attributes.ChecklistItems)
+		countItems(res, item.Attributes.Items)
+	}
+}
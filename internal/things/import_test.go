@@ -0,0 +1,174 @@
+package things
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportPreviewDoesNotDispatch(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher})
+
+	res, err := client.Import(context.Background(), ImportInput{
+		ImportOptions: ImportOptions{AreaID: "area-work", ListID: "proj-inbox", Preview: true},
+		Items: []JSONItem{
+			{Type: JSONProject, Attributes: JSONAttributes{Title: "Launch", Items: []JSONItem{
+				{Type: JSONHeading, Attributes: JSONAttributes{Title: "QA"}},
+				{Type: JSONToDo, Attributes: JSONAttributes{Title: "Test", ChecklistItems: []JSONItem{
+					{Type: JSONChecklistItem, Attributes: JSONAttributes{Title: "iOS"}},
+				}}},
+			}}},
+			{Type: JSONToDo, Attributes: JSONAttributes{Title: "Loose"}},
+			{Type: JSONToDo, Attributes: JSONAttributes{Title: "Filed", ListID: "proj-other"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+
+	if len(launcher.calls) != 0 || len(res.Dispatches) != 0 {
+		t.Fatalf("preview dispatched: %v", launcher.calls)
+	}
+	if res.Projects != 1 || res.Headings != 1 || res.ToDos != 3 || res.ChecklistItems != 1 {
+		t.Fatalf("unexpected counts: %+v", res)
+	}
+	data := string(res.Data)
+	for _, want := range []string{`"area-id":"area-work"`, `"title":"Loose","list-id":"proj-inbox"`, `"title":"Filed","list-id":"proj-other"`} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("payload %s missing %s", data, want)
+		}
+	}
+}
+
+func TestImportDispatchesJSONCommand(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher})
+
+	res, err := client.Import(context.Background(), ImportInput{Items: []JSONItem{{Type: JSONToDo, Attributes: JSONAttributes{Title: "Pack"}}}})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	want := "things:///json?data=%5B%7B%22type%22%3A%22to-do%22%2C%22attributes%22%3A%7B%22title%22%3A%22Pack%22%7D%7D%5D"
+	if len(launcher.calls) != 1 || launcher.calls[0] != want || res.Dispatches[0].URL != want {
+		t.Fatalf("Import dispatched %v, want %q", launcher.calls, want)
+	}
+
+	if _, err := client.Import(context.Background(), ImportInput{}); err == nil {
+		t.Fatal("expected error for empty import")
+	}
+}
+
+func TestImportUpdatesNeedAuthToken(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher})
+	items := []JSONItem{{Type: JSONToDo, Operation: JSONUpdate, ID: "todo-1", Attributes: JSONAttributes{When: "today"}}}
+
+	res, err := client.Import(context.Background(), ImportInput{ImportOptions: ImportOptions{Preview: true}, Items: items})
+	if err != nil || res.Updated != 1 {
+		t.Fatalf("preview = %+v, %v", res, err)
+	}
+	if _, err := client.Import(context.Background(), ImportInput{Items: items}); err == nil || len(launcher.calls) != 0 {
+		t.Fatalf("Import without a token returned %v after %d dispatches", err, len(launcher.calls))
+	}
+
+	client = NewClient(Config{Launcher: launcher, AuthToken: "secret"})
+	if _, err := client.Import(context.Background(), ImportInput{Items: items}); err != nil || len(launcher.calls) != 1 {
+		t.Fatalf("Import with a token returned %v after %d dispatches", err, len(launcher.calls))
+	}
+}
+
+func TestImportChunksLargeBatches(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher})
+	var waits []time.Duration
+	client.wait = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	var items []JSONItem
+	for i := 0; i < 300; i++ {
+		items = append(items, JSONItem{Type: JSONToDo, Attributes: JSONAttributes{Title: fmt.Sprintf("To-do %d", i)}})
+	}
+	res, err := client.Import(context.Background(), ImportInput{Items: items})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+
+	if len(launcher.calls) != 2 || len(res.Dispatches) != 2 {
+		t.Fatalf("expected two chunks, got %d dispatches", len(launcher.calls))
+	}
+	if len(waits) != 1 || waits[0] != 10*time.Second {
+		t.Fatalf("expected one pause between chunks, got %v", waits)
+	}
+}
+
+func TestImportDryRunDoesNotWaitBetweenChunks(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DryRun: true})
+	client.wait = func(context.Context, time.Duration) error {
+		t.Fatal("dry run waited between chunks")
+		return nil
+	}
+
+	var items []JSONItem
+	for i := 0; i < 300; i++ {
+		items = append(items, JSONItem{Type: JSONToDo, Attributes: JSONAttributes{Title: fmt.Sprintf("To-do %d", i)}})
+	}
+	res, err := client.Import(context.Background(), ImportInput{Items: items})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if len(res.Dispatches) != 2 || len(launcher.calls) != 0 {
+		t.Fatalf("got %d dispatches and %d launches, want two chunks and nothing launched", len(res.Dispatches), len(launcher.calls))
+	}
+}
+
+func TestImportResumesAfterDispatchedChunks(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher})
+	var waits int
+	client.wait = func(context.Context, time.Duration) error {
+		waits++
+		return nil
+	}
+
+	var items []JSONItem
+	for i := 0; i < 600; i++ {
+		items = append(items, JSONItem{Type: JSONToDo, Attributes: JSONAttributes{Title: fmt.Sprintf("To-do %d", i)}})
+	}
+	var progress []string
+	_, err := client.Import(context.Background(), ImportInput{
+		Items:  items,
+		Resume: 1,
+		Progress: func(done, total int, chunk []JSONItem) error {
+			progress = append(progress, fmt.Sprintf("%d/%d:%d", done, total, len(chunk)))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if len(launcher.calls) != 2 || !strings.Contains(launcher.calls[0], "To-do%20250") {
+		t.Fatalf("expected chunks 2 and 3 to be sent, got %d calls", len(launcher.calls))
+	}
+	if got := strings.Join(progress, ","); got != "2/3:250,3/3:100" || waits != 1 {
+		t.Fatalf("progress %s with %d waits", got, waits)
+	}
+}
+
+func TestChunkItemsCountsNestedItems(t *testing.T) {
+	project := JSONItem{Type: JSONProject}
+	for i := 0; i < 200; i++ {
+		project.Attributes.Items = append(project.Attributes.Items, JSONItem{Type: JSONToDo})
+	}
+	loose := JSONItem{Type: JSONToDo, Attributes: JSONAttributes{ChecklistItems: make([]JSONItem, 60)}}
+
+	chunks := chunkItems([]JSONItem{project, loose, loose}, 250)
+	if len(chunks) != 2 || len(chunks[0]) != 1 || len(chunks[1]) != 2 {
+		t.Fatalf("unexpected chunks: %d", len(chunks))
+	}
+}
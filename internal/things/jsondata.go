@@ -0,0 +1,133 @@
+package things
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Item types and operations understood by the json command.
+const (
+	JSONToDo          = "to-do"
+	JSONProject       = "project"
+	JSONHeading       = "heading"
+	JSONChecklistItem = "checklist-item"
+
+	JSONCreate = "create"
+	JSONUpdate = "update"
+)
+
+// JSONItem is one object in the data array of the json command.
+type JSONItem struct {
+	Type       string         `json:"type"`
+	Operation  string         `json:"operation,omitempty"`
+	ID         string         `json:"id,omitempty"`
+	Attributes JSONAttributes `json:"attributes"`
+}
+
+// JSONAttributes covers the attributes of every JSON item type; Things
+// ignores the ones that do not apply.
+type JSONAttributes struct {
+	Title          string     `json:"title,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+	When           string     `json:"when,omitempty"`
+	Deadline       string     `json:"deadline,omitempty"`
+	Tags           []string   `json:"tags,omitempty"`
+	ChecklistItems []JSONItem `json:"checklist-items,omitempty"`
+	ListID         string     `json:"list-id,omitempty"`
+	List           string     `json:"list,omitempty"`
+	HeadingID      string     `json:"heading-id,omitempty"`
+	Heading        string     `json:"heading,omitempty"`
+	AreaID         string     `json:"area-id,omitempty"`
+	Area           string     `json:"area,omitempty"`
+	Completed      *bool      `json:"completed,omitempty"`
+	Canceled       *bool      `json:"canceled,omitempty"`
+	Archived       *bool      `json:"archived,omitempty"`
+	CreationDate   string     `json:"creation-date,omitempty"`
+	CompletionDate string     `json:"completion-date,omitempty"`
+	Items          []JSONItem `json:"items,omitempty"`
+
+	// Update-only attributes.
+	PrependNotes          string `json:"prepend-notes,omitempty"`
+	AppendNotes           string `json:"append-notes,omitempty"`
+	AddTags               string `json:"add-tags,omitempty"`
+	PrependChecklistItems string `json:"prepend-checklist-items,omitempty"`
+	AppendChecklistItems  string `json:"append-checklist-items,omitempty"`
+
+	// Clear lists attribute keys to send as null so the update empties them.
+	Clear []string `json:"-"`
+}
+
+// MarshalJSON writes cleared attributes as explicit nulls.
+func (a JSONAttributes) MarshalJSON() ([]byte, error) {
+	type plain JSONAttributes
+	data, err := json.Marshal(plain(a))
+	if err != nil || len(a.Clear) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range a.Clear {
+		fields[key] = json.RawMessage("null")
+	}
+	return json.Marshal(fields)
+}
+
+// UpdateItem builds the json command equivalent of an update call. Empty
+// strings are treated as clears, matching the URL scheme.
+func UpdateItem(input UpdateInput) (JSONItem, error) {
+	if input.ID == "" {
+		return JSONItem{}, errors.New("id is required")
+	}
+	if boolValue(input.Duplicate) {
+		return JSONItem{}, errors.New("duplicate is not supported by the json command")
+	}
+	cleared, err := clearParams(input.Clear, updateClearable(input))
+	if err != nil {
+		return JSONItem{}, err
+	}
+
+	attrs := JSONAttributes{
+		Tags:                  input.Tags,
+		AddTags:               strings.Join(input.AddTags, ","),
+		PrependChecklistItems: joinLines(input.PrependChecklistItems),
+		AppendChecklistItems:  joinLines(input.AppendChecklistItems),
+		Completed:             input.Completed,
+		Canceled:              input.Canceled,
+		Clear:                 cleared,
+	}
+	for _, title := range input.ChecklistItems {
+		attrs.ChecklistItems = append(attrs.ChecklistItems, JSONItem{
+			Type:       JSONChecklistItem,
+			Attributes: JSONAttributes{Title: title},
+		})
+	}
+	setAttribute(&attrs.Title, &attrs.Clear, "title", input.Title)
+	setAttribute(&attrs.Notes, &attrs.Clear, "notes", input.Notes)
+	setAttribute(&attrs.PrependNotes, &attrs.Clear, "prepend-notes", input.PrependNotes)
+	setAttribute(&attrs.AppendNotes, &attrs.Clear, "append-notes", input.AppendNotes)
+	setAttribute(&attrs.When, &attrs.Clear, "when", normalizeOptional(input.When, normalizeWhen))
+	setAttribute(&attrs.Deadline, &attrs.Clear, "deadline", normalizeOptional(input.Deadline, normalizeDate))
+	setAttribute(&attrs.List, &attrs.Clear, "list", input.List)
+	setAttribute(&attrs.ListID, &attrs.Clear, "list-id", input.ListID)
+	setAttribute(&attrs.Heading, &attrs.Clear, "heading", input.Heading)
+	setAttribute(&attrs.HeadingID, &attrs.Clear, "heading-id", input.HeadingID)
+	setAttribute(&attrs.CreationDate, &attrs.Clear, "creation-date", input.CreationDate)
+	setAttribute(&attrs.CompletionDate, &attrs.Clear, "completion-date", input.CompletionDate)
+
+	return JSONItem{Type: JSONToDo, Operation: JSONUpdate, ID: input.ID, Attributes: attrs}, nil
+}
+
+func setAttribute(dst *string, clear *[]string, key string, value *string) {
+	if value == nil {
+		return
+	}
+	if *value == "" {
+		*clear = append(*clear, key)
+		return
+	}
+	*dst = *value
+}
@@ -0,0 +1,54 @@
+package things
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUpdateItemMarshalsClearsAsNull(t *testing.T) {
+	empty, when := "", "Tomorrow"
+	item, err := UpdateItem(UpdateInput{
+		ID:       "todo-id",
+		When:     &when,
+		Deadline: &empty,
+		Clear:    []string{"tags"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateItem returned error: %v", err)
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	want := `{"type":"to-do","operation":"update","id":"todo-id","attributes":{"deadline":null,"tags":null,"when":"tomorrow"}}`
+	if string(data) != want {
+		t.Fatalf("marshaled %s, want %s", data, want)
+	}
+}
+
+func TestUpdateItemRejectsDuplicate(t *testing.T) {
+	yes := true
+	if _, err := UpdateItem(UpdateInput{ID: "todo-id", Duplicate: &yes}); err == nil {
+		t.Fatalf("expected error for duplicate")
+	}
+}
+
+func TestJSONItemOmitsEmptyAttributes(t *testing.T) {
+	data, err := json.Marshal(JSONItem{
+		Type: JSONProject,
+		Attributes: JSONAttributes{
+			Title: "Trip",
+			Items: []JSONItem{{Type: JSONHeading, Attributes: JSONAttributes{Title: "Sights"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	want := `{"type":"project","attributes":{"title":"Trip","items":[{"type":"heading","attributes":{"title":"Sights"}}]}}`
+	if string(data) != want {
+		t.Fatalf("marshaled %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,149 @@
+package things
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LintError is returned in strict mode when a call contains parameters Things
+// would silently ignore.
+type LintError struct {
+	Warnings []Warning
+}
+
+func (e *LintError) Error() string {
+	parts := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		parts[i] = w.Field + " " + w.Message
+	}
+	return "strict mode: " + strings.Join(parts, "; ")
+}
+
+// checkLint turns warnings into an error when the client runs in strict mode.
+func (c *Client) checkLint(warnings []Warning) error {
+	if c.strict && len(warnings) > 0 {
+		return &LintError{Warnings: warnings}
+	}
+	return nil
+}
+
+type linter struct {
+	warnings []Warning
+}
+
+func (l *linter) warn(cond bool, field, format string, args ...any) {
+	if cond {
+		l.warnings = append(l.warnings, Warning{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+func (l *linter) status(completed, canceled *bool) {
+	l.warn(boolValue(canceled) && completed != nil, "completed", "ignored because canceled is true")
+}
+
+func (l *linter) when(value string) {
+	date, _, hasTime := strings.Cut(value, "@")
+	date = strings.ToLower(strings.TrimSpace(date))
+	l.warn(hasTime && (date == "anytime" || date == "someday"), "when", "time is ignored for %s", date)
+}
+
+// isoLayouts are the ISO8601 forms accepted for creation and completion
+// dates. Those without a zone are in local time.
+var isoLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func (l *linter) pastDate(field, value string) {
+	if value == "" {
+		return
+	}
+	for _, layout := range isoLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			l.warn(t.After(time.Now()), field, "is in the future and will be ignored")
+			return
+		}
+	}
+	l.warn(true, field, "is not a date (2006-01-02) or date time (2006-01-02T15:04, optionally with seconds and a zone) and will be ignored")
+}
+
+func lintAdd(input AddInput) []Warning {
+	var l linter
+	hasTitles := len(input.Titles) > 0
+	quickEntry := boolValue(input.ShowQuickEntry)
+
+	l.warn(hasTitles && input.Title != "", "title", "ignored because titles is set")
+	l.warn(hasTitles && quickEntry, "showQuickEntry", "ignored because titles is set")
+	l.warn(quickEntry && !hasTitles && input.Reveal != nil, "reveal", "ignored because showQuickEntry is set")
+	l.warn(input.ListID != "" && input.List != "", "list", "ignored because listId is set")
+	l.warn(input.HeadingID != "" && input.Heading != "", "heading", "ignored because headingId is set")
+	l.warn((input.Heading != "" || input.HeadingID != "") && input.List == "" && input.ListID == "",
+		"heading", "ignored because no project list is set")
+	switch input.UseClipboard {
+	case "replace-title":
+		l.warn(input.Title != "" || hasTitles, "title", "ignored because useClipboard is replace-title")
+	case "replace-notes":
+		l.warn(input.Notes != "", "notes", "ignored because useClipboard is replace-notes")
+	case "replace-checklist-items":
+		l.warn(len(input.ChecklistItems) > 0, "checklistItems", "ignored because useClipboard is replace-checklist-items")
+	}
+	l.warn(len(input.ChecklistItems) > 100, "checklistItems", "only the first 100 items are kept")
+	l.status(input.Completed, input.Canceled)
+	l.warn(input.CompletionDate != "" && !boolValue(input.Completed) && !boolValue(input.Canceled),
+		"completionDate", "ignored because the to-do is not completed or canceled")
+	l.when(input.When)
+	l.pastDate("creationDate", input.CreationDate)
+	l.pastDate("completionDate", input.CompletionDate)
+	return l.warnings
+}
+
+func lintAddProject(input AddProjectInput) []Warning {
+	var l linter
+	l.warn(input.AreaID != "" && input.Area != "", "area", "ignored because areaId is set")
+	l.status(input.Completed, input.Canceled)
+	l.warn(input.CompletionDate != "" && !boolValue(input.Completed) && !boolValue(input.Canceled),
+		"completionDate", "ignored because the project is not completed or canceled")
+	l.when(input.When)
+	l.pastDate("creationDate", input.CreationDate)
+	l.pastDate("completionDate", input.CompletionDate)
+	return l.warnings
+}
+
+func lintUpdate(input UpdateInput) []Warning {
+	var l linter
+	l.warn(input.ListID != nil && input.List != nil, "list", "ignored because listId is set")
+	l.warn(input.HeadingID != nil && input.Heading != nil, "heading", "ignored because headingId is set")
+	l.warn(len(input.ChecklistItems) > 100, "checklistItems", "only the first 100 items are kept")
+	l.status(input.Completed, input.Canceled)
+	// The item may already be completed, so completionDate is only flagged
+	// when the call itself reopens it.
+	l.warn(input.CompletionDate != nil && input.Completed != nil && !*input.Completed && !boolValue(input.Canceled),
+		"completionDate", "ignored because completed is false")
+	l.when(stringValue(input.When))
+	l.pastDate("creationDate", stringValue(input.CreationDate))
+	l.pastDate("completionDate", stringValue(input.CompletionDate))
+	return l.warnings
+}
+
+func lintUpdateProject(input UpdateProjectInput) []Warning {
+	var l linter
+	l.warn(input.AreaID != nil && input.Area != nil, "area", "ignored because areaId is set")
+	l.status(input.Completed, input.Canceled)
+	l.warn(input.CompletionDate != nil && input.Completed != nil && !*input.Completed && !boolValue(input.Canceled),
+		"completionDate", "ignored because completed is false")
+	l.when(stringValue(input.When))
+	l.pastDate("creationDate", stringValue(input.CreationDate))
+	l.pastDate("completionDate", stringValue(input.CompletionDate))
+	return l.warnings
+}
+
+func lintShow(input ShowInput) []Warning {
+	var l linter
+	l.warn(input.ID != "" && input.Query != "", "query", "ignored because id is set")
+	return l.warnings
+}
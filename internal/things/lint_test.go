@@ -0,0 +1,105 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func warningFields(warnings []Warning) []string {
+	fields := make([]string, len(warnings))
+	for i, w := range warnings {
+		fields[i] = w.Field
+	}
+	return fields
+}
+
+func TestLintAddReportsPrecedenceConflicts(t *testing.T) {
+	yes := true
+	warnings := lintAdd(AddInput{
+		Title:          "Ignored",
+		Titles:         []string{"A"},
+		List:           "Work",
+		ListID:         "list-id",
+		Completed:      &yes,
+		Canceled:       &yes,
+		When:           "someday@9am",
+		CompletionDate: "2999-01-01T00:00:00Z",
+	})
+
+	want := map[string]bool{"title": true, "list": true, "completed": true, "when": true, "completionDate": true}
+	got := map[string]bool{}
+	for _, field := range warningFields(warnings) {
+		got[field] = true
+	}
+	for field := range want {
+		if !got[field] {
+			t.Errorf("expected warning for %s, got %v", field, warningFields(warnings))
+		}
+	}
+}
+
+func TestLintAddFlagsHeadingWithoutProject(t *testing.T) {
+	warnings := lintAdd(AddInput{Title: "A", Heading: "QA"})
+	if len(warnings) != 1 || warnings[0].Field != "heading" {
+		t.Fatalf("expected heading warning, got %+v", warnings)
+	}
+}
+
+func TestLintAddCompletionDateRequiresCompletion(t *testing.T) {
+	warnings := lintAdd(AddInput{Title: "A", CompletionDate: "2020-01-01T00:00:00Z"})
+	if len(warnings) != 1 || warnings[0].Field != "completionDate" {
+		t.Fatalf("expected completionDate warning, got %+v", warnings)
+	}
+
+	yes := true
+	if warnings := lintAdd(AddInput{Title: "A", Completed: &yes, CompletionDate: "2020-01-01T00:00:00Z"}); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %+v", warnings)
+	}
+}
+
+func TestLintAcceptsISO8601Layouts(t *testing.T) {
+	for _, value := range []string{"2020-01-02T10:00:00Z", "2020-01-02T10:00:00+01:00", "2020-01-02T10:00:00", "2020-01-02T10:00", "2020-01-02 10:00", "2020-01-02"} {
+		if warnings := lintAdd(AddInput{Title: "A", CreationDate: value}); len(warnings) != 0 {
+			t.Errorf("creationDate %q: unexpected warnings %+v", value, warnings)
+		}
+	}
+	for _, value := range []string{"yesterday", "02/01/2020", "2999-01-02 10:00"} {
+		if warnings := lintAdd(AddInput{Title: "A", CreationDate: value}); len(warnings) != 1 || warnings[0].Field != "creationDate" {
+			t.Errorf("creationDate %q: expected a warning, got %+v", value, warnings)
+		}
+	}
+}
+
+func TestStrictModeRejectsWarnings(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Strict: true})
+
+	_, err := client.Show(context.Background(), ShowInput{ID: "today", Query: "Work"})
+
+	var lintErr *LintError
+	if !errors.As(err, &lintErr) || len(lintErr.Warnings) != 1 {
+		t.Fatalf("expected LintError, got %v", err)
+	}
+	if len(launcher.calls) != 0 {
+		t.Fatalf("expected no dispatch, saw %v", launcher.calls)
+	}
+}
+
+func TestUpdateProjectReportsWarnings(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}})
+	area, areaID := "Work", "area-id"
+
+	res, err := client.UpdateProject(context.Background(), UpdateProjectInput{
+		AuthToken: "token",
+		ID:        "project-id",
+		Area:      &area,
+		AreaID:    &areaID,
+	})
+	if err != nil {
+		t.Fatalf("UpdateProject returned error: %v", err)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].Field != "area" {
+		t.Fatalf("expected area warning, got %+v", res.Warnings)
+	}
+}
@@ -0,0 +1,121 @@
+package things
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// markerPrefix starts the notes line that ties an imported item to its
+// source, so a later import of the same source updates the item.
+const markerPrefix = "things-mcp-id: "
+
+// Marker returns the notes line that identifies key.
+func Marker(key string) string {
+	return markerPrefix + key
+}
+
+// markerKey returns the key of the first marker line in notes.
+func markerKey(notes string) (string, bool) {
+	for line := range strings.Lines(notes) {
+		if key, ok := strings.CutPrefix(strings.TrimSpace(line), markerPrefix); ok && key != "" {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// MarkedItems returns the IDs of untrashed items of type kind, open or not,
+// whose notes carry a marker, keyed by the marker's key.
+func (d *DB) MarkedItems(ctx context.Context, kind ItemType) (map[string]string, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT uuid, COALESCE(notes, '')
+		FROM TMTask
+		WHERE type = ? AND trashed = 0 AND instr(COALESCE(notes, ''), ?) > 0
+		ORDER BY creationDate`, kind, markerPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("query marked items: %w", err)
+	}
+	defer rows.Close()
+
+	marked := map[string]string{}
+	for rows.Next() {
+		var id, notes string
+		if err := rows.Scan(&id, &notes); err != nil {
+			return nil, fmt.Errorf("scan marked item: %w", err)
+		}
+		if key, ok := markerKey(notes); ok {
+			if _, seen := marked[key]; !seen {
+				marked[key] = id
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query marked items: %w", err)
+	}
+	return marked, nil
+}
+
+// updateMarked turns top-level to-dos whose marker matches an existing to-do
+// into updates of it. The update mirrors the title, deadline, tags, and
+// completion state, and leaves notes, list, and when to the user.
+func (c *Client) updateMarked(ctx context.Context, items []JSONItem) error {
+	if c.db == nil {
+		return nil
+	}
+	var marked map[string]string
+	for i := range items {
+		item := &items[i]
+		if item.Type != JSONToDo || item.Operation == JSONUpdate {
+			continue
+		}
+		key, ok := markerKey(item.Attributes.Notes)
+		if !ok {
+			continue
+		}
+		if marked == nil {
+			var err error
+			if marked, err = c.db.MarkedItems(ctx, ItemToDo); err != nil {
+				return err
+			}
+		}
+		id, ok := marked[key]
+		if !ok {
+			continue
+		}
+		*item = markedUpdate(id, item.Attributes)
+	}
+	return nil
+}
+
+func markedUpdate(id string, src JSONAttributes) JSONItem {
+	attrs := JSONAttributes{
+		Title:          src.Title,
+		Deadline:       src.Deadline,
+		Tags:           src.Tags,
+		CompletionDate: src.CompletionDate,
+	}
+	switch {
+	case boolValue(src.Canceled):
+		attrs.Canceled = src.Canceled
+	default:
+		completed := boolValue(src.Completed)
+		attrs.Completed = &completed
+	}
+	if attrs.Deadline == "" {
+		attrs.Clear = append(attrs.Clear, "deadline")
+	}
+	if len(attrs.Tags) == 0 {
+		attrs.Clear = append(attrs.Clear, "tags")
+	}
+	return JSONItem{Type: JSONToDo, Operation: JSONUpdate, ID: id, Attributes: attrs}
+}
+
+func hasMarkers(items []JSONItem) bool {
+	for _, item := range items {
+		if _, ok := markerKey(item.Attributes.Notes); ok && item.Type == JSONToDo {
+			return true
+		}
+	}
+	return false
+}
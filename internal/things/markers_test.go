@@ -0,0 +1,103 @@
+package things
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func markerFixture(t *testing.T) *DB {
+	t.Helper()
+	return newFixtureDB(t,
+		`INSERT INTO TMTask (uuid, type, title, notes, creationDate) VALUES ('todo-12', 0, 'Fix login', 'https://example.com/12'||char(10)||char(10)||'things-mcp-id: issue:https://example.com/12', 1)`,
+		`INSERT INTO TMTask (uuid, type, title, notes, creationDate) VALUES ('todo-12b', 0, 'Fix login again', 'things-mcp-id: issue:https://example.com/12', 2)`,
+		`INSERT INTO TMTask (uuid, type, title, notes, trashed) VALUES ('todo-13', 0, 'Trashed', 'things-mcp-id: issue:https://example.com/13', 1)`,
+		`INSERT INTO TMTask (uuid, type, title, notes) VALUES ('todo-plain', 0, 'Plain', 'no marker')`,
+		`INSERT INTO TMTask (uuid, type, title) VALUES ('todo-null', 0, 'No notes')`,
+	)
+}
+
+func TestDBMarkedItems(t *testing.T) {
+	marked, err := markerFixture(t).MarkedItems(context.Background(), ItemToDo)
+	if err != nil {
+		t.Fatalf("MarkedItems returned error: %v", err)
+	}
+	if len(marked) != 1 || marked["issue:https://example.com/12"] != "todo-12" {
+		t.Fatalf("unexpected marked items: %v", marked)
+	}
+}
+
+func TestImportUpdatesMarkedToDos(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: markerFixture(t), AuthToken: "token"})
+
+	res, err := client.Import(context.Background(), ImportInput{Items: []JSONItem{
+		{Type: JSONToDo, Attributes: JSONAttributes{Title: "Fix login flow", Notes: "https://example.com/12\n\n" + Marker("issue:https://example.com/12"), Tags: []string{"bug"}}},
+		{Type: JSONToDo, Attributes: JSONAttributes{Title: "New issue", Notes: Marker("issue:https://example.com/13")}},
+	}})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if res.Updated != 1 || res.ToDos != 1 {
+		t.Fatalf("counts = %+v, want 1 updated and 1 created", res)
+	}
+	data := string(res.Data)
+	for _, want := range []string{
+		`"operation":"update","id":"todo-12"`,
+		`"title":"Fix login flow"`,
+		`"completed":false`,
+		`"deadline":null`,
+		`"title":"New issue","notes":"things-mcp-id: issue:https://example.com/13"`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("payload %s missing %s", data, want)
+		}
+	}
+	if strings.Contains(data, `"notes":"https://example.com/12`) {
+		t.Errorf("update overwrote notes: %s", data)
+	}
+	if len(launcher.calls) != 1 || !strings.Contains(launcher.calls[0], "auth-token=token") {
+		t.Errorf("dispatched %v, want one call with the auth token", launcher.calls)
+	}
+}
+
+func TestImportWarnsWithoutDatabaseForMarkers(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}})
+	res, err := client.Import(context.Background(), ImportInput{
+		ImportOptions: ImportOptions{Preview: true},
+		Items:         []JSONItem{{Type: JSONToDo, Attributes: JSONAttributes{Title: "Issue", Notes: Marker("issue:x")}}},
+	})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if len(res.Warnings) != 1 || res.ToDos != 1 {
+		t.Fatalf("result = %+v, want one created to-do and a warning", res)
+	}
+}
+
+func TestImportUpdateNeedsAuthToken(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}, DB: markerFixture(t)})
+	_, err := client.Import(context.Background(), ImportInput{Items: []JSONItem{
+		{Type: JSONToDo, Attributes: JSONAttributes{Title: "Fix", Notes: Marker("issue:https://example.com/12")}},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "auth token") {
+		t.Fatalf("err = %v, want auth token error", err)
+	}
+}
+
+func TestImportRawKeepsMarkedItems(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DB: markerFixture(t)})
+	res, err := client.Import(context.Background(), ImportInput{Raw: true, Items: []JSONItem{
+		{Type: JSONToDo, Attributes: JSONAttributes{Title: "Fix login", Notes: Marker("issue:https://example.com/12")}},
+	}})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if res.Updated != 0 || res.ToDos != 1 || len(launcher.calls) != 1 {
+		t.Fatalf("result = %+v after %d launches, want one created to-do", res, len(launcher.calls))
+	}
+	if strings.Contains(string(res.Data), `"operation":"update"`) {
+		t.Errorf("raw import rewrote a marked item: %s", res.Data)
+	}
+}
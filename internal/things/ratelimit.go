@@ -0,0 +1,47 @@
+package things
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a sliding-window limiter. A nil limiter allows everything.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	sent   []time.Time
+	now    func() time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, now: time.Now}
+}
+
+// allow records a dispatch, or reports how long to wait when the window is
+// full.
+func (r *rateLimiter) allow() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	cutoff := now.Add(-r.window)
+	kept := r.sent[:0]
+	for _, t := range r.sent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	r.sent = kept
+
+	if len(r.sent) >= r.limit {
+		wait := r.sent[0].Add(r.window).Sub(now)
+		return fmt.Errorf("rate limit of %d dispatches per %s reached; retry in %s", r.limit, r.window, wait.Round(time.Second))
+	}
+	r.sent = append(r.sent, now)
+	return nil
+}
@@ -0,0 +1,44 @@
+package things
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSlidingWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(2, 10*time.Second)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.allow(); err != nil {
+			t.Fatalf("dispatch %d rejected: %v", i, err)
+		}
+	}
+	err := limiter.allow()
+	if err == nil || !strings.Contains(err.Error(), "retry in 10s") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+
+	now = now.Add(11 * time.Second)
+	if err := limiter.allow(); err != nil {
+		t.Fatalf("expected window to slide, got %v", err)
+	}
+}
+
+func TestDispatchRespectsRateLimit(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, RateLimit: RateLimit{Requests: 1, Per: time.Minute}})
+
+	if _, err := client.Show(context.Background(), ShowInput{ID: "today"}); err != nil {
+		t.Fatalf("first Show returned error: %v", err)
+	}
+	if _, err := client.Show(context.Background(), ShowInput{ID: "today"}); err == nil {
+		t.Fatal("expected second Show to be rate limited")
+	}
+	if len(launcher.calls) != 1 {
+		t.Fatalf("expected one launch, got %d", len(launcher.calls))
+	}
+}
@@ -0,0 +1,333 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveInput names the list, heading, area, or tags to look up in the
+// catalog.
+type ResolveInput struct {
+	List    string   `json:"list,omitempty"`
+	ListID  string   `json:"listId,omitempty"`
+	Heading string   `json:"heading,omitempty"`
+	Area    string   `json:"area,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// Resolution holds the IDs that names resolved to.
+type Resolution struct {
+	ListID       string   `json:"listId,omitempty"`
+	ListTitle    string   `json:"listTitle,omitempty"`
+	ListType     string   `json:"listType,omitempty"`
+	HeadingID    string   `json:"headingId,omitempty"`
+	HeadingTitle string   `json:"headingTitle,omitempty"`
+	AreaID       string   `json:"areaId,omitempty"`
+	AreaTitle    string   `json:"areaTitle,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// ResolveError reports a name that matched nothing, or more than one item.
+type ResolveError struct {
+	Kind        string
+	Name        string
+	Matches     []string
+	Suggestions []string
+}
+
+func (e *ResolveError) Error() string {
+	if len(e.Matches) > 0 {
+		return fmt.Sprintf("%s %q is ambiguous: matches %s; pass an id instead", e.Kind, e.Name, strings.Join(e.Matches, ", "))
+	}
+	msg := fmt.Sprintf("%s %q not found", e.Kind, e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += "; did you mean " + quoteList(e.Suggestions) + "?"
+	}
+	return msg
+}
+
+// Resolver turns list, heading, and area names into IDs so Things never
+// silently drops an item into the Inbox.
+type Resolver struct {
+	source CatalogSource
+}
+
+// NewResolver builds a Resolver backed by source.
+func NewResolver(source CatalogSource) *Resolver {
+	return &Resolver{source: source}
+}
+
+// Resolve looks up every name in input. IDs that are already known are passed
+// through untouched, even when the catalog does not contain them.
+func (r *Resolver) Resolve(ctx context.Context, input ResolveInput) (Resolution, error) {
+	catalog, err := r.source.Catalog(ctx)
+	if err != nil {
+		return Resolution{}, fmt.Errorf("load catalog: %w", err)
+	}
+
+	var res Resolution
+	var project *CatalogProject
+
+	switch {
+	case input.ListID != "":
+		res.ListID = input.ListID
+		if p, ok := catalog.project(input.ListID); ok {
+			project = &p
+			res.ListTitle, res.ListType = p.Title, "project"
+		} else if a, ok := catalog.area(input.ListID); ok {
+			res.ListTitle, res.ListType = a.Title, "area"
+		}
+	case input.List != "":
+		entries := make([]catalogEntry, 0, len(catalog.Projects)+len(catalog.Areas))
+		for _, p := range catalog.Projects {
+			entries = append(entries, catalogEntry{id: p.ID, title: p.Title, kind: "project"})
+		}
+		for _, a := range catalog.Areas {
+			entries = append(entries, catalogEntry{id: a.ID, title: a.Title, kind: "area"})
+		}
+		match, err := matchEntry("list", input.List, entries)
+		if err != nil {
+			return Resolution{}, err
+		}
+		res.ListID, res.ListTitle, res.ListType = match.id, match.title, match.kind
+		if p, ok := catalog.project(match.id); ok {
+			project = &p
+		}
+	}
+
+	if input.Heading != "" {
+		switch {
+		case project != nil:
+			entries := make([]catalogEntry, 0, len(project.Headings))
+			for _, h := range project.Headings {
+				entries = append(entries, catalogEntry{id: h.ID, title: h.Title, kind: "heading"})
+			}
+			match, err := matchEntry("heading", input.Heading, entries)
+			if err != nil {
+				return Resolution{}, err
+			}
+			res.HeadingID, res.HeadingTitle = match.id, match.title
+		case res.ListType == "area":
+			return Resolution{}, fmt.Errorf("heading %q requires a project, but %q is an area", input.Heading, res.ListTitle)
+		case res.ListID == "":
+			return Resolution{}, fmt.Errorf("heading %q requires a project list", input.Heading)
+		default:
+			// The list ID is not in the catalog; let Things match the title.
+			res.HeadingTitle = input.Heading
+		}
+	}
+
+	if input.Area != "" {
+		entries := make([]catalogEntry, 0, len(catalog.Areas))
+		for _, a := range catalog.Areas {
+			entries = append(entries, catalogEntry{id: a.ID, title: a.Title, kind: "area"})
+		}
+		match, err := matchEntry("area", input.Area, entries)
+		if err != nil {
+			return Resolution{}, err
+		}
+		res.AreaID, res.AreaTitle = match.id, match.title
+	}
+
+	if len(input.Tags) > 0 && catalog.Tags != nil {
+		tags, missing := matchTags(catalog, input.Tags)
+		if len(missing) > 0 {
+			errs := make([]error, len(missing))
+			for i, err := range missing {
+				errs[i] = err
+			}
+			return Resolution{}, errors.Join(errs...)
+		}
+		res.Tags = tags
+	}
+
+	return res, nil
+}
+
+// Resolve reports what the configured catalog makes of the supplied names.
+func (c *Client) Resolve(ctx context.Context, input ResolveInput) (Resolution, error) {
+	if c.resolver == nil {
+		return Resolution{}, errors.New("no catalog configured; start the server with -db or -catalog")
+	}
+	if input.List == "" && input.ListID == "" && input.Heading == "" && input.Area == "" && len(input.Tags) == 0 {
+		return Resolution{}, errors.New("provide list, listId, heading, area, or tags")
+	}
+	return c.resolver.Resolve(ctx, input)
+}
+
+func (c *Client) resolveAdd(ctx context.Context, input *AddInput) (Resolution, error) {
+	if c.resolver == nil {
+		return Resolution{}, nil
+	}
+	lookup := ResolveInput{ListID: input.ListID}
+	if input.ListID == "" {
+		lookup.List = input.List
+	}
+	if input.HeadingID == "" {
+		lookup.Heading = input.Heading
+	}
+	if lookup.List == "" && lookup.Heading == "" {
+		return Resolution{}, nil
+	}
+
+	res, err := c.resolver.Resolve(ctx, lookup)
+	if err != nil {
+		return Resolution{}, err
+	}
+	if lookup.List != "" {
+		input.ListID, input.List = res.ListID, ""
+	}
+	if res.HeadingID != "" {
+		input.HeadingID, input.Heading = res.HeadingID, ""
+	}
+	return res, nil
+}
+
+func (c *Client) resolveUpdate(ctx context.Context, input *UpdateInput) (Resolution, error) {
+	if c.resolver == nil {
+		return Resolution{}, nil
+	}
+	lookup := ResolveInput{}
+	if input.ListID != nil {
+		lookup.ListID = *input.ListID
+	} else if input.List != nil {
+		lookup.List = *input.List
+	}
+	// Without a list the heading is matched against the to-do's current
+	// project, which the catalog cannot know.
+	if input.HeadingID == nil && input.Heading != nil && (lookup.List != "" || lookup.ListID != "") {
+		lookup.Heading = *input.Heading
+	}
+	if lookup.List == "" && lookup.Heading == "" {
+		return Resolution{}, nil
+	}
+
+	res, err := c.resolver.Resolve(ctx, lookup)
+	if err != nil {
+		return Resolution{}, err
+	}
+	if lookup.List != "" {
+		input.ListID, input.List = &res.ListID, nil
+	}
+	if res.HeadingID != "" {
+		input.HeadingID, input.Heading = &res.HeadingID, nil
+	}
+	return res, nil
+}
+
+func (c *Client) resolveArea(ctx context.Context, area, areaID *string) (Resolution, error) {
+	if c.resolver == nil || *areaID != "" || *area == "" {
+		return Resolution{}, nil
+	}
+	res, err := c.resolver.Resolve(ctx, ResolveInput{Area: *area})
+	if err != nil {
+		return Resolution{}, err
+	}
+	*areaID, *area = res.AreaID, ""
+	return res, nil
+}
+
+type catalogEntry struct {
+	id    string
+	title string
+	kind  string
+}
+
+func matchEntry(kind, name string, entries []catalogEntry) (catalogEntry, error) {
+	var exact, folded []catalogEntry
+	needle := strings.TrimSpace(name)
+	for _, entry := range entries {
+		switch {
+		case entry.title == needle:
+			exact = append(exact, entry)
+		case strings.EqualFold(strings.TrimSpace(entry.title), needle):
+			folded = append(folded, entry)
+		}
+	}
+
+	for _, matches := range [][]catalogEntry{exact, folded} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			described := make([]string, len(matches))
+			for i, m := range matches {
+				described[i] = fmt.Sprintf("%s %q (%s)", m.kind, m.title, m.id)
+			}
+			return catalogEntry{}, &ResolveError{Kind: kind, Name: name, Matches: described}
+		}
+	}
+
+	titles := make([]string, len(entries))
+	for i, entry := range entries {
+		titles[i] = entry.title
+	}
+	return catalogEntry{}, &ResolveError{Kind: kind, Name: name, Suggestions: suggest(name, titles)}
+}
+
+// suggest returns up to three candidates that look like plausible typos of
+// name, closest first.
+func suggest(name string, candidates []string) []string {
+	type scored struct {
+		title string
+		dist  int
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(name))
+	limit := max(2, len(needle)/3)
+	seen := map[string]bool{}
+	var hits []scored
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		hay := strings.ToLower(candidate)
+		dist := editDistance(needle, hay)
+		if dist <= limit || (needle != "" && (strings.Contains(hay, needle) || strings.Contains(needle, hay))) {
+			hits = append(hits, scored{title: candidate, dist: dist})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].dist < hits[j].dist })
+
+	var out []string
+	for i := 0; i < len(hits) && i < 3; i++ {
+		out = append(out, hits[i].title)
+	}
+	return out
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
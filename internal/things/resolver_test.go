@@ -0,0 +1,115 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testCatalog() *Catalog {
+	return &Catalog{
+		Areas: []CatalogArea{
+			{ID: "area-work", Title: "Work"},
+			{ID: "area-errands", Title: "Errands"},
+		},
+		Projects: []CatalogProject{
+			{ID: "proj-launch", Title: "Launch", AreaID: "area-work", Headings: []CatalogHeading{
+				{ID: "head-qa", Title: "QA"},
+				{ID: "head-docs", Title: "Docs"},
+			}},
+			{ID: "proj-errands", Title: "Errands"},
+		},
+	}
+}
+
+func TestAddResolvesListAndHeadingNames(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Catalog: testCatalog()})
+
+	_, err := client.Add(context.Background(), AddInput{
+		Title:   "Write tests",
+		List:    "launch",
+		Heading: "QA",
+	})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	want := "things:///add?heading-id=head-qa&list-id=proj-launch&title=Write%20tests"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("Add dispatched %q, want %q", got, want)
+	}
+}
+
+func TestAddReportsMissingListWithSuggestions(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Catalog: testCatalog()})
+
+	_, err := client.Add(context.Background(), AddInput{Title: "Draft", List: "Wrok"})
+
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) {
+		t.Fatalf("expected ResolveError, got %v", err)
+	}
+	if len(resolveErr.Suggestions) == 0 || resolveErr.Suggestions[0] != "Work" {
+		t.Fatalf("expected Work suggestion, got %v", resolveErr.Suggestions)
+	}
+	if len(launcher.calls) != 0 {
+		t.Fatalf("expected no dispatch, saw %v", launcher.calls)
+	}
+}
+
+func TestResolveRejectsAmbiguousList(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}, Catalog: testCatalog()})
+
+	_, err := client.Resolve(context.Background(), ResolveInput{List: "Errands"})
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("expected ambiguity error, got %v", err)
+	}
+}
+
+func TestResolveHeadingRequiresProject(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}, Catalog: testCatalog()})
+
+	_, err := client.Resolve(context.Background(), ResolveInput{ListID: "area-work", Heading: "QA"})
+	if err == nil || !strings.Contains(err.Error(), "area") {
+		t.Fatalf("expected area error, got %v", err)
+	}
+}
+
+func TestAddProjectResolvesArea(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Catalog: testCatalog()})
+
+	if _, err := client.AddProject(context.Background(), AddProjectInput{Title: "Q3", Area: "work"}); err != nil {
+		t.Fatalf("AddProject returned error: %v", err)
+	}
+
+	want := "things:///add-project?area-id=area-work&title=Q3"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("AddProject dispatched %q, want %q", got, want)
+	}
+}
+
+func TestLoadCatalogValidatesEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	good := filepath.Join(dir, "good.yaml")
+	os.WriteFile(good, []byte("areas:\n  - id: area-work\n    title: Work\nprojects:\n  - id: proj-1\n    title: Launch\n    headings:\n      - id: head-1\n        title: QA\n"), 0o600)
+	catalog, err := LoadCatalog(good)
+	if err != nil {
+		t.Fatalf("LoadCatalog returned error: %v", err)
+	}
+	if len(catalog.Projects) != 1 || catalog.Projects[0].Headings[0].ID != "head-1" {
+		t.Fatalf("unexpected catalog %+v", catalog)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	os.WriteFile(bad, []byte("areas:\n  - title: Work\n"), 0o600)
+	if _, err := LoadCatalog(bad); err == nil {
+		t.Fatalf("expected error for area without id")
+	}
+}
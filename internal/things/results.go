@@ -0,0 +1,137 @@
+package things
+
+import (
+	"context"
+	"time"
+)
+
+// Dispatch describes a single URL-scheme invocation.
+type Dispatch struct {
+	URL    string `json:"url"`
+	DryRun bool   `json:"dryRun,omitempty"`
+	// Replayed is set when an idempotency key matched an earlier call and
+	// its result was returned without dispatching again.
+	Replayed bool      `json:"replayed,omitempty"`
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+func (d *Dispatch) base() *Dispatch { return d }
+
+// Warning flags a parameter Things will ignore or treat differently than the
+// caller probably expects.
+type Warning struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type AddResult struct {
+	Dispatch
+	Titles    []string `json:"titles,omitempty"`
+	IDs       []string `json:"ids,omitempty"`
+	ListID    string   `json:"listId,omitempty"`
+	List      string   `json:"list,omitempty"`
+	HeadingID string   `json:"headingId,omitempty"`
+	Heading   string   `json:"heading,omitempty"`
+	When      string   `json:"when,omitempty"`
+	Deadline  string   `json:"deadline,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	// Duplicates lists titles that matched open to-dos when onDuplicate
+	// is set.
+	Duplicates []Duplicate `json:"duplicates,omitempty"`
+}
+
+type AddProjectResult struct {
+	Dispatch
+	ID       string   `json:"id,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	AreaID   string   `json:"areaId,omitempty"`
+	Area     string   `json:"area,omitempty"`
+	When     string   `json:"when,omitempty"`
+	Deadline string   `json:"deadline,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	ToDos    []string `json:"toDos,omitempty"`
+	// Duplicates lists the open project the title matched when onDuplicate
+	// is set.
+	Duplicates []Duplicate `json:"duplicates,omitempty"`
+}
+
+type UpdateResult struct {
+	Dispatch
+	ID        string   `json:"id"`
+	ListID    string   `json:"listId,omitempty"`
+	List      string   `json:"list,omitempty"`
+	HeadingID string   `json:"headingId,omitempty"`
+	Heading   string   `json:"heading,omitempty"`
+	When      *string  `json:"when,omitempty"`
+	Deadline  *string  `json:"deadline,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	AddTags   []string `json:"addTags,omitempty"`
+	Cleared   []string `json:"cleared,omitempty"`
+}
+
+type UpdateProjectResult struct {
+	Dispatch
+	ID       string   `json:"id"`
+	AreaID   string   `json:"areaId,omitempty"`
+	Area     string   `json:"area,omitempty"`
+	When     *string  `json:"when,omitempty"`
+	Deadline *string  `json:"deadline,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	AddTags  []string `json:"addTags,omitempty"`
+	Cleared  []string `json:"cleared,omitempty"`
+}
+
+type ShowResult struct {
+	Dispatch
+	Target string `json:"target"`
+}
+
+type SearchResult struct {
+	Dispatch
+}
+
+type VersionResult struct {
+	Dispatch
+}
+
+type JSONResult struct {
+	Dispatch
+	Items int `json:"items"`
+}
+
+// Created IDs are only visible once Things has written them to its database,
+// so lookups poll briefly after dispatch.
+const (
+	createdLookupTimeout  = 2 * time.Second
+	createdLookupInterval = 100 * time.Millisecond
+)
+
+// lookupCreated finds the IDs of items Things created from titles since the
+// dispatch started. It is best effort: without a database, or if Things is
+// slow to write, it returns nil.
+func (c *Client) lookupCreated(ctx context.Context, kind ItemType, titles []string, since time.Time) []string {
+	if c.db == nil || c.dryRun || len(titles) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createdLookupTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(createdLookupInterval)
+	defer ticker.Stop()
+	for {
+		ids, err := c.db.CreatedSince(ctx, kind, titles, since)
+		if err == nil && len(ids) >= len(titles) {
+			return ids
+		}
+		select {
+		case <-ctx.Done():
+			return ids
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) newDispatch(target string) Dispatch {
+	return Dispatch{URL: target, DryRun: c.dryRun}
+}
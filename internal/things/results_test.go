@@ -0,0 +1,79 @@
+package things
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAddResultReportsIgnoredTitle(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}})
+
+	res, err := client.Add(context.Background(), AddInput{
+		Title:  "Ignored",
+		Titles: []string{"Milk", "Beer"},
+		When:   "Today",
+	})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	if !slices.Equal(res.Titles, []string{"Milk", "Beer"}) {
+		t.Fatalf("unexpected titles %v", res.Titles)
+	}
+	if res.When != "today" {
+		t.Fatalf("expected normalized when, got %q", res.When)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].Field != "title" {
+		t.Fatalf("expected title warning, got %+v", res.Warnings)
+	}
+}
+
+func TestDryRunSkipsLauncher(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, DryRun: true})
+
+	res, err := client.Search(context.Background(), SearchInput{Query: "milk"})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if !res.DryRun || res.URL != "things:///search?query=milk" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if len(launcher.calls) != 0 {
+		t.Fatalf("expected no launches, saw %v", launcher.calls)
+	}
+}
+
+func TestAddResultLooksUpCreatedIDs(t *testing.T) {
+	// Rows are stamped in the future so they count as created after dispatch.
+	created := unixSeconds(time.Now().Add(time.Minute))
+	db := newFixtureDB(t,
+		fmt.Sprintf(`INSERT INTO TMTask (uuid, type, title, creationDate) VALUES ('id-milk', 0, 'Milk', %f), ('id-beer', 0, 'Beer', %f)`, created, created),
+		`INSERT INTO TMTask (uuid, type, title, creationDate) VALUES ('id-old', 0, 'Milk', 1)`,
+	)
+	client := NewClient(Config{Launcher: &fakeLauncher{}, DB: db})
+
+	res, err := client.Add(context.Background(), AddInput{Titles: []string{"Milk", "Beer"}})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if !slices.Equal(res.IDs, []string{"id-milk", "id-beer"}) {
+		t.Fatalf("unexpected IDs %v", res.IDs)
+	}
+}
+
+func TestUpdateResultKeepsClearedDeadline(t *testing.T) {
+	client := NewClient(Config{Launcher: &fakeLauncher{}})
+	empty := ""
+
+	res, err := client.Update(context.Background(), UpdateInput{AuthToken: "token", ID: "todo-id", Deadline: &empty})
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if res.Deadline == nil || *res.Deadline != "" {
+		t.Fatalf("expected cleared deadline, got %v", res.Deadline)
+	}
+}
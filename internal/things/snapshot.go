@@ -0,0 +1,375 @@
+package things
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// More statuses as stored in the TMTask table.
+const (
+	statusCanceled  = 2
+	statusCompleted = 3
+)
+
+// Item states in a snapshot.
+const (
+	StatusOpen      = "open"
+	StatusCompleted = "completed"
+	StatusCanceled  = "canceled"
+)
+
+// Snapshot is a read of the Things library: areas with their projects and
+// to-dos, then the projects and to-dos that are in no area. Trashed items and
+// repeating templates are left out.
+type Snapshot struct {
+	Areas    []Area    `json:"areas"`
+	Projects []Project `json:"projects"`
+	ToDos    []ToDo    `json:"toDos"`
+}
+
+// Area is an area of responsibility.
+type Area struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Tags     []string  `json:"tags,omitempty"`
+	Projects []Project `json:"projects,omitempty"`
+	ToDos    []ToDo    `json:"toDos,omitempty"`
+}
+
+// Task holds the fields projects and to-dos share. When is "" for the Inbox,
+// "anytime", "someday", or the scheduled date; dates are YYYY-MM-DD.
+type Task struct {
+	ID               string     `json:"id"`
+	Title            string     `json:"title"`
+	Notes            string     `json:"notes,omitempty"`
+	Status           string     `json:"status"`
+	When             string     `json:"when,omitempty"`
+	Deadline         string     `json:"deadline,omitempty"`
+	Tags             []string   `json:"tags,omitempty"`
+	CreationDate     time.Time  `json:"creationDate"`
+	ModificationDate time.Time  `json:"modificationDate"`
+	CompletionDate   *time.Time `json:"completionDate,omitempty"`
+}
+
+// Project is a project with the to-dos outside its headings first.
+type Project struct {
+	Task
+	ToDos    []ToDo    `json:"toDos,omitempty"`
+	Headings []Heading `json:"headings,omitempty"`
+}
+
+// Heading groups to-dos inside a project.
+type Heading struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	ToDos []ToDo `json:"toDos,omitempty"`
+}
+
+// ToDo is a to-do and its checklist.
+type ToDo struct {
+	Task
+	Checklist []ChecklistItem `json:"checklist,omitempty"`
+}
+
+// ChecklistItem is one line of a to-do's checklist.
+type ChecklistItem struct {
+	Title     string `json:"title"`
+	Completed bool   `json:"completed,omitempty"`
+}
+
+// SnapshotOptions select what a snapshot includes.
+type SnapshotOptions struct {
+	// IncludeClosed adds completed and canceled items, the Logbook, to the
+	// open ones.
+	IncludeClosed bool
+}
+
+type taskRow struct {
+	Task
+	kind    ItemType
+	area    string
+	project string
+	heading string
+}
+
+// Snapshot reads areas, projects, headings, to-dos, and checklists in the
+// order Things shows them.
+func (d *DB) Snapshot(ctx context.Context, opts SnapshotOptions) (*Snapshot, error) {
+	tags, err := d.taskTags(ctx, `SELECT tt.tasks, t.title FROM TMTaskTag tt JOIN TMTag t ON t.uuid = tt.tags ORDER BY t."index"`)
+	if err != nil {
+		return nil, err
+	}
+	areaTags, err := d.taskTags(ctx, `SELECT at.areas, t.title FROM TMAreaTag at JOIN TMTag t ON t.uuid = at.tags ORDER BY t."index"`)
+	if err != nil {
+		return nil, err
+	}
+	checklists, err := d.checklists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.taskRows(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	headings := map[string]*Heading{}
+	headingProject := map[string]string{}
+	projects := map[string]*Project{}
+	for i := range rows {
+		row := &rows[i]
+		row.Tags = tags[row.ID]
+		switch row.kind {
+		case ItemHeading:
+			headings[row.ID] = &Heading{ID: row.ID, Title: row.Title}
+			headingProject[row.ID] = row.project
+		case ItemProject:
+			projects[row.ID] = &Project{Task: row.Task}
+		}
+	}
+
+	areas, err := d.areas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	areaIndex := map[string]int{}
+	for i := range areas {
+		areas[i].Tags = areaTags[areas[i].ID]
+		areaIndex[areas[i].ID] = i
+	}
+
+	snap := &Snapshot{}
+	for _, row := range rows {
+		if row.kind != ItemToDo {
+			continue
+		}
+		todo := ToDo{Task: row.Task, Checklist: checklists[row.ID]}
+		project := row.project
+		if project == "" {
+			project = headingProject[row.heading]
+		}
+		p, inProject := projects[project]
+		if h, ok := headings[row.heading]; ok && inProject {
+			h.ToDos = append(h.ToDos, todo)
+			continue
+		}
+		switch i, inArea := areaIndex[row.area]; {
+		case inProject:
+			p.ToDos = append(p.ToDos, todo)
+		case project != "":
+			// The project is closed and left out of the snapshot.
+		case inArea:
+			areas[i].ToDos = append(areas[i].ToDos, todo)
+		default:
+			snap.ToDos = append(snap.ToDos, todo)
+		}
+	}
+	for _, row := range rows {
+		if p, ok := projects[row.project]; ok && row.kind == ItemHeading {
+			p.Headings = append(p.Headings, *headings[row.ID])
+		}
+	}
+	for _, row := range rows {
+		if row.kind != ItemProject {
+			continue
+		}
+		if i, ok := areaIndex[row.area]; ok {
+			areas[i].Projects = append(areas[i].Projects, *projects[row.ID])
+		} else {
+			snap.Projects = append(snap.Projects, *projects[row.ID])
+		}
+	}
+	snap.Areas = areas
+	return snap, nil
+}
+
+// Entry is a project or to-do from a snapshot with the titles of the area,
+// project, and heading that hold it.
+type Entry struct {
+	Type ItemType
+	Task
+	Area      string
+	Project   string
+	Heading   string
+	Checklist []ChecklistItem
+}
+
+// Entries flattens s into its projects and to-dos in snapshot order, each
+// project followed by its to-dos.
+func (s *Snapshot) Entries() []Entry {
+	var entries []Entry
+	addToDos := func(todos []ToDo, area, project, heading string) {
+		for _, todo := range todos {
+			entries = append(entries, Entry{Type: ItemToDo, Task: todo.Task, Area: area, Project: project, Heading: heading, Checklist: todo.Checklist})
+		}
+	}
+	addProjects := func(projects []Project, area string) {
+		for _, p := range projects {
+			entries = append(entries, Entry{Type: ItemProject, Task: p.Task, Area: area})
+			addToDos(p.ToDos, area, p.Title, "")
+			for _, h := range p.Headings {
+				addToDos(h.ToDos, area, p.Title, h.Title)
+			}
+		}
+	}
+	for _, area := range s.Areas {
+		addToDos(area.ToDos, area.Title, "", "")
+		addProjects(area.Projects, area.Title)
+	}
+	addProjects(s.Projects, "")
+	addToDos(s.ToDos, "", "", "")
+	return entries
+}
+
+func (d *DB) taskRows(ctx context.Context, opts SnapshotOptions) ([]taskRow, error) {
+	query := `
+		SELECT uuid, type, COALESCE(title, ''), COALESCE(notes, ''), status, start,
+			startDate, deadline, creationDate, userModificationDate, stopDate,
+			COALESCE(area, ''), COALESCE(project, ''), COALESCE(heading, '')
+		FROM TMTask
+		WHERE trashed = 0 AND rt1_recurrenceRule IS NULL`
+	var args []any
+	if !opts.IncludeClosed {
+		query += ` AND status = ?`
+		args = append(args, statusOpen)
+	}
+	rows, err := d.db.QueryContext(ctx, query+` ORDER BY "index"`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []taskRow
+	for rows.Next() {
+		var row taskRow
+		var status, start int
+		var startDate, deadline sql.NullInt64
+		var created, modified, stopped sql.NullFloat64
+		if err := rows.Scan(&row.ID, &row.kind, &row.Title, &row.Notes, &status, &start,
+			&startDate, &deadline, &created, &modified, &stopped,
+			&row.area, &row.project, &row.heading); err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		row.Status = taskStatus(status)
+		row.When = taskWhen(start, startDate)
+		if deadline.Valid {
+			row.Deadline = unpackDate(deadline.Int64)
+		}
+		row.CreationDate = fromUnixSeconds(created)
+		row.ModificationDate = fromUnixSeconds(modified)
+		if stopped.Valid && row.Status != StatusOpen {
+			t := fromUnixSeconds(stopped)
+			row.CompletionDate = &t
+		}
+		tasks = append(tasks, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+func (d *DB) areas(ctx context.Context) ([]Area, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT uuid, COALESCE(title, '') FROM TMArea ORDER BY "index"`)
+	if err != nil {
+		return nil, fmt.Errorf("query areas: %w", err)
+	}
+	defer rows.Close()
+
+	var areas []Area
+	for rows.Next() {
+		var area Area
+		if err := rows.Scan(&area.ID, &area.Title); err != nil {
+			return nil, fmt.Errorf("scan area: %w", err)
+		}
+		areas = append(areas, area)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query areas: %w", err)
+	}
+	return areas, nil
+}
+
+// taskTags runs a query returning owner IDs and tag titles and groups the
+// titles by owner.
+func (d *DB) taskTags(ctx context.Context, query string) (map[string][]string, error) {
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := map[string][]string{}
+	for rows.Next() {
+		var owner, title string
+		if err := rows.Scan(&owner, &title); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags[owner] = append(tags[owner], title)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (d *DB) checklists(ctx context.Context) (map[string][]ChecklistItem, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT task, COALESCE(title, ''), status FROM TMChecklistItem ORDER BY "index"`)
+	if err != nil {
+		return nil, fmt.Errorf("query checklist items: %w", err)
+	}
+	defer rows.Close()
+
+	items := map[string][]ChecklistItem{}
+	for rows.Next() {
+		var task, title string
+		var status int
+		if err := rows.Scan(&task, &title, &status); err != nil {
+			return nil, fmt.Errorf("scan checklist item: %w", err)
+		}
+		items[task] = append(items[task], ChecklistItem{Title: title, Completed: status != statusOpen})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query checklist items: %w", err)
+	}
+	return items, nil
+}
+
+func taskStatus(status int) string {
+	switch status {
+	case statusCompleted:
+		return StatusCompleted
+	case statusCanceled:
+		return StatusCanceled
+	}
+	return StatusOpen
+}
+
+// taskWhen maps the start column, 0 for the Inbox, 1 for Anytime, and 2 for
+// Someday, and the scheduled date onto a when value.
+func taskWhen(start int, startDate sql.NullInt64) string {
+	switch {
+	case startDate.Valid && startDate.Int64 > 0:
+		return unpackDate(startDate.Int64)
+	case start == 1:
+		return "anytime"
+	case start == 2:
+		return "someday"
+	}
+	return ""
+}
+
+// unpackDate decodes the year<<16 | month<<12 | day<<7 integers Things uses
+// for start dates and deadlines.
+func unpackDate(packed int64) string {
+	return fmt.Sprintf("%04d-%02d-%02d", packed>>16, (packed>>12)&0xf, (packed>>7)&0x1f)
+}
+
+func fromUnixSeconds(value sql.NullFloat64) time.Time {
+	if !value.Valid {
+		return time.Time{}
+	}
+	sec := int64(value.Float64)
+	return time.Unix(sec, int64((value.Float64-float64(sec))*float64(time.Second))).UTC()
+}
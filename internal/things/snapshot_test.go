@@ -0,0 +1,115 @@
+package things
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// packDate encodes t the way Things stores start dates and deadlines.
+func packDate(t time.Time) int64 {
+	return int64(t.Year())<<16 | int64(t.Month())<<12 | int64(t.Day())<<7
+}
+
+func snapshotFixture(t *testing.T) *DB {
+	t.Helper()
+	deadline := packDate(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	scheduled := packDate(time.Date(2025, 2, 20, 0, 0, 0, 0, time.UTC))
+	return newFixtureDB(t,
+		`INSERT INTO TMArea (uuid, title, "index") VALUES ('area-work', 'Work', 0), ('area-home', 'Home', 1)`,
+		`INSERT INTO TMTag (uuid, title, "index") VALUES ('tag-urgent', 'Urgent', 0), ('tag-errand', 'Errand', 1)`,
+		`INSERT INTO TMAreaTag (areas, tags) VALUES ('area-home', 'tag-errand')`,
+		fmt.Sprintf(`INSERT INTO TMTask (uuid, type, title, notes, area, start, deadline, creationDate, "index") VALUES ('proj-launch', 1, 'Launch', 'Ship v2', 'area-work', 1, %d, 1735689600, 0)`, deadline),
+		`INSERT INTO TMTask (uuid, type, title, project, "index") VALUES ('head-qa', 2, 'QA', 'proj-launch', 1)`,
+		`INSERT INTO TMTask (uuid, type, title, project, start, "index") VALUES ('todo-plan', 0, 'Write plan', 'proj-launch', 1, 0)`,
+		fmt.Sprintf(`INSERT INTO TMTask (uuid, type, title, heading, start, startDate, "index") VALUES ('todo-test', 0, 'Test login', 'head-qa', 2, %d, 2)`, scheduled),
+		`INSERT INTO TMTask (uuid, type, title, heading, status, stopDate, "index") VALUES ('todo-done', 0, 'Old test', 'head-qa', 3, 1736000000.5, 3)`,
+		`INSERT INTO TMTask (uuid, type, title, area, start) VALUES ('todo-milk', 0, 'Buy milk', 'area-home', 2)`,
+		`INSERT INTO TMTask (uuid, type, title, status) VALUES ('proj-old', 1, 'Old project', 3)`,
+		`INSERT INTO TMTask (uuid, type, title, project) VALUES ('todo-orphan', 0, 'In old project', 'proj-old')`,
+		`INSERT INTO TMTask (uuid, type, title) VALUES ('todo-inbox', 0, 'Call mom')`,
+		`INSERT INTO TMTask (uuid, type, title, trashed) VALUES ('todo-trash', 0, 'Trashed', 1)`,
+		`INSERT INTO TMTask (uuid, type, title, rt1_recurrenceRule) VALUES ('todo-repeat', 0, 'Water plants', x'00')`,
+		`INSERT INTO TMTaskTag (tasks, tags) VALUES ('todo-test', 'tag-urgent')`,
+		`INSERT INTO TMChecklistItem (uuid, title, status, task, "index") VALUES ('c1', 'iOS', 0, 'todo-test', 0), ('c2', 'Android', 3, 'todo-test', 1)`,
+	)
+}
+
+func TestDBSnapshotBuildsTree(t *testing.T) {
+	snap, err := snapshotFixture(t).Snapshot(context.Background(), SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+
+	if len(snap.Areas) != 2 || len(snap.Areas[0].Projects) != 1 {
+		t.Fatalf("unexpected areas: %+v", snap.Areas)
+	}
+	launch := snap.Areas[0].Projects[0]
+	if launch.Deadline != "2025-03-01" || launch.When != "anytime" || launch.Notes != "Ship v2" {
+		t.Errorf("project = %+v", launch.Task)
+	}
+	if !launch.CreationDate.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("creation date = %v", launch.CreationDate)
+	}
+	if len(launch.ToDos) != 1 || launch.ToDos[0].Title != "Write plan" {
+		t.Errorf("project to-dos = %+v", launch.ToDos)
+	}
+	if len(launch.Headings) != 1 || len(launch.Headings[0].ToDos) != 1 {
+		t.Fatalf("headings = %+v", launch.Headings)
+	}
+	test := launch.Headings[0].ToDos[0]
+	if test.When != "2025-02-20" || strings.Join(test.Tags, ",") != "Urgent" || len(test.Checklist) != 2 || !test.Checklist[1].Completed {
+		t.Errorf("heading to-do = %+v", test)
+	}
+
+	home := snap.Areas[1]
+	if strings.Join(home.Tags, ",") != "Errand" || len(home.ToDos) != 1 || home.ToDos[0].When != "someday" {
+		t.Errorf("home area = %+v", home)
+	}
+	if len(snap.Projects) != 0 || len(snap.ToDos) != 1 || snap.ToDos[0].ID != "todo-inbox" || snap.ToDos[0].When != "" {
+		t.Errorf("top level = %+v %+v", snap.Projects, snap.ToDos)
+	}
+}
+
+func TestDBSnapshotIncludesClosed(t *testing.T) {
+	snap, err := snapshotFixture(t).Snapshot(context.Background(), SnapshotOptions{IncludeClosed: true})
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+
+	qa := snap.Areas[0].Projects[0].Headings[0]
+	if len(qa.ToDos) != 2 {
+		t.Fatalf("heading to-dos = %+v", qa.ToDos)
+	}
+	done := qa.ToDos[1]
+	if done.Status != StatusCompleted || done.CompletionDate == nil || done.CompletionDate.Unix() != 1736000000 {
+		t.Errorf("completed to-do = %+v", done.Task)
+	}
+	if len(snap.Projects) != 1 || len(snap.Projects[0].ToDos) != 1 {
+		t.Errorf("closed project = %+v", snap.Projects)
+	}
+}
+
+func TestSnapshotEntries(t *testing.T) {
+	snap, err := snapshotFixture(t).Snapshot(context.Background(), SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+
+	var got []string
+	for _, e := range snap.Entries() {
+		got = append(got, fmt.Sprintf("%s/%s/%s/%s", e.Area, e.Project, e.Heading, e.Title))
+	}
+	want := []string{
+		"Work///Launch",
+		"Work/Launch//Write plan",
+		"Work/Launch/QA/Test login",
+		"Home///Buy milk",
+		"///Call mom",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("entries =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,72 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// matchTags rewrites tags to the spelling used in the catalog. Tags that match
+// nothing, or more than one tag, are returned unchanged alongside an error.
+func matchTags(catalog *Catalog, tags []string) ([]string, []*ResolveError) {
+	entries := make([]catalogEntry, len(catalog.Tags))
+	for i, tag := range catalog.Tags {
+		entries[i] = catalogEntry{id: tag.ID, title: tag.Title, kind: "tag"}
+	}
+
+	out := make([]string, len(tags))
+	var errs []*ResolveError
+	for i, tag := range tags {
+		out[i] = tag
+		match, err := matchEntry("tag", tag, entries)
+		if err != nil {
+			errs = append(errs, err.(*ResolveError))
+			continue
+		}
+		out[i] = match.title
+	}
+	return out, errs
+}
+
+// checkTags validates every tag list against the catalog, fixing their case
+// in place. Missing tags are created first when the client allows it;
+// otherwise they are reported, since Things would drop them silently. A
+// dry-run client lets them through without creating them.
+func (c *Client) checkTags(ctx context.Context, lists ...*[]string) error {
+	if c.resolver == nil || !slices.ContainsFunc(lists, func(l *[]string) bool { return len(*l) > 0 }) {
+		return nil
+	}
+
+	catalog, err := c.resolver.source.Catalog(ctx)
+	if err != nil {
+		return fmt.Errorf("load catalog: %w", err)
+	}
+	if catalog.Tags == nil {
+		return nil
+	}
+
+	var errs []error
+	created := map[string]bool{}
+	for _, list := range lists {
+		if len(*list) == 0 {
+			continue
+		}
+		canonical, missing := matchTags(catalog, *list)
+		for _, resolveErr := range missing {
+			if len(resolveErr.Matches) > 0 || !c.createTags {
+				errs = append(errs, resolveErr)
+				continue
+			}
+			if created[resolveErr.Name] || c.dryRun {
+				continue
+			}
+			if _, err := c.AddTag(ctx, AddTagInput{Title: resolveErr.Name}); err != nil {
+				return err
+			}
+			created[resolveErr.Name] = true
+		}
+		*list = canonical
+	}
+	return errors.Join(errs...)
+}
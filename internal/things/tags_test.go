@@ -0,0 +1,121 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeScriptRunner struct {
+	scripts []string
+	output  string
+	err     error
+}
+
+func (f *fakeScriptRunner) Run(_ context.Context, script string) (string, error) {
+	f.scripts = append(f.scripts, script)
+	return f.output, f.err
+}
+
+func taggedCatalog() *Catalog {
+	catalog := testCatalog()
+	catalog.Tags = []CatalogTag{{ID: "tag-urgent", Title: "Urgent"}, {ID: "tag-home", Title: "Home"}}
+	return catalog
+}
+
+func TestAddCanonicalizesTagCase(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Catalog: taggedCatalog()})
+
+	if _, err := client.Add(context.Background(), AddInput{Title: "Call", Tags: []string{"urgent"}}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	want := "things:///add?tags=Urgent&title=Call"
+	if got := launcher.calls[0]; got != want {
+		t.Fatalf("Add dispatched %q, want %q", got, want)
+	}
+}
+
+func TestAddRejectsUnknownTags(t *testing.T) {
+	launcher := &fakeLauncher{}
+	scripts := &fakeScriptRunner{}
+	client := NewClient(Config{Launcher: launcher, Scripts: scripts, Catalog: taggedCatalog()})
+
+	_, err := client.Add(context.Background(), AddInput{Title: "Call", Tags: []string{"Urgnt"}})
+
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || resolveErr.Kind != "tag" {
+		t.Fatalf("expected tag ResolveError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `did you mean "Urgent"`) {
+		t.Fatalf("expected suggestion in %q", err.Error())
+	}
+	if len(launcher.calls) != 0 || len(scripts.scripts) != 0 {
+		t.Fatalf("expected no side effects, saw launches %v scripts %v", launcher.calls, scripts.scripts)
+	}
+}
+
+func TestUpdateCreatesMissingTagsWhenEnabled(t *testing.T) {
+	launcher := &fakeLauncher{}
+	scripts := &fakeScriptRunner{}
+	client := NewClient(Config{
+		Launcher:          launcher,
+		Scripts:           scripts,
+		Catalog:           taggedCatalog(),
+		CreateMissingTags: true,
+	})
+
+	_, err := client.Update(context.Background(), UpdateInput{
+		AuthToken: "token",
+		ID:        "todo-id",
+		Tags:      []string{"Waiting \"On\""},
+		AddTags:   []string{"Waiting \"On\"", "home"},
+	})
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	if len(scripts.scripts) != 1 {
+		t.Fatalf("expected 1 script, saw %d", len(scripts.scripts))
+	}
+	if !strings.Contains(scripts.scripts[0], `{name:"Waiting \"On\""}`) {
+		t.Fatalf("expected escaped tag name in script %q", scripts.scripts[0])
+	}
+	if !strings.Contains(launcher.calls[0], "add-tags=Waiting%20%22On%22%2CHome") {
+		t.Fatalf("expected canonical add-tags in %q", launcher.calls[0])
+	}
+}
+
+func TestDryRunDoesNotCreateMissingTags(t *testing.T) {
+	launcher := &fakeLauncher{}
+	scripts := &fakeScriptRunner{}
+	client := NewClient(Config{
+		Launcher:          launcher,
+		Scripts:           scripts,
+		Catalog:           taggedCatalog(),
+		CreateMissingTags: true,
+		DryRun:            true,
+	})
+
+	res, err := client.Add(context.Background(), AddInput{Title: "Call", Tags: []string{"Waiting", "urgent"}})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if len(scripts.scripts) != 0 || len(launcher.calls) != 0 {
+		t.Fatalf("dry run ran scripts %v and launched %v", scripts.scripts, launcher.calls)
+	}
+	if !strings.Contains(res.URL, "tags=Waiting%2CUrgent") {
+		t.Fatalf("unexpected URL %q", res.URL)
+	}
+}
+
+func TestTagsSkippedWhenCatalogHasNoTags(t *testing.T) {
+	launcher := &fakeLauncher{}
+	client := NewClient(Config{Launcher: launcher, Catalog: testCatalog()})
+
+	if _, err := client.Add(context.Background(), AddInput{Title: "Call", Tags: []string{"anything"}}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+}
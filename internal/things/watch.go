@@ -0,0 +1,94 @@
+package things
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watcher reports changes to the projects and to-dos in the database. It
+// polls the modification time and size of the database file and its
+// write-ahead log, and once they have settled for Debounce it reads the items
+// again and passes the differences to OnChange.
+type Watcher struct {
+	db *DB
+	// Interval is how often the files are checked; it defaults to a second.
+	Interval time.Duration
+	// Debounce is how long the files must stay unchanged before the items
+	// are read, so a sync writing in bursts is reported once. It defaults to
+	// two seconds.
+	Debounce time.Duration
+	// OnChange receives each non-empty set of changes.
+	OnChange func([]Change)
+	// Logf reports read failures when set.
+	Logf func(format string, args ...any)
+}
+
+// NewWatcher returns a watcher for db.
+func NewWatcher(db *DB) *Watcher {
+	return &Watcher{db: db, Interval: time.Second, Debounce: 2 * time.Second}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Run reads the current items and then watches for changes until ctx is
+// done.
+func (w *Watcher) Run(ctx context.Context) error {
+	states, err := w.db.ItemStates(ctx)
+	if err != nil {
+		return err
+	}
+	stamps := w.stamps()
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	var settleAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if current := w.stamps(); current != stamps {
+				stamps = current
+				settleAt = now.Add(w.Debounce)
+				continue
+			}
+			if settleAt.IsZero() || now.Before(settleAt) {
+				continue
+			}
+			settleAt = time.Time{}
+
+			changes, next, err := w.db.Changes(ctx, states)
+			if err != nil {
+				// Things may be mid-write; try again on the next tick.
+				w.logf("watch: %v", err)
+				settleAt = now
+				continue
+			}
+			states = next
+			if len(changes) > 0 && w.OnChange != nil {
+				w.OnChange(changes)
+			}
+		}
+	}
+}
+
+// stamps returns the state of the database file and its write-ahead log.
+func (w *Watcher) stamps() [2]fileStamp {
+	var out [2]fileStamp
+	for i, path := range []string{w.db.path, w.db.path + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			out[i] = fileStamp{info.ModTime(), info.Size()}
+		}
+	}
+	return out
+}
+
+func (w *Watcher) logf(format string, args ...any) {
+	if w.Logf != nil {
+		w.Logf(format, args...)
+	}
+}
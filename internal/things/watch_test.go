@@ -0,0 +1,36 @@
+package things
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatcherReportsChangesAfterWrites(t *testing.T) {
+	db := newFixtureDB(t, `INSERT INTO TMTask (uuid, type, title) VALUES ('todo-a', 0, 'Call mom')`)
+	w := NewWatcher(db)
+	w.Interval = 10 * time.Millisecond
+	w.Debounce = 30 * time.Millisecond
+	got := make(chan []Change, 1)
+	w.OnChange = func(changes []Change) { got <- changes }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	// Let the watcher take its first read before writing.
+	time.Sleep(50 * time.Millisecond)
+	execFixture(t, db, `UPDATE TMTask SET status = 3 WHERE uuid = 'todo-a'`)
+
+	select {
+	case changes := <-got:
+		if len(changes) != 1 || changes[0].Kind != ChangeCompleted || changes[0].ID != "todo-a" {
+			t.Fatalf("changes = %+v", changes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no changes reported")
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,276 @@
+// Package webhook posts JSON events to configured HTTP endpoints, signing
+// each body with HMAC-SHA256, retrying failures with exponential backoff, and
+// appending events that could not be delivered to a dead-letter file.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	EventDispatch = "dispatch"
+	EventChange   = "change"
+)
+
+// EventTypes lists every event type an endpoint can subscribe to.
+var EventTypes = []string{EventDispatch, EventChange}
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Things-Event"
+	HeaderDelivery  = "X-Things-Delivery"
+	HeaderSignature = "X-Things-Signature"
+)
+
+// Event is the JSON body posted to endpoints. Text is a one-line summary,
+// which lets chat webhooks such as Slack's show the event as is.
+type Event struct {
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+	Data any       `json:"data"`
+}
+
+// Endpoint is a URL receiving events.
+type Endpoint struct {
+	URL string
+	// Secret, when set, signs each body; the signature is sent as
+	// "sha256=<hex>" in the X-Things-Signature header.
+	Secret string
+	// Events limits the event types sent; empty means all of them.
+	Events []string
+}
+
+// queueSize bounds the events waiting for each endpoint.
+const queueSize = 256
+
+// Sender delivers events to its endpoints in the background. Each endpoint
+// has its own queue, so a slow or failing endpoint does not hold up the
+// others, and events reach an endpoint in the order they were published.
+type Sender struct {
+	queues     []*queue
+	client     *http.Client
+	deadLetter string
+	mu         sync.Mutex
+	now        func() time.Time
+	wait       func(context.Context, time.Duration) error
+
+	// Retries is how many times a failed delivery is retried; it defaults
+	// to 5.
+	Retries int
+	// Backoff is the wait before the first retry, doubling for each one
+	// after up to a minute; it defaults to a second.
+	Backoff time.Duration
+	// Logf reports failed deliveries when set.
+	Logf func(format string, args ...any)
+}
+
+type queue struct {
+	endpoint Endpoint
+	events   chan Event
+}
+
+// deadLetter is a line of the dead-letter file.
+type deadLetter struct {
+	Endpoint string    `json:"endpoint"`
+	Event    Event     `json:"event"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// NewSender returns a sender for endpoints that appends undeliverable events
+// to the JSON Lines file at deadLetterPath.
+func NewSender(endpoints []Endpoint, deadLetterPath string) *Sender {
+	s := &Sender{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		deadLetter: deadLetterPath,
+		now:        time.Now,
+		wait:       sleep,
+		Retries:    5,
+		Backoff:    time.Second,
+	}
+	for _, e := range endpoints {
+		s.queues = append(s.queues, &queue{endpoint: e, events: make(chan Event, queueSize)})
+	}
+	return s
+}
+
+// Publish queues an event for every endpoint subscribed to its type. It does
+// not block; an event that finds an endpoint's queue full goes straight to
+// the dead-letter file.
+func (s *Sender) Publish(eventType, text string, data any) {
+	var id [8]byte
+	rand.Read(id[:])
+	event := Event{ID: hex.EncodeToString(id[:]), Type: eventType, Time: s.now().UTC(), Text: text, Data: data}
+	for _, q := range s.queues {
+		if len(q.endpoint.Events) > 0 && !slices.Contains(q.endpoint.Events, eventType) {
+			continue
+		}
+		select {
+		case q.events <- event:
+		default:
+			s.fail(q.endpoint, event, errors.New("delivery queue is full"), 0)
+		}
+	}
+}
+
+// Run delivers queued events until ctx is done. Events still queued then are
+// written to the dead-letter file so they are not lost.
+func (s *Sender) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, q := range s.queues {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case event := <-q.events:
+					s.Deliver(ctx, q.endpoint, event)
+				case <-ctx.Done():
+					for {
+						select {
+						case event := <-q.events:
+							s.fail(q.endpoint, event, errors.New("server stopped before delivery"), 0)
+						default:
+							return
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Deliver posts event to endpoint, retrying network errors, 429s, and 5xx
+// responses. An event that still fails, or is rejected with another 4xx
+// status, is written to the dead-letter file and the error returned.
+func (s *Sender) Deliver(ctx context.Context, endpoint Endpoint, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return s.fail(endpoint, event, err, 0)
+	}
+	backoff := s.Backoff
+	attempts := 0
+	for {
+		attempts++
+		retry, err := s.post(ctx, endpoint, event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempts > s.Retries {
+			return s.fail(endpoint, event, err, attempts)
+		}
+		if werr := s.wait(ctx, backoff); werr != nil {
+			return s.fail(endpoint, event, fmt.Errorf("%w; gave up: %w", err, werr), attempts)
+		}
+		backoff = min(2*backoff, time.Minute)
+	}
+}
+
+// post sends one attempt and reports whether a failure is worth retrying.
+func (s *Sender) post(ctx context.Context, endpoint Endpoint, event Event, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "things-mcp")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	if endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(endpoint.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("%s responded %s", endpoint.URL, resp.Status)
+	}
+	return false, fmt.Errorf("%s responded %s", endpoint.URL, resp.Status)
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// fail appends event to the dead-letter file and returns err.
+func (s *Sender) fail(endpoint Endpoint, event Event, err error, attempts int) error {
+	s.logf("webhook %s: event %s not delivered: %v", endpoint.URL, event.ID, err)
+	line, merr := json.Marshal(deadLetter{Endpoint: endpoint.URL, Event: event, Error: err.Error(), Attempts: attempts, FailedAt: s.now().UTC()})
+	if merr != nil {
+		return errors.Join(err, merr)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if werr := appendLine(s.deadLetter, line); werr != nil {
+		s.logf("webhook dead-letter file: %v", werr)
+		return errors.Join(err, werr)
+	}
+	return err
+}
+
+func appendLine(path string, line []byte) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *Sender) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
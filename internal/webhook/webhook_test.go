@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type received struct {
+	event     Event
+	signature string
+	header    http.Header
+}
+
+// recorder is a test endpoint answering with the queued statuses, then 200.
+type recorder struct {
+	mu       sync.Mutex
+	statuses []int
+	got      []received
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var event Event
+	json.Unmarshal(body, &event)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, received{event: event, signature: req.Header.Get(HeaderSignature), header: req.Header})
+	if req.Header.Get(HeaderSignature) != "" && req.Header.Get(HeaderSignature) != Sign("s3cret", body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if len(r.statuses) > 0 {
+		w.WriteHeader(r.statuses[0])
+		r.statuses = r.statuses[1:]
+	}
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.got)
+}
+
+func newTestSender(t *testing.T, endpoints ...Endpoint) (*Sender, string, *[]time.Duration) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	s := NewSender(endpoints, path)
+	waits := &[]time.Duration{}
+	s.wait = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return s, path, waits
+}
+
+func readDeadLetters(t *testing.T, path string) []deadLetter {
+	t.Helper()
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var out []deadLetter
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var d deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			t.Fatalf("dead letter %s: %v", scanner.Text(), err)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func TestDeliverSignsAndRetries(t *testing.T) {
+	rec := &recorder{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	endpoint := Endpoint{URL: srv.URL, Secret: "s3cret"}
+	s, dead, waits := newTestSender(t, endpoint)
+
+	event := Event{ID: "e1", Type: EventDispatch, Text: "Added Buy milk", Data: map[string]string{"command": "add"}}
+	if err := s.Deliver(context.Background(), endpoint, event); err != nil {
+		t.Fatalf("Deliver returned error: %v", err)
+	}
+	if len(rec.got) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(rec.got))
+	}
+	last := rec.got[2]
+	if last.event.ID != "e1" || last.event.Text != "Added Buy milk" || last.header.Get(HeaderEvent) != EventDispatch || last.header.Get(HeaderDelivery) != "e1" {
+		t.Fatalf("delivery = %+v", last)
+	}
+	if len(*waits) != 2 || (*waits)[0] != time.Second || (*waits)[1] != 2*time.Second {
+		t.Fatalf("backoff = %v", *waits)
+	}
+	if letters := readDeadLetters(t, dead); len(letters) != 0 {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+}
+
+func TestDeliverDeadLettersFailures(t *testing.T) {
+	rec := &recorder{statuses: []int{500, 500, 500, 500, 500, 500, http.StatusBadRequest}}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	endpoint := Endpoint{URL: srv.URL}
+	s, dead, waits := newTestSender(t, endpoint)
+	s.Retries = 2
+
+	if err := s.Deliver(context.Background(), endpoint, Event{ID: "down", Type: EventChange}); err == nil {
+		t.Fatal("expected error after retries")
+	}
+	if len(rec.got) != 3 || len(*waits) != 2 {
+		t.Fatalf("attempts = %d, waits = %v", len(rec.got), *waits)
+	}
+
+	// Client errors other than 429 are not retried.
+	rec.statuses = []int{http.StatusBadRequest}
+	if err := s.Deliver(context.Background(), endpoint, Event{ID: "bad", Type: EventChange}); err == nil {
+		t.Fatal("expected error for 400")
+	}
+	if len(rec.got) != 4 {
+		t.Fatalf("400 was retried: %d attempts", len(rec.got))
+	}
+
+	letters := readDeadLetters(t, dead)
+	if len(letters) != 2 || letters[0].Event.ID != "down" || letters[0].Attempts != 3 || letters[1].Event.ID != "bad" || letters[1].Endpoint != srv.URL {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+}
+
+func TestSenderRunFiltersEvents(t *testing.T) {
+	all, changes := &recorder{}, &recorder{}
+	allSrv, changeSrv := httptest.NewServer(all), httptest.NewServer(changes)
+	defer allSrv.Close()
+	defer changeSrv.Close()
+	s, _, _ := newTestSender(t,
+		Endpoint{URL: allSrv.URL, Secret: "s3cret"},
+		Endpoint{URL: changeSrv.URL, Events: []string{EventChange}},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	s.Publish(EventDispatch, "Dispatched add", nil)
+	s.Publish(EventChange, "Completed Buy milk", nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for (all.count() < 2 || changes.count() < 1) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if all.count() != 2 || all.got[0].event.Type != EventDispatch || all.got[1].event.Type != EventChange || all.got[0].signature == "" {
+		t.Fatalf("all endpoint got %+v", all.got)
+	}
+	if changes.count() != 1 || changes.got[0].event.Type != EventChange || changes.got[0].signature != "" {
+		t.Fatalf("change endpoint got %+v", changes.got)
+	}
+}
+
+func TestRunDeadLettersQueuedEventsOnShutdown(t *testing.T) {
+	s, dead, _ := newTestSender(t, Endpoint{URL: "http://127.0.0.1:1"})
+	s.Publish(EventChange, "Completed Buy milk", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+
+	// The event was either attempted and failed, or drained unattempted.
+	if letters := readDeadLetters(t, dead); len(letters) != 1 || letters[0].Event.Text != "Completed Buy milk" {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+}